@@ -11,6 +11,7 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/paths"
 	"github.com/fairyhunter13/auto-bmad/apps/core/internal/server"
 )
 
@@ -24,6 +25,7 @@ var (
 func main() {
 	// Parse command-line flags
 	projectPath := flag.String("project-path", "", "Path to BMAD project root (required)")
+	configDir := flag.String("config-dir", "", "Directory for user settings and data (default: $AUTOBMAD_HOME or XDG directories)")
 	flag.Parse()
 
 	// Validate required project path
@@ -33,6 +35,12 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Put user settings, presets and recent projects under --config-dir
+	if err := paths.SetOverride(*configDir); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Create logger that writes to stderr (stdout is reserved for JSON-RPC)
 	logger := log.New(os.Stderr, "[RPC] ", log.LstdFlags)
 
@@ -42,6 +50,11 @@ func main() {
 	// Print version info to stderr
 	logger.Printf("AutoBMAD Core v%s (commit: %s, built: %s)", version, commit, date)
 	logger.Printf("Project path: %s", *projectPath)
+	if dir := paths.Get().Config; dir != "" {
+		logger.Printf("Config dir: %s", dir)
+	} else {
+		logger.Println("No config directory found; user settings and recent projects are not saved")
+	}
 	logger.Println("Starting JSON-RPC server on stdio...")
 
 	// Create server with project path
@@ -53,6 +66,12 @@ func main() {
 	// Register project handlers
 	server.RegisterProjectHandlers(srv)
 
+	// Register artifact handlers
+	server.RegisterArtifactHandlers(srv, *projectPath)
+
+	// Register sprint status handlers
+	server.RegisterSprintHandlers(srv, *projectPath)
+
 	// Register OpenCode handlers
 	server.RegisterOpenCodeHandlers(srv)
 
@@ -62,6 +81,15 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Initialize OpenCode executor (heartbeat/stall settings apply per step)
+	server.InitOpenCodeExecutor(srv)
+
+	// Register journey handlers (uses the executor and settings)
+	if err := server.RegisterJourneyHandlers(srv, *projectPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to register journey handlers: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Create context that cancels on SIGTERM/SIGINT
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -72,6 +100,12 @@ func main() {
 	server.InitNetworkMonitor(ctx, srv)
 	defer cancel()
 
+	// Watch the project for artifact and configuration changes
+	if err := server.StartProjectWatcher(srv, *projectPath); err != nil {
+		logger.Printf("Failed to start project watcher: %v", err)
+	}
+	defer srv.Workspace().Shutdown()
+
 	// Handle graceful shutdown
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT)
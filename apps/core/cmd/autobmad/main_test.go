@@ -25,7 +25,7 @@ func TestBinaryPingIntegration(t *testing.T) {
 
 	// Start the binary with test project path
 	testProjectPath := t.TempDir()
-	proc := exec.Command("./autobmad_test", "--project-path", testProjectPath)
+	proc := exec.Command("./autobmad_test", "--project-path", testProjectPath, "--config-dir", t.TempDir())
 	stdin, err := proc.StdinPipe()
 	if err != nil {
 		t.Fatalf("failed to get stdin pipe: %v", err)
@@ -104,7 +104,7 @@ func TestBinaryGracefulShutdown(t *testing.T) {
 
 	// Start the binary with test project path
 	testProjectPath := t.TempDir()
-	proc := exec.Command("./autobmad_test", "--project-path", testProjectPath)
+	proc := exec.Command("./autobmad_test", "--project-path", testProjectPath, "--config-dir", t.TempDir())
 	stdin, _ := proc.StdinPipe()
 
 	if err := proc.Start(); err != nil {
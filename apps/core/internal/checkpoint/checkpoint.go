@@ -3,7 +3,90 @@
 // rollback and crash recovery.
 package checkpoint
 
-// Checkpointer manages Git checkpoint operations.
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Default identity of checkpoint commits in repositories without one.
+const (
+	defaultAuthorName  = "Auto-BMAD"
+	defaultAuthorEmail = "auto-bmad@localhost"
+)
+
+// Checkpointer manages Git checkpoint operations. A checkpoint commits the
+// BMAD output of a project (_bmad-output/, without Auto-BMAD's own state in
+// .autobmad/) and nothing else, so other staged or unstaged work of the
+// user is left alone. It is safe for concurrent use.
 type Checkpointer struct {
-	// TODO: Story 3.6 will implement Git checkpoint commits
+	repoPath string
+	paths    []string
+
+	mu sync.Mutex // Serializes git commands on the repository
+}
+
+// NewCheckpointer creates a checkpointer for the project at repoPath.
+func NewCheckpointer(repoPath string) *Checkpointer {
+	return &Checkpointer{
+		repoPath: repoPath,
+		paths:    []string{"_bmad-output", ":(exclude)_bmad-output/.autobmad"},
+	}
+}
+
+// Commit stages the checkpointed paths and commits them with message. It
+// returns the commit SHA, or "" when the project is not a Git repository,
+// its output is ignored by Git, or nothing changed since the last checkpoint.
+func (c *Checkpointer) Commit(message string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !GetRepoStatus(c.repoPath).IsGitRepo {
+		return "", nil
+	}
+	if _, err := c.git(nil, "check-ignore", "--quiet", c.paths[0]); err == nil {
+		return "", nil
+	}
+	if _, err := c.git(nil, append([]string{"add", "--all", "--"}, c.paths...)...); err != nil {
+		return "", err
+	}
+	// diff --quiet exits with 1 when there are staged changes
+	if _, err := c.git(nil, append([]string{"diff", "--cached", "--quiet", "--"}, c.paths...)...); err == nil {
+		return "", nil
+	}
+
+	var env []string
+	if name, _ := c.git(nil, "config", "user.name"); name == "" {
+		env = append(env, "GIT_AUTHOR_NAME="+defaultAuthorName, "GIT_COMMITTER_NAME="+defaultAuthorName)
+	}
+	if email, _ := c.git(nil, "config", "user.email"); email == "" {
+		env = append(env, "GIT_AUTHOR_EMAIL="+defaultAuthorEmail, "GIT_COMMITTER_EMAIL="+defaultAuthorEmail)
+	}
+	args := append([]string{"commit", "--quiet", "--no-verify", "--message", message, "--"}, c.paths...)
+	if _, err := c.git(env, args...); err != nil {
+		return "", err
+	}
+	return c.git(nil, "rev-parse", "HEAD")
+}
+
+// git runs a git command in the repository with extra environment
+// variables and returns its trimmed output.
+func (c *Checkpointer) git(env []string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", c.repoPath}, args...)...)
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s failed: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s failed: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
 }
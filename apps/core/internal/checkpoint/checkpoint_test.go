@@ -0,0 +1,63 @@
+package checkpoint
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckpointerCommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	git := func(args ...string) string {
+		out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	write := func(rel, content string) {
+		path := filepath.Join(repo, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := NewCheckpointer(repo)
+	write("_bmad-output/planning-artifacts/prd.md", "# PRD\n")
+	if sha, err := c.Commit("checkpoint"); err != nil || sha != "" {
+		t.Fatalf("Commit outside a repository = %q, %v; want no checkpoint", sha, err)
+	}
+
+	git("init", "-q")
+	write("_bmad-output/.autobmad/config.json", "{}\n")
+	write("notes.txt", "work in progress\n")
+	git("add", "notes.txt")
+
+	sha, err := c.Commit("auto-bmad: prd")
+	if err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if sha == "" || git("rev-parse", "HEAD") != sha {
+		t.Fatalf("Commit = %q, HEAD = %s", sha, git("rev-parse", "HEAD"))
+	}
+	files := git("show", "--name-only", "--format=", sha)
+	if files != "_bmad-output/planning-artifacts/prd.md" {
+		t.Errorf("committed files = %q, want only the artifact", files)
+	}
+	if status := git("status", "--porcelain", "notes.txt"); !strings.HasPrefix(status, "A ") {
+		t.Errorf("notes.txt status = %q, want still staged", status)
+	}
+
+	// Nothing changed since the last checkpoint
+	if again, err := c.Commit("auto-bmad: prd"); err != nil || again != "" {
+		t.Errorf("second Commit = %q, %v; want no checkpoint", again, err)
+	}
+}
@@ -0,0 +1,50 @@
+package checkpoint
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// revisionPattern accepts commit SHAs and HEAD-relative revisions. Anything
+// else is rejected so user input can never be read as a git option.
+var revisionPattern = regexp.MustCompile(`^(?:[0-9a-fA-F]{4,64}|HEAD(?:[~^]\d{0,3})*)$`)
+
+// ValidateRevision checks that rev is a commit SHA or HEAD-relative revision.
+func ValidateRevision(rev string) error {
+	if !revisionPattern.MatchString(rev) {
+		return fmt.Errorf("invalid revision %q: use a commit SHA or HEAD~n", rev)
+	}
+	return nil
+}
+
+// Diff returns the unified diff of path between two revisions of the
+// repository at repoPath. An empty from means HEAD; an empty to means the
+// working tree. path is relative to repoPath.
+func Diff(repoPath, from, to, path string) (string, error) {
+	if from == "" {
+		from = "HEAD"
+	}
+	args := []string{"-C", repoPath, "diff", "--no-color", "--no-ext-diff"}
+	for _, rev := range []string{from, to} {
+		if rev == "" {
+			continue
+		}
+		if err := ValidateRevision(rev); err != nil {
+			return "", err
+		}
+		args = append(args, rev)
+	}
+	args = append(args, "--", path)
+
+	cmd := exec.Command("git", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git diff failed: %s", strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
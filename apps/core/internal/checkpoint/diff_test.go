@@ -0,0 +1,71 @@
+package checkpoint
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateRevision(t *testing.T) {
+	for _, rev := range []string{"HEAD", "HEAD~2", "HEAD^", "abc1234", "0123456789abcdef0123456789abcdef01234567"} {
+		if err := ValidateRevision(rev); err != nil {
+			t.Errorf("ValidateRevision(%q) = %v", rev, err)
+		}
+	}
+	for _, rev := range []string{"", "--output=/tmp/x", "main", "HEAD;rm", "abc"} {
+		if err := ValidateRevision(rev); err == nil {
+			t.Errorf("ValidateRevision(%q) accepted", rev)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	write := func(content string) {
+		if err := os.WriteFile(filepath.Join(repo, "prd.md"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "-q")
+	write("# PRD\n")
+	git("add", "prd.md")
+	git("commit", "-qm", "v1")
+	first := git("rev-parse", "HEAD")
+	write("# PRD\n## Goals\n")
+	git("commit", "-qam", "v2")
+	write("# PRD\n## Goals\n## Scope\n")
+
+	working, err := Diff(repo, "", "", "prd.md")
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if !strings.Contains(working, "+## Scope") || strings.Contains(working, "+## Goals") {
+		t.Errorf("working tree diff:\n%s", working)
+	}
+
+	between, err := Diff(repo, first, "HEAD", "prd.md")
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if !strings.Contains(between, "+## Goals") || strings.Contains(between, "+## Scope") {
+		t.Errorf("checkpoint diff:\n%s", between)
+	}
+
+	if _, err := Diff(repo, "--cached", "", "prd.md"); err == nil {
+		t.Error("expected option-like revision to be rejected")
+	}
+}
@@ -0,0 +1,47 @@
+// Package filelock provides advisory locks that serialize read-modify-write
+// cycles on a file across processes. The lock is taken on a sibling
+// <path>.lock file, so the guarded file itself can be replaced by an atomic
+// rename while the lock is held.
+package filelock
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Lock is a held lock. Release it with Unlock.
+type Lock struct {
+	f *os.File
+}
+
+// Acquire blocks until it holds the exclusive lock guarding path. The
+// directory of path is created if needed.
+func Acquire(path string) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("creating lock directory: %w", err)
+	}
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("opening lock file: %w", err)
+	}
+	if err := lock(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("locking %s: %w", path, err)
+	}
+	return &Lock{f: f}, nil
+}
+
+// Unlock releases the lock. The lock file is left in place so that other
+// processes waiting on it keep locking the same file.
+func (l *Lock) Unlock() error {
+	if l == nil || l.f == nil {
+		return nil
+	}
+	err := unlock(l.f)
+	if cerr := l.f.Close(); err == nil {
+		err = cerr
+	}
+	l.f = nil
+	return err
+}
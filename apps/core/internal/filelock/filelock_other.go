@@ -0,0 +1,12 @@
+//go:build !unix
+
+package filelock
+
+import "os"
+
+// lock is a no-op where flock is unavailable; writes stay atomic but
+// concurrent read-modify-write cycles are not serialized.
+func lock(f *os.File) error { return nil }
+
+// unlock is a no-op where flock is unavailable.
+func unlock(f *os.File) error { return nil }
@@ -0,0 +1,54 @@
+//go:build unix
+
+package filelock
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAcquire_Exclusive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "config.json")
+
+	first, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire() failed: %v", err)
+	}
+
+	var mu sync.Mutex
+	acquired := false
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		second, err := Acquire(path)
+		if err != nil {
+			t.Errorf("second Acquire() failed: %v", err)
+			return
+		}
+		mu.Lock()
+		acquired = true
+		mu.Unlock()
+		second.Unlock()
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	if acquired {
+		t.Error("second lock acquired while the first was held")
+	}
+	mu.Unlock()
+
+	if err := first.Unlock(); err != nil {
+		t.Fatalf("Unlock() failed: %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("second lock not acquired after Unlock")
+	}
+	if err := first.Unlock(); err != nil {
+		t.Errorf("second Unlock() = %v", err)
+	}
+}
@@ -0,0 +1,398 @@
+package journey
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed completion_rules.yaml
+var defaultCompletionRules []byte
+
+// ProjectRulesFile is the project-local completion rules file, relative to the project root.
+// Rules declared there extend or override the built-in defaults per workflow.
+const ProjectRulesFile = "_bmad-output/.autobmad/completion-rules.yaml"
+
+// DefaultFailureMarkers are output prefixes that always mark a step as failed.
+// Source: architecture.md "Output Validation Rules" - no Error:, FATAL:, panic: in output.
+var DefaultFailureMarkers = []string{"Error:", "FATAL:", "panic:"}
+
+// ArtifactExpectation describes an artifact a workflow is expected to produce.
+type ArtifactExpectation struct {
+	// Pattern is a glob relative to _bmad-output/ (e.g. "planning-artifacts/prd*.md")
+	Pattern string `yaml:"pattern" json:"pattern"`
+	// RequiredSections are markdown headings that must appear in the artifact
+	RequiredSections []string `yaml:"requiredSections,omitempty" json:"requiredSections,omitempty"`
+}
+
+// CompletionRule declares how success is detected for a single workflow.
+type CompletionRule struct {
+	ExpectedArtifacts []ArtifactExpectation `yaml:"expectedArtifacts,omitempty" json:"expectedArtifacts,omitempty"`
+	CompletionMarkers []string              `yaml:"completionMarkers,omitempty" json:"completionMarkers,omitempty"`
+	FailureMarkers    []string              `yaml:"failureMarkers,omitempty" json:"failureMarkers,omitempty"`
+}
+
+// RuleSet maps workflow names to their completion rules.
+type RuleSet struct {
+	Workflows map[string]CompletionRule `yaml:"workflows" json:"workflows"`
+}
+
+// ParseRuleSet parses a completion rules YAML document.
+func ParseRuleSet(data []byte) (*RuleSet, error) {
+	rs := &RuleSet{}
+	if err := yaml.Unmarshal(data, rs); err != nil {
+		return nil, fmt.Errorf("parsing completion rules: %w", err)
+	}
+	if rs.Workflows == nil {
+		rs.Workflows = make(map[string]CompletionRule)
+	}
+	return rs, nil
+}
+
+// LoadRuleSet returns the built-in completion rules merged with the
+// project-local rules file, if present. Project rules replace the default
+// rule of the same workflow entirely.
+func LoadRuleSet(projectPath string) (*RuleSet, error) {
+	rs, err := ParseRuleSet(defaultCompletionRules)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(projectPath, ProjectRulesFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return rs, nil
+		}
+		return nil, fmt.Errorf("reading project completion rules: %w", err)
+	}
+
+	projectRules, err := ParseRuleSet(data)
+	if err != nil {
+		return nil, err
+	}
+	for name, rule := range projectRules.Workflows {
+		rs.Workflows[name] = rule
+	}
+
+	return rs, nil
+}
+
+// Rule returns the completion rule for a workflow and whether one was declared.
+func (rs *RuleSet) Rule(workflow string) (CompletionRule, bool) {
+	rule, ok := rs.Workflows[workflow]
+	return rule, ok
+}
+
+// CompletionInput is everything the detector needs to judge a finished step.
+type CompletionInput struct {
+	Workflow    string
+	ProjectPath string
+	ExitCode    int
+	Output      string    // Combined OpenCode output
+	StartedAt   time.Time // Artifacts older than this are considered stale
+}
+
+// CheckResult is the outcome of a single completion check.
+type CheckResult struct {
+	Name     string `json:"name"`
+	Passed   bool   `json:"passed"`
+	Evidence string `json:"evidence"`
+}
+
+// CompletionVerdict is the detector's decision with evidence for every check.
+type CompletionVerdict struct {
+	Workflow  string        `json:"workflow"`
+	Success   bool          `json:"success"`
+	Checks    []CheckResult `json:"checks"`
+	Artifacts []string      `json:"artifacts,omitempty"` // Fresh artifacts matched by the rule
+	CheckedAt time.Time     `json:"checkedAt"`
+}
+
+// FailedChecks returns the checks that did not pass.
+func (v *CompletionVerdict) FailedChecks() []CheckResult {
+	var failed []CheckResult
+	for _, c := range v.Checks {
+		if !c.Passed {
+			failed = append(failed, c)
+		}
+	}
+	return failed
+}
+
+// CompletionDetector decides whether a workflow step really succeeded.
+// A step only succeeds when every check passes, so an exit code of 0 alone
+// is never enough when the workflow declares expected artifacts.
+type CompletionDetector struct {
+	rules *RuleSet
+}
+
+// NewCompletionDetector creates a detector using the given rule set.
+func NewCompletionDetector(rules *RuleSet) *CompletionDetector {
+	if rules == nil {
+		rules = &RuleSet{Workflows: make(map[string]CompletionRule)}
+	}
+	return &CompletionDetector{rules: rules}
+}
+
+// Detect runs all completion checks for the given input and returns a verdict.
+func (d *CompletionDetector) Detect(in CompletionInput) *CompletionVerdict {
+	verdict := &CompletionVerdict{
+		Workflow:  in.Workflow,
+		CheckedAt: time.Now(),
+	}
+
+	rule, declared := d.rules.Rule(in.Workflow)
+
+	verdict.Checks = append(verdict.Checks, checkExitCode(in.ExitCode))
+
+	failureMarkers := append(append([]string{}, DefaultFailureMarkers...), rule.FailureMarkers...)
+	verdict.Checks = append(verdict.Checks, checkFailureMarkers(in.Output, failureMarkers))
+
+	if len(rule.CompletionMarkers) > 0 {
+		verdict.Checks = append(verdict.Checks, checkCompletionMarkers(in.Output, rule.CompletionMarkers))
+	}
+
+	outputPath := filepath.Join(in.ProjectPath, "_bmad-output")
+	for _, expectation := range rule.ExpectedArtifacts {
+		checks, artifacts := checkArtifact(outputPath, expectation, in.StartedAt)
+		verdict.Checks = append(verdict.Checks, checks...)
+		verdict.Artifacts = append(verdict.Artifacts, artifacts...)
+	}
+
+	if !declared {
+		verdict.Checks = append(verdict.Checks, CheckResult{
+			Name:     "rule",
+			Passed:   true,
+			Evidence: fmt.Sprintf("no completion rule declared for %q; only exit code and output were checked", in.Workflow),
+		})
+	}
+
+	verdict.Success = len(verdict.FailedChecks()) == 0
+	return verdict
+}
+
+// ExistingArtifacts returns the files currently matching a workflow's
+// expected artifact patterns, relative to _bmad-output/.
+func (d *CompletionDetector) ExistingArtifacts(workflow, projectPath string) []string {
+	rule, _ := d.rules.Rule(workflow)
+	outputPath := filepath.Join(projectPath, "_bmad-output")
+
+	var existing []string
+	for _, exp := range rule.ExpectedArtifacts {
+		matches, _ := filepath.Glob(filepath.Join(outputPath, filepath.FromSlash(exp.Pattern)))
+		for _, match := range matches {
+			if info, err := os.Stat(match); err != nil || info.IsDir() {
+				continue
+			}
+			if rel, err := filepath.Rel(outputPath, match); err == nil {
+				existing = append(existing, filepath.ToSlash(rel))
+			}
+		}
+	}
+	sort.Strings(existing)
+	return existing
+}
+
+// checkExitCode passes only when the process exited cleanly.
+func checkExitCode(code int) CheckResult {
+	return CheckResult{
+		Name:     "exit-code",
+		Passed:   code == 0,
+		Evidence: fmt.Sprintf("process exited with code %d", code),
+	}
+}
+
+// checkFailureMarkers fails if any output line starts with a failure marker.
+func checkFailureMarkers(output string, markers []string) CheckResult {
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		for _, marker := range markers {
+			if marker != "" && strings.HasPrefix(trimmed, marker) {
+				return CheckResult{
+					Name:     "failure-markers",
+					Passed:   false,
+					Evidence: fmt.Sprintf("output contains failure marker %q: %s", marker, truncate(trimmed, 200)),
+				}
+			}
+		}
+	}
+	return CheckResult{
+		Name:     "failure-markers",
+		Passed:   true,
+		Evidence: "no failure markers in output",
+	}
+}
+
+// checkCompletionMarkers passes if at least one completion marker appears in the output.
+func checkCompletionMarkers(output string, markers []string) CheckResult {
+	lower := strings.ToLower(output)
+	for _, marker := range markers {
+		if marker != "" && strings.Contains(lower, strings.ToLower(marker)) {
+			return CheckResult{
+				Name:     "completion-marker",
+				Passed:   true,
+				Evidence: fmt.Sprintf("output contains completion marker %q", marker),
+			}
+		}
+	}
+	return CheckResult{
+		Name:     "completion-marker",
+		Passed:   false,
+		Evidence: fmt.Sprintf("none of the completion markers %q found in output", markers),
+	}
+}
+
+// checkArtifact verifies presence, freshness and required sections of an expected artifact.
+// It returns the checks performed and the relative paths of fresh matching artifacts.
+func checkArtifact(outputPath string, exp ArtifactExpectation, startedAt time.Time) ([]CheckResult, []string) {
+	presence := CheckResult{Name: "artifact:" + exp.Pattern}
+
+	matches, err := filepath.Glob(filepath.Join(outputPath, filepath.FromSlash(exp.Pattern)))
+	if err != nil {
+		presence.Evidence = fmt.Sprintf("invalid pattern: %v", err)
+		return []CheckResult{presence}, nil
+	}
+	if len(matches) == 0 {
+		presence.Evidence = "no artifact matches pattern"
+		return []CheckResult{presence}, nil
+	}
+	presence.Passed = true
+	presence.Evidence = fmt.Sprintf("%d matching artifact(s)", len(matches))
+
+	// Keep only artifacts written after the step started
+	freshness := CheckResult{Name: "fresh:" + exp.Pattern}
+	var fresh []string
+	var newest time.Time
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		if info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+		if !info.ModTime().Before(startedAt) {
+			fresh = append(fresh, match)
+		}
+	}
+	sort.Strings(fresh)
+
+	if len(fresh) == 0 {
+		freshness.Evidence = fmt.Sprintf("newest match modified %s, before step start %s",
+			newest.Format(time.RFC3339), startedAt.Format(time.RFC3339))
+		return []CheckResult{presence, freshness}, nil
+	}
+	freshness.Passed = true
+	freshness.Evidence = fmt.Sprintf("%d artifact(s) modified after step start", len(fresh))
+
+	relPaths := make([]string, 0, len(fresh))
+	for _, p := range fresh {
+		if rel, err := filepath.Rel(outputPath, p); err == nil {
+			relPaths = append(relPaths, filepath.ToSlash(rel))
+		}
+	}
+
+	checks := []CheckResult{presence, freshness}
+	if len(exp.RequiredSections) > 0 {
+		checks = append(checks, checkSections(fresh, exp))
+	}
+
+	return checks, relPaths
+}
+
+// checkSections passes if any fresh artifact contains every required heading.
+func checkSections(paths []string, exp ArtifactExpectation) CheckResult {
+	result := CheckResult{Name: "sections:" + exp.Pattern}
+
+	var missing []string
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			continue
+		}
+		missing = missingSections(MarkdownHeadings(string(data)), exp.RequiredSections)
+		if len(missing) == 0 {
+			result.Passed = true
+			result.Evidence = fmt.Sprintf("%s contains all required sections", filepath.Base(p))
+			return result
+		}
+	}
+
+	result.Evidence = fmt.Sprintf("missing required sections: %s", strings.Join(missing, ", "))
+	return result
+}
+
+// missingSections returns required headings not present in headings (case-insensitive).
+func missingSections(headings, required []string) []string {
+	present := make(map[string]bool, len(headings))
+	for _, h := range headings {
+		present[strings.ToLower(h)] = true
+	}
+
+	var missing []string
+	for _, r := range required {
+		if !present[strings.ToLower(strings.TrimSpace(r))] {
+			missing = append(missing, r)
+		}
+	}
+	return missing
+}
+
+// MarkdownHeadings returns the text of every ATX heading in a markdown document,
+// skipping YAML frontmatter and fenced code blocks.
+func MarkdownHeadings(content string) []string {
+	var headings []string
+	inFence := false
+
+	lines := strings.Split(content, "\n")
+	start := 0
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		for i := 1; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "---" {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	for _, line := range lines[start:] {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || !strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		text := strings.TrimLeft(trimmed, "#")
+		if text == "" || (text[0] != ' ' && text[0] != '\t') {
+			continue
+		}
+		headings = append(headings, stripClosingHashes(strings.TrimSpace(text)))
+	}
+
+	return headings
+}
+
+// stripClosingHashes removes an optional closing "###" sequence from a heading.
+func stripClosingHashes(text string) string {
+	idx := strings.LastIndex(text, " #")
+	if idx < 0 || strings.Trim(text[idx+1:], "#") != "" {
+		return text
+	}
+	return strings.TrimSpace(text[:idx])
+}
+
+// truncate shortens s to at most n bytes for use in evidence strings.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
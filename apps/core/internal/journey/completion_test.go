@@ -0,0 +1,227 @@
+package journey
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeArtifact creates a file under <project>/_bmad-output with the given modification time.
+func writeArtifact(t *testing.T, projectPath, rel, content string, modTime time.Time) {
+	t.Helper()
+	path := filepath.Join(projectPath, "_bmad-output", filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+}
+
+func testRules(t *testing.T) *RuleSet {
+	t.Helper()
+	rs, err := ParseRuleSet([]byte(`
+workflows:
+  create-prd:
+    expectedArtifacts:
+      - pattern: planning-artifacts/prd*.md
+        requiredSections: ["Executive Summary", "Success Criteria"]
+    completionMarkers: ["PRD complete"]
+`))
+	if err != nil {
+		t.Fatalf("ParseRuleSet failed: %v", err)
+	}
+	return rs
+}
+
+func findCheck(v *CompletionVerdict, name string) *CheckResult {
+	for i := range v.Checks {
+		if v.Checks[i].Name == name {
+			return &v.Checks[i]
+		}
+	}
+	return nil
+}
+
+func TestDetect_Success(t *testing.T) {
+	project := t.TempDir()
+	started := time.Now().Add(-time.Minute)
+	writeArtifact(t, project, "planning-artifacts/prd.md",
+		"---\ntitle: x\n---\n# PRD\n## Executive Summary\ntext\n## Success Criteria\n", time.Now())
+
+	d := NewCompletionDetector(testRules(t))
+	v := d.Detect(CompletionInput{
+		Workflow:    "create-prd",
+		ProjectPath: project,
+		ExitCode:    0,
+		Output:      "working...\nPRD complete\n",
+		StartedAt:   started,
+	})
+
+	if !v.Success {
+		t.Fatalf("expected success, failed checks: %+v", v.FailedChecks())
+	}
+	if len(v.Artifacts) != 1 || v.Artifacts[0] != "planning-artifacts/prd.md" {
+		t.Errorf("Artifacts = %v, want [planning-artifacts/prd.md]", v.Artifacts)
+	}
+}
+
+func TestDetect_ExitZeroButMissingArtifact(t *testing.T) {
+	project := t.TempDir()
+
+	d := NewCompletionDetector(testRules(t))
+	v := d.Detect(CompletionInput{
+		Workflow:    "create-prd",
+		ProjectPath: project,
+		Output:      "PRD complete",
+		StartedAt:   time.Now(),
+	})
+
+	if v.Success {
+		t.Fatal("expected failure when artifact is missing")
+	}
+	c := findCheck(v, "artifact:planning-artifacts/prd*.md")
+	if c == nil || c.Passed {
+		t.Errorf("artifact check = %+v, want failed", c)
+	}
+}
+
+func TestDetect_StaleArtifact(t *testing.T) {
+	project := t.TempDir()
+	writeArtifact(t, project, "planning-artifacts/prd.md",
+		"## Executive Summary\n## Success Criteria\n", time.Now().Add(-time.Hour))
+
+	d := NewCompletionDetector(testRules(t))
+	v := d.Detect(CompletionInput{
+		Workflow:    "create-prd",
+		ProjectPath: project,
+		Output:      "PRD complete",
+		StartedAt:   time.Now().Add(-time.Minute),
+	})
+
+	if v.Success {
+		t.Fatal("expected failure for stale artifact")
+	}
+	c := findCheck(v, "fresh:planning-artifacts/prd*.md")
+	if c == nil || c.Passed {
+		t.Errorf("fresh check = %+v, want failed", c)
+	}
+}
+
+func TestDetect_MissingSections(t *testing.T) {
+	project := t.TempDir()
+	writeArtifact(t, project, "planning-artifacts/prd.md", "## Executive Summary\n", time.Now())
+
+	d := NewCompletionDetector(testRules(t))
+	v := d.Detect(CompletionInput{
+		Workflow:    "create-prd",
+		ProjectPath: project,
+		Output:      "PRD complete",
+		StartedAt:   time.Now().Add(-time.Minute),
+	})
+
+	c := findCheck(v, "sections:planning-artifacts/prd*.md")
+	if c == nil || c.Passed {
+		t.Fatalf("sections check = %+v, want failed", c)
+	}
+	if v.Success {
+		t.Error("expected failure for missing sections")
+	}
+}
+
+func TestDetect_FailureMarkersAndExitCode(t *testing.T) {
+	d := NewCompletionDetector(nil)
+
+	tests := []struct {
+		name     string
+		exitCode int
+		output   string
+		want     bool
+	}{
+		{"clean", 0, "all good", true},
+		{"non-zero exit", 1, "all good", false},
+		{"error marker", 0, "step 1\nError: model refused\n", false},
+		{"panic marker", 0, "panic: runtime error", false},
+		{"marker mid-line is ignored", 0, "no Error: here at line start", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := d.Detect(CompletionInput{
+				Workflow:  "unknown-workflow",
+				ExitCode:  tt.exitCode,
+				Output:    tt.output,
+				StartedAt: time.Now(),
+			})
+			if v.Success != tt.want {
+				t.Errorf("Success = %v, want %v (checks: %+v)", v.Success, tt.want, v.Checks)
+			}
+		})
+	}
+}
+
+func TestDetect_MissingCompletionMarker(t *testing.T) {
+	project := t.TempDir()
+	writeArtifact(t, project, "planning-artifacts/prd.md",
+		"## Executive Summary\n## Success Criteria\n", time.Now())
+
+	d := NewCompletionDetector(testRules(t))
+	v := d.Detect(CompletionInput{
+		Workflow:    "create-prd",
+		ProjectPath: project,
+		Output:      "stopped early",
+		StartedAt:   time.Now().Add(-time.Minute),
+	})
+
+	c := findCheck(v, "completion-marker")
+	if c == nil || c.Passed {
+		t.Fatalf("completion-marker check = %+v, want failed", c)
+	}
+}
+
+func TestLoadRuleSet_ProjectOverride(t *testing.T) {
+	project := t.TempDir()
+	rulesPath := filepath.Join(project, filepath.FromSlash(ProjectRulesFile))
+	if err := os.MkdirAll(filepath.Dir(rulesPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	override := "workflows:\n  prd:\n    completionMarkers: [\"done\"]\n  custom:\n    expectedArtifacts:\n      - pattern: custom/*.md\n"
+	if err := os.WriteFile(rulesPath, []byte(override), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rs, err := LoadRuleSet(project)
+	if err != nil {
+		t.Fatalf("LoadRuleSet failed: %v", err)
+	}
+
+	prd, ok := rs.Rule("prd")
+	if !ok || len(prd.ExpectedArtifacts) != 0 || len(prd.CompletionMarkers) != 1 {
+		t.Errorf("prd rule not overridden: %+v", prd)
+	}
+	if _, ok := rs.Rule("custom"); !ok {
+		t.Error("custom rule not loaded")
+	}
+	if _, ok := rs.Rule("create-architecture"); !ok {
+		t.Error("default rule create-architecture missing")
+	}
+}
+
+func TestMarkdownHeadings(t *testing.T) {
+	content := "---\ntitle: '# not a heading'\n---\n# Title\n\n## Section One ##\n```\n# code comment\n```\n### C#\n#nospace\n"
+	got := MarkdownHeadings(content)
+	want := []string{"Title", "Section One", "C#"}
+
+	if len(got) != len(want) {
+		t.Fatalf("MarkdownHeadings = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("heading[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
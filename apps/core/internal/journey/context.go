@@ -0,0 +1,220 @@
+package journey
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/project"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/state"
+)
+
+// ContextFileName is the instruction file written for every step.
+const ContextFileName = "context.md"
+
+// ContextInput identifies the step whose context is being assembled.
+type ContextInput struct {
+	JourneyID      string
+	StepIndex      int
+	Workflow       string
+	ProjectContext string   // Description stored via project.setContext
+	PriorArtifacts []string // Artifacts produced by earlier steps, relative to _bmad-output/
+
+	PreviousAttempts []AttemptSummary // Failed attempts of this step
+	Feedback         []string         // User feedback for this attempt
+}
+
+// AttemptSummary is the failure evidence of an earlier attempt carried into the next one.
+type AttemptSummary struct {
+	Number       int            `json:"number"`
+	Outcome      AttemptOutcome `json:"outcome"`
+	Error        string         `json:"error,omitempty"`
+	FailedChecks []CheckResult  `json:"failedChecks,omitempty"`
+	Feedback     []string       `json:"feedback,omitempty"`
+}
+
+// StepContext is the BMAD workflow/agent context passed to OpenCode (FR19).
+type StepContext struct {
+	JourneyID      string                `json:"journeyId"`
+	StepIndex      int                   `json:"stepIndex"`
+	Workflow       project.WorkflowEntry `json:"workflow"`
+	Agent          *project.AgentEntry   `json:"agent,omitempty"`
+	Config         []project.ConfigValue `json:"config"`
+	ProjectContext string                `json:"projectContext,omitempty"`
+	PriorArtifacts []string              `json:"priorArtifacts"`
+
+	PreviousAttempts []AttemptSummary `json:"previousAttempts,omitempty"`
+	Feedback         []string         `json:"feedback,omitempty"`
+}
+
+// BuildStepContext gathers the manifest entry, agent persona, module config,
+// project context and prior artifacts for a step.
+func BuildStepContext(projectPath string, in ContextInput) (*StepContext, error) {
+	workflow, err := project.FindWorkflow(projectPath, in.Workflow)
+	if err != nil {
+		return nil, err
+	}
+
+	agent, err := project.AgentForWorkflow(projectPath, *workflow)
+	if err != nil {
+		return nil, fmt.Errorf("resolving agent: %w", err)
+	}
+
+	config, err := project.LoadModuleConfig(projectPath, workflow.Module)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("loading module config: %w", err)
+	}
+
+	return &StepContext{
+		JourneyID:      in.JourneyID,
+		StepIndex:      in.StepIndex,
+		Workflow:       *workflow,
+		Agent:          agent,
+		Config:         config,
+		ProjectContext: in.ProjectContext,
+		PriorArtifacts: collectPriorArtifacts(projectPath, in.PriorArtifacts),
+
+		PreviousAttempts: in.PreviousAttempts,
+		Feedback:         in.Feedback,
+	}, nil
+}
+
+// collectPriorArtifacts merges journey artifacts with artifacts already in
+// _bmad-output/, returning a sorted, de-duplicated list.
+func collectPriorArtifacts(projectPath string, journeyArtifacts []string) []string {
+	seen := make(map[string]bool)
+	artifacts := []string{}
+	add := func(p string) {
+		p = filepath.ToSlash(p)
+		if p != "" && !seen[p] {
+			seen[p] = true
+			artifacts = append(artifacts, p)
+		}
+	}
+
+	for _, a := range journeyArtifacts {
+		add(a)
+	}
+	if scan, err := project.Scan(projectPath); err == nil {
+		for _, a := range scan.ExistingArtifacts {
+			add(a.Path)
+		}
+	}
+
+	sort.Strings(artifacts)
+	return artifacts
+}
+
+// Render produces the deterministic markdown instruction file for the step.
+// The same inputs always render byte-identical output.
+func (c *StepContext) Render() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# BMAD Step Context\n\n")
+	fmt.Fprintf(&b, "Journey `%s`, step %d.\n\n", c.JourneyID, c.StepIndex)
+
+	fmt.Fprintf(&b, "## Workflow\n\n")
+	fmt.Fprintf(&b, "- Name: %s\n", c.Workflow.Name)
+	fmt.Fprintf(&b, "- Module: %s\n", c.Workflow.Module)
+	if c.Workflow.Description != "" {
+		fmt.Fprintf(&b, "- Description: %s\n", c.Workflow.Description)
+	}
+	fmt.Fprintf(&b, "- Instructions: `%s`\n\n", c.Workflow.Path)
+	fmt.Fprintf(&b, "Load the workflow instructions file above and execute it from start to finish.\n\n")
+
+	if c.Agent != nil {
+		a := c.Agent
+		fmt.Fprintf(&b, "## Agent Persona\n\n")
+		fmt.Fprintf(&b, "- Agent: %s %s (%s), %s\n", a.Icon, a.DisplayName, a.Name, a.Title)
+		writeField(&b, "Role", a.Role)
+		writeField(&b, "Identity", a.Identity)
+		writeField(&b, "Communication style", a.CommunicationStyle)
+		writeField(&b, "Principles", a.Principles)
+		if a.Path != "" {
+			fmt.Fprintf(&b, "- Definition: `%s`\n", a.Path)
+		}
+		fmt.Fprintf(&b, "\nAdopt this persona for the whole step.\n\n")
+	}
+
+	if len(c.Config) > 0 {
+		fmt.Fprintf(&b, "## Configuration (`_bmad/%s/config.yaml`)\n\n", c.Workflow.Module)
+		for _, v := range c.Config {
+			fmt.Fprintf(&b, "- %s: %s\n", v.Key, v.Value)
+		}
+		fmt.Fprintf(&b, "\n")
+	}
+
+	if c.ProjectContext != "" {
+		fmt.Fprintf(&b, "## Project Context\n\n%s\n\n", c.ProjectContext)
+	}
+
+	fmt.Fprintf(&b, "## Prior Artifacts\n\n")
+	if len(c.PriorArtifacts) == 0 {
+		fmt.Fprintf(&b, "None yet.\n\n")
+	} else {
+		fmt.Fprintf(&b, "Relative to `_bmad-output/`. Use them as input documents where the workflow asks for them.\n\n")
+		for _, a := range c.PriorArtifacts {
+			fmt.Fprintf(&b, "- `%s`\n", a)
+		}
+		fmt.Fprintf(&b, "\n")
+	}
+
+	if len(c.PreviousAttempts) > 0 {
+		fmt.Fprintf(&b, "## Previous Attempts\n\n")
+		fmt.Fprintf(&b, "This step was attempted before. Avoid repeating these failures.\n\n")
+		for _, a := range c.PreviousAttempts {
+			fmt.Fprintf(&b, "### Attempt %d (%s)\n\n", a.Number, a.Outcome)
+			writeField(&b, "Error", a.Error)
+			for _, check := range a.FailedChecks {
+				fmt.Fprintf(&b, "- Failed check `%s`: %s\n", check.Name, check.Evidence)
+			}
+			for _, f := range a.Feedback {
+				writeField(&b, "User feedback", f)
+			}
+			fmt.Fprintf(&b, "\n")
+		}
+	}
+
+	if len(c.Feedback) > 0 {
+		fmt.Fprintf(&b, "## User Feedback\n\n")
+		fmt.Fprintf(&b, "The user gave this feedback for the current attempt. It takes precedence over earlier instructions.\n\n")
+		for _, f := range c.Feedback {
+			fmt.Fprintf(&b, "- %s\n", strings.TrimSpace(f))
+		}
+		fmt.Fprintf(&b, "\n")
+	}
+
+	fmt.Fprintf(&b, "## Execution Rules\n\n")
+	fmt.Fprintf(&b, "- You are running under Auto-BMAD; write every artifact the workflow produces to disk.\n")
+	fmt.Fprintf(&b, "- When the workflow asks for a choice, present it as a numbered list and wait for input.\n")
+
+	return b.String()
+}
+
+// writeField writes a "- Label: value" line if value is non-empty.
+func writeField(b *strings.Builder, label, value string) {
+	if value != "" {
+		fmt.Fprintf(b, "- %s: %s\n", label, strings.TrimSpace(value))
+	}
+}
+
+// WriteContextFile writes rendered context to
+// .autobmad/journeys/<id>/steps/<n>/context.md and returns its path.
+func WriteContextFile(store *state.Manager, journeyID string, stepIndex int, content string) (string, error) {
+	if err := state.ValidateJourneyID(journeyID); err != nil {
+		return "", err
+	}
+
+	dir := store.StepDir(journeyID, stepIndex)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("creating step directory: %w", err)
+	}
+
+	path := filepath.Join(dir, ContextFileName)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return "", fmt.Errorf("writing context file: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,118 @@
+package journey
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/state"
+)
+
+// newTestProject creates a BMAD project with a minimal manifest set.
+func newTestProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"_bmad/_config/workflow-manifest.csv": "name,description,module,path\n" +
+			`"prd","Create PRDs","bmm","_bmad/bmm/workflows/prd/workflow.md"` + "\n" +
+			`"create-architecture","Architecture","bmm","_bmad/bmm/workflows/arch/workflow.md"` + "\n",
+		"_bmad/_config/agent-manifest.csv": "name,displayName,title,icon,role,identity,communicationStyle,principles,module,path\n" +
+			`"pm","John","Product Manager","📋","PM","Detective","Direct","Ship value","bmm","_bmad/bmm/agents/pm.md"` + "\n",
+		"_bmad/_config/bmad-help.csv": "module,phase,name,code,sequence,workflow-file,command,required,agent-name\n" +
+			"bmm,2-planning,Create PRD,CP,10,_bmad/bmm/workflows/prd/workflow.md,bmad_bmm_create-prd,true,pm\n",
+		"_bmad/bmm/config.yaml":                            "project_name: demo\nuser_name: Tester\n",
+		"_bmad-output/planning-artifacts/product-brief.md": "# Brief\n",
+	}
+	for rel, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestBuildStepContext(t *testing.T) {
+	dir := newTestProject(t)
+
+	sc, err := BuildStepContext(dir, ContextInput{
+		JourneyID:      "j-1",
+		StepIndex:      1,
+		Workflow:       "prd",
+		ProjectContext: "A todo app",
+		PriorArtifacts: []string{"planning-artifacts/research.md", "planning-artifacts/product-brief.md"},
+	})
+	if err != nil {
+		t.Fatalf("BuildStepContext failed: %v", err)
+	}
+
+	if sc.Agent == nil || sc.Agent.Name != "pm" {
+		t.Errorf("Agent = %+v, want pm", sc.Agent)
+	}
+	if len(sc.Config) != 2 {
+		t.Errorf("Config = %+v, want 2 values", sc.Config)
+	}
+	want := []string{"planning-artifacts/product-brief.md", "planning-artifacts/research.md"}
+	if strings.Join(sc.PriorArtifacts, ",") != strings.Join(want, ",") {
+		t.Errorf("PriorArtifacts = %v, want %v", sc.PriorArtifacts, want)
+	}
+
+	content := sc.Render()
+	for _, fragment := range []string{
+		"_bmad/bmm/workflows/prd/workflow.md",
+		"John (pm)",
+		"- user_name: Tester",
+		"A todo app",
+		"`planning-artifacts/research.md`",
+	} {
+		if !strings.Contains(content, fragment) {
+			t.Errorf("rendered context missing %q:\n%s", fragment, content)
+		}
+	}
+
+	// Rendering is deterministic
+	again, _ := BuildStepContext(dir, ContextInput{
+		JourneyID:      "j-1",
+		StepIndex:      1,
+		Workflow:       "prd",
+		ProjectContext: "A todo app",
+		PriorArtifacts: []string{"planning-artifacts/product-brief.md", "planning-artifacts/research.md"},
+	})
+	if again.Render() != content {
+		t.Error("Render is not deterministic")
+	}
+}
+
+func TestBuildStepContext_UnknownWorkflow(t *testing.T) {
+	dir := newTestProject(t)
+	if _, err := BuildStepContext(dir, ContextInput{Workflow: "nope"}); err == nil {
+		t.Error("expected error for unknown workflow")
+	}
+}
+
+func TestWriteContextFile(t *testing.T) {
+	dir := t.TempDir()
+	store := state.NewManager(dir)
+
+	path, err := WriteContextFile(store, "j-1", 2, "hello")
+	if err != nil {
+		t.Fatalf("WriteContextFile failed: %v", err)
+	}
+
+	want := filepath.Join(dir, "_bmad-output", ".autobmad", "journeys", "j-1", "steps", "2", "context.md")
+	if path != want {
+		t.Errorf("path = %q, want %q", path, want)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "hello" {
+		t.Errorf("content = %q, want hello", data)
+	}
+
+	if _, err := WriteContextFile(store, "../x", 0, "bad"); err == nil {
+		t.Error("expected error for unsafe journey id")
+	}
+}
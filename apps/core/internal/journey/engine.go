@@ -0,0 +1,1052 @@
+package journey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/checkpoint"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/network"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/opencode"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/state"
+)
+
+// contextPrompt is the message sent to OpenCode alongside the attached context file.
+const contextPrompt = "Execute the BMAD step described in the attached context file."
+
+// ErrNotFound is returned when a journey ID is unknown.
+var ErrNotFound = errors.New("journey not found")
+
+// ErrInvalidState is returned when an operation is not allowed in the journey's current state.
+var ErrInvalidState = errors.New("invalid journey state")
+
+// Runner executes OpenCode requests. *opencode.Executor implements it.
+type Runner interface {
+	Run(ctx context.Context, req opencode.ExecRequest) (*opencode.ExecResult, error)
+}
+
+// Engine orchestrates journeys for a single project: it runs each step
+// through the Runner, judges completion and persists state after every change.
+type Engine struct {
+	projectPath string
+	store       *state.Manager
+	history     *state.History
+	runner      Runner
+	detector    *CompletionDetector
+	checkpoints *checkpoint.Checkpointer
+
+	// Emit sends journey events to the client.
+	Emit func(event string, data interface{})
+	// Settings returns the current settings; defaults are used when nil.
+	Settings func() *state.Settings
+	// ProjectContext returns the project description stored via project.setContext.
+	ProjectContext func() string
+	// NetworkStatus returns the current connectivity; used to classify
+	// failures and to hold steps that need the network while offline.
+	NetworkStatus func() network.Status
+
+	mu          sync.Mutex
+	journeys    map[string]*Journey
+	cancels     map[string]context.CancelFunc
+	stops       map[string]context.CancelFunc  // Stops the current attempt of a journey
+	flags       map[string]*YellowFlagDetector // Yellow-flag detector of the current attempt
+	lastNetwork network.Status                 // Last status passed to NetworkChanged
+	resumeTimer *time.Timer                    // Resumes journeys held for the network
+	wg          sync.WaitGroup
+}
+
+// NewEngine creates an engine for the project using the given runner.
+// Completion rules are loaded from the built-in defaults and the project file.
+func NewEngine(projectPath string, runner Runner) (*Engine, error) {
+	rules, err := LoadRuleSet(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Engine{
+		projectPath: projectPath,
+		store:       state.NewManager(projectPath),
+		runner:      runner,
+		detector:    NewCompletionDetector(rules),
+		checkpoints: checkpoint.NewCheckpointer(projectPath),
+		journeys:    make(map[string]*Journey),
+		cancels:     make(map[string]context.CancelFunc),
+		stops:       make(map[string]context.CancelFunc),
+		flags:       make(map[string]*YellowFlagDetector),
+	}
+	e.history = state.NewHistory(e.store, e.summarize)
+	if err := e.adoptNetworkHolds(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Store returns the journey state manager.
+func (e *Engine) Store() *state.Manager {
+	return e.store
+}
+
+// History returns the index of finished journeys.
+func (e *Engine) History() *state.History {
+	return e.history
+}
+
+// Start creates a journey for the given route and begins running it in the background.
+func (e *Engine) Start(route []string, profile string) (*Journey, error) {
+	if len(route) == 0 {
+		return nil, fmt.Errorf("route must contain at least one workflow")
+	}
+
+	j := NewJourney(e.projectPath, route, profile)
+
+	e.mu.Lock()
+	e.journeys[j.ID] = j
+	if err := e.saveLocked(j); err != nil {
+		delete(e.journeys, j.ID)
+		e.mu.Unlock()
+		return nil, err
+	}
+	snapshot := j.Clone()
+	e.mu.Unlock()
+
+	e.launch(j)
+	return snapshot, nil
+}
+
+// launch runs the journey from its current step in a new goroutine.
+func (e *Engine) launch(j *Journey) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	e.mu.Lock()
+	e.cancels[j.ID] = cancel
+	e.mu.Unlock()
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		defer cancel()
+		e.run(ctx, j)
+	}()
+}
+
+// Get returns a snapshot of a journey, loading it from disk if it is not in memory.
+func (e *Engine) Get(id string) (*Journey, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	j, err := e.lookupLocked(id)
+	if err != nil {
+		return nil, err
+	}
+	return j.Clone(), nil
+}
+
+// lookupLocked finds a journey in memory or on disk. Caller must hold e.mu.
+func (e *Engine) lookupLocked(id string) (*Journey, error) {
+	if j, ok := e.journeys[id]; ok {
+		return j, nil
+	}
+
+	j := &Journey{}
+	if err := e.store.LoadJourney(id, j); err != nil {
+		if errors.Is(err, state.ErrJourneyNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		return nil, err
+	}
+	e.journeys[id] = j
+	return j, nil
+}
+
+// Abort cancels a running journey. The current step's process is terminated.
+func (e *Engine) Abort(id string) (*Journey, error) {
+	e.mu.Lock()
+	j, err := e.lookupLocked(id)
+	if err != nil {
+		e.mu.Unlock()
+		return nil, err
+	}
+	if j.Status.IsFinished() {
+		e.mu.Unlock()
+		return nil, fmt.Errorf("%w: journey is %s", ErrInvalidState, j.Status)
+	}
+
+	cancel, running := e.cancels[id]
+	if !running {
+		// Not running in this process (e.g. pending after restart): finish it directly
+		e.finishLocked(j, StatusAborted, "aborted by user")
+	}
+	e.mu.Unlock()
+
+	if running {
+		cancel()
+	}
+	return e.Get(id)
+}
+
+// Replan changes the remaining route of an unfinished journey (FR10).
+// Completed steps and attempted steps are never rewritten; see ReplanRequest.
+func (e *Engine) Replan(id string, req ReplanRequest) (*Journey, *RouteRevision, error) {
+	e.mu.Lock()
+	j, err := e.lookupLocked(id)
+	if err != nil {
+		e.mu.Unlock()
+		return nil, nil, err
+	}
+	if j.Status.IsFinished() {
+		e.mu.Unlock()
+		return nil, nil, fmt.Errorf("%w: journey is %s", ErrInvalidState, j.Status)
+	}
+
+	_, active := e.cancels[id]
+	rev, err := j.replan(req, active)
+	if err != nil {
+		e.mu.Unlock()
+		return nil, nil, err
+	}
+	if flag := j.YellowFlag; flag != nil && j.Steps[flag.StepIndex].Status == StepSkipped {
+		// The question belonged to a step that is no longer on the route
+		j.Steps[flag.StepIndex].YellowFlags = append(j.Steps[flag.StepIndex].YellowFlags, flag)
+		j.YellowFlag = nil
+	}
+	e.saveLocked(j)
+	snapshot := j.Clone()
+	e.mu.Unlock()
+
+	e.emit("journey.replanned", map[string]interface{}{
+		"journeyId": id,
+		"revision":  rev,
+	})
+	return snapshot, rev, nil
+}
+
+// FailureReport explains the journey's most recent failed attempt.
+func (e *Engine) FailureReport(id string) (*FailureReport, error) {
+	j, err := e.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	settings := e.settings()
+	r, err := BuildFailureReport(j, settings.StepTimeoutDefault)
+	if err != nil {
+		return nil, err
+	}
+	overrideTimeoutAction(j, r, settings)
+	return r, nil
+}
+
+// overrideTimeoutAction suggests actions for the timeout of the failed
+// workflow's override when it has its own timeout; the default would not
+// apply to it.
+func overrideTimeoutAction(j *Journey, r *FailureReport, settings *state.Settings) {
+	o, ok := settings.WorkflowOverrides[r.Workflow]
+	if !ok || o.StepTimeout == nil {
+		return
+	}
+	r.Actions = suggestActions(j, r.Category, *o.StepTimeout)
+	for i := range r.Actions {
+		if r.Actions[i].ID != "increase-timeout" {
+			continue
+		}
+		overrides := make(map[string]state.WorkflowOverride, len(settings.WorkflowOverrides))
+		for name, v := range settings.WorkflowOverrides {
+			overrides[name] = v
+		}
+		longer, _ := longerTimeout(*o.StepTimeout)
+		o.StepTimeout = &longer
+		overrides[r.Workflow] = o
+		r.Actions[i].Description = fmt.Sprintf("Raise the %s step timeout to %s, then retry.", r.Workflow, formatDuration(int64(longer)))
+		r.Actions[i].Params = map[string]interface{}{"workflowOverrides": overrides}
+	}
+}
+
+// Summary returns the completion summary of a journey (FR41).
+func (e *Engine) Summary(id string) (*Summary, error) {
+	j, err := e.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	return BuildSummary(j, time.Now()), nil
+}
+
+// ExportReport renders a journey's summary to
+// _bmad-output/.autobmad/reports/<id>.<format> and returns the file path.
+// An existing report of the same journey and format is replaced.
+func (e *Engine) ExportReport(id string, format ReportFormat) (string, error) {
+	summary, err := e.Summary(id)
+	if err != nil {
+		return "", err
+	}
+	data, err := RenderReport(summary, format)
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(e.store.Root(), "reports")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("creating reports directory: %w", err)
+	}
+	path := filepath.Join(dir, id+"."+string(format))
+	tempPath := path + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0644); err != nil {
+		return "", fmt.Errorf("writing report: %w", err)
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		return "", fmt.Errorf("renaming report: %w", err)
+	}
+	return path, nil
+}
+
+// Running returns the IDs of the journeys running in this process.
+func (e *Engine) Running() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ids := make([]string, 0, len(e.cancels))
+	for id := range e.cancels {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Wait blocks until all running journeys have stopped.
+func (e *Engine) Wait() {
+	e.wg.Wait()
+}
+
+// PreviewContext renders the context a step would receive without running it.
+// If journeyID is empty, the workflow is previewed as the first step of a new journey.
+func (e *Engine) PreviewContext(workflow, journeyID string, stepIndex int) (*StepContext, string, error) {
+	in := ContextInput{
+		JourneyID:      journeyID,
+		StepIndex:      stepIndex,
+		Workflow:       workflow,
+		ProjectContext: e.projectContext(),
+	}
+
+	if journeyID != "" {
+		j, err := e.Get(journeyID)
+		if err != nil {
+			return nil, "", err
+		}
+		if stepIndex < 0 || stepIndex >= len(j.Steps) {
+			return nil, "", fmt.Errorf("step index %d out of range", stepIndex)
+		}
+		if in.Workflow == "" {
+			in.Workflow = j.Steps[stepIndex].Workflow
+		}
+		in.PriorArtifacts = j.PriorArtifacts(stepIndex)
+	}
+
+	sc, err := BuildStepContext(e.projectPath, in)
+	if err != nil {
+		return nil, "", err
+	}
+	return sc, sc.Render(), nil
+}
+
+// stepOutcome is how a step's run ended.
+type stepOutcome int
+
+const (
+	stepSucceeded stepOutcome = iota
+	stepFailed                // Failed without retries left to try; escalated to the user
+	stepAborted               // Cancelled by Abort
+	stepPaused                // Stopped to wait for the answer to a yellow flag or the network
+	stepRestart               // Interrupted, but already answered; run the step again
+)
+
+// run executes steps sequentially from the journey's current step.
+func (e *Engine) run(ctx context.Context, j *Journey) {
+	e.mu.Lock()
+	j.Status = StatusRunning
+	j.PauseReason = ""
+	if j.StartedAt == nil {
+		j.StartedAt = timePtr(time.Now())
+	}
+	e.saveLocked(j)
+	startedAt := *j.StartedAt
+	e.mu.Unlock()
+
+	e.emit("journey.started", map[string]interface{}{
+		"journeyId": j.ID,
+		"workflow":  j.Destination,
+		"startedAt": startedAt,
+	})
+
+	for {
+		e.mu.Lock()
+		index := j.CurrentStep
+		done := index >= len(j.Steps)
+		e.mu.Unlock()
+
+		if done {
+			e.finish(j, StatusCompleted, "")
+			return
+		}
+
+		switch e.runStep(ctx, j, index) {
+		case stepPaused:
+			return
+		case stepRestart:
+			continue
+		case stepAborted:
+			e.finish(j, StatusAborted, "aborted by user")
+			return
+		case stepFailed:
+			e.escalate(j, index)
+			return
+		}
+
+		e.mu.Lock()
+		j.CurrentStep = index + 1
+		e.saveLocked(j)
+		e.mu.Unlock()
+	}
+}
+
+// runStep executes a step through the retry controller. Each attempt after
+// the first receives the failure evidence and feedback of the earlier ones.
+func (e *Engine) runStep(ctx context.Context, j *Journey, index int) stepOutcome {
+	if e.holdIfOffline(j, index) {
+		return stepPaused
+	}
+
+	e.mu.Lock()
+	step := j.Steps[index]
+	step.Status = StepRunning
+	step.StartedAt = timePtr(time.Now())
+	step.CompletedAt = nil
+	step.Error = ""
+	first := len(step.Attempts) + 1
+	workflow := step.Workflow
+	e.saveLocked(j)
+	e.mu.Unlock()
+
+	e.emit("step.started", map[string]interface{}{
+		"journeyId": j.ID,
+		"stepIndex": index,
+		"stepName":  workflow,
+	})
+
+	rc := NewRetryController(RetryPolicyForWorkflow(e.settings(), workflow))
+	rc.OnRetry = func(nextAttempt int, delay time.Duration) {
+		e.mu.Lock()
+		lastError := step.Error
+		e.mu.Unlock()
+
+		e.emit("step.retrying", map[string]interface{}{
+			"journeyId": j.ID,
+			"stepIndex": index,
+			"attempt":   nextAttempt,
+			"delayMs":   delay.Milliseconds(),
+			"lastError": lastError,
+		})
+	}
+
+	rc.Final = func(n int) bool {
+		// A process killed as stalled fails the step (stallAction kill)
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		for _, a := range step.Attempts {
+			if a.Number == n {
+				return a.StallAction == opencode.StallActionKill
+			}
+		}
+		return false
+	}
+
+	outcome, exhausted := rc.Run(ctx, first, func(n int) AttemptOutcome {
+		return e.runAttempt(ctx, j, index, n)
+	})
+
+	switch {
+	case outcome == AttemptSucceeded:
+		sha := e.checkpoint(j, index, workflow)
+		e.mu.Lock()
+		step.Status = StepCompleted
+		step.CompletedAt = timePtr(time.Now())
+		step.Checkpoint = sha
+		artifacts := step.Artifacts
+		e.saveLocked(j)
+		e.mu.Unlock()
+
+		e.emit("step.completed", map[string]interface{}{
+			"journeyId":  j.ID,
+			"stepIndex":  index,
+			"status":     StepCompleted,
+			"artifacts":  artifacts,
+			"checkpoint": sha,
+		})
+		return stepSucceeded
+
+	case outcome == AttemptInterrupted && ctx.Err() == nil:
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if j.YellowFlag == nil && j.NetworkHold == nil {
+			// Answered or back online while the attempt was stopping
+			return stepRestart
+		}
+		// Checked together with the flag and hold so SubmitFeedback and
+		// the automatic resume see a consistent state
+		delete(e.cancels, j.ID)
+		step.Status = StepPending
+		e.saveLocked(j)
+		return stepPaused
+
+	case outcome == AttemptCancelled || ctx.Err() != nil:
+		e.failStep(j, index, "cancelled", false)
+		return stepAborted
+
+	default:
+		e.mu.Lock()
+		reason := step.Error
+		e.mu.Unlock()
+		e.failStep(j, index, reason, !exhausted)
+		return stepFailed
+	}
+}
+
+// runAttempt executes one attempt of a step and records it on the step.
+func (e *Engine) runAttempt(ctx context.Context, j *Journey, index, number int) AttemptOutcome {
+	attemptCtx, stopAttempt := context.WithCancel(ctx)
+	defer stopAttempt()
+	defer func() {
+		e.mu.Lock()
+		delete(e.stops, j.ID)
+		delete(e.flags, j.ID)
+		e.mu.Unlock()
+	}()
+
+	e.mu.Lock()
+	if j.NetworkHold != nil {
+		// Went offline while waiting to retry
+		e.mu.Unlock()
+		return AttemptInterrupted
+	}
+	// Registered with the check so going offline always stops the attempt
+	e.stops[j.ID] = stopAttempt
+	step := j.Steps[index]
+	attempt := &Attempt{
+		Number:    number,
+		Outcome:   AttemptRunning,
+		StartedAt: time.Now(),
+		Feedback:  step.Feedback,
+		LogFile:   filepath.Join(e.store.StepDir(j.ID, index), fmt.Sprintf("attempt-%d.log", number)),
+	}
+	attempt.TranscriptFile = strings.TrimSuffix(attempt.LogFile, ".log") + ".transcript.jsonl"
+	in := ContextInput{
+		JourneyID:        j.ID,
+		StepIndex:        index,
+		Workflow:         step.Workflow,
+		ProjectContext:   e.projectContext(),
+		PriorArtifacts:   j.PriorArtifacts(index),
+		PreviousAttempts: step.attemptSummaries(),
+		Feedback:         step.Feedback,
+	}
+	step.Feedback = nil
+	if len(step.Attempts) == 0 {
+		// Remember what existed so the summary can tell created from modified artifacts
+		step.Baseline = e.detector.ExistingArtifacts(step.Workflow, e.projectPath)
+	}
+	step.Attempts = append(step.Attempts, attempt)
+	profile := j.Profile
+	e.saveLocked(j)
+	e.mu.Unlock()
+
+	// The workflow's override may replace the profile and timeout
+	settings := e.settings()
+	ws := settings.ForWorkflow(in.Workflow)
+	if ws.Profile != "" {
+		profile = ws.Profile
+	}
+
+	sc, err := BuildStepContext(e.projectPath, in)
+	if err != nil {
+		return e.finishAttempt(j, index, attempt, AttemptFailed, fmt.Sprintf("building context: %v", err), nil)
+	}
+	contextFile, err := WriteContextFile(e.store, j.ID, index, sc.Render())
+	if err != nil {
+		return e.finishAttempt(j, index, attempt, AttemptFailed, err.Error(), nil)
+	}
+
+	e.mu.Lock()
+	step.ContextFile = contextFile
+	attempt.ContextFile = contextFile
+	e.saveLocked(j)
+	e.mu.Unlock()
+
+	flags := e.newYellowFlagDetector(j.ID)
+	if flags != nil {
+		e.mu.Lock()
+		e.flags[j.ID] = flags
+		e.mu.Unlock()
+	}
+	events := &eventStream{}
+	output := func(stream, text string) {
+		if text == "" {
+			return
+		}
+		e.emit("opencode.output", map[string]interface{}{
+			"journeyId": j.ID,
+			"stepIndex": index,
+			"chunk":     text,
+			"stream":    stream,
+		})
+		if flags != nil && stream == opencode.StreamStdout {
+			if flag := flags.Feed([]byte(text)); flag != nil {
+				e.raiseYellowFlag(j, index, number, flag, stopAttempt)
+			}
+		}
+	}
+
+	// OpenCode reports usage only in its JSON event stream
+	args := append(slices.Clone(ws.ExtraArgs), "--file", contextFile, "--format", "json", contextPrompt)
+
+	result, err := e.runner.Run(attemptCtx, opencode.ExecRequest{
+		JourneyID: j.ID,
+		StepIndex: index,
+		Dir:       e.projectPath,
+		Profile:   profile,
+		Args:      args,
+		Env:       []string{"AUTOBMAD_JOURNEY_ID=" + j.ID, "AUTOBMAD_CONTEXT_FILE=" + contextFile},
+		Timeout:   time.Duration(ws.StepTimeout) * time.Millisecond,
+		LogPath:   attempt.LogFile,
+
+		TranscriptPath: attempt.TranscriptFile,
+
+		HeartbeatInterval: time.Duration(settings.HeartbeatInterval) * time.Millisecond,
+		StallIntervals:    settings.StallIntervals,
+		StallAction:       opencode.StallAction(settings.StallAction),
+		InputMode:         opencode.InputMode(settings.InputMode),
+
+		OnOutput: func(stream string, chunk []byte) {
+			if stream == opencode.StreamStdout {
+				output(stream, events.Feed(chunk))
+			} else {
+				output(stream, string(chunk))
+			}
+		},
+	})
+	output(opencode.StreamStdout, events.Flush())
+	e.mu.Lock()
+	attempt.Usage = events.Usage()
+	e.mu.Unlock()
+	if e.awaitingInput(j) {
+		// The process ended or was stopped while a question is open; the
+		// answer goes into the next attempt's context instead
+		e.mu.Lock()
+		j.YellowFlag.Delivery = DeliverContext
+		e.mu.Unlock()
+		return e.finishAttempt(j, index, attempt, AttemptInterrupted, "stopped for user input", nil)
+	}
+	if e.heldForNetwork(j) {
+		// Stopped because the network went offline; the step runs again once it is back
+		return e.finishAttempt(j, index, attempt, AttemptInterrupted, "stopped: network offline", nil)
+	}
+	if err != nil {
+		return e.finishAttempt(j, index, attempt, AttemptFailed, err.Error(), &FailureSignals{RunError: err.Error()})
+	}
+
+	stdout := readableOutput(result.Output)
+	verdict := e.detector.Detect(CompletionInput{
+		Workflow:    in.Workflow,
+		ProjectPath: e.projectPath,
+		ExitCode:    result.ExitCode,
+		Output:      stdout,
+		StartedAt:   attempt.StartedAt,
+	})
+
+	e.mu.Lock()
+	step.ExitCode = result.ExitCode
+	step.Verdict = verdict
+	step.Artifacts = verdict.Artifacts
+	attempt.ExitCode = result.ExitCode
+	attempt.Verdict = verdict
+	attempt.StallAction = result.StallAction
+	e.mu.Unlock()
+
+	signals := &FailureSignals{
+		ExitCode: result.ExitCode,
+		Stdout:   stdout,
+		Stderr:   result.Stderr,
+		TimedOut: result.TimedOut,
+		Stalled:  result.Stalled,
+		RunError: result.Error,
+		Verdict:  verdict,
+	}
+	switch {
+	case result.Cancelled || ctx.Err() != nil:
+		return e.finishAttempt(j, index, attempt, AttemptCancelled, "cancelled", signals)
+	case !verdict.Success || result.Stalled:
+		// A stalled process was stopped; it only runs again for stallAction retry
+		return e.finishAttempt(j, index, attempt, AttemptFailed, describeFailure(result, verdict), signals)
+	default:
+		return e.finishAttempt(j, index, attempt, AttemptSucceeded, "", nil)
+	}
+}
+
+// checkpoint commits the BMAD output after a completed step when the
+// gitCheckpoints setting is on, and returns the commit SHA. Without a
+// repository or changes there is no checkpoint; a failed commit is reported
+// but does not fail the step.
+func (e *Engine) checkpoint(j *Journey, index int, workflow string) string {
+	if !e.settings().GitCheckpoints {
+		return ""
+	}
+	sha, err := e.checkpoints.Commit(fmt.Sprintf("auto-bmad: %s (journey %s, step %d)", workflow, j.ID, index+1))
+	if err != nil {
+		e.emit("journey.error", map[string]interface{}{
+			"journeyId": j.ID,
+			"stepIndex": index,
+			"error":     fmt.Sprintf("checkpoint: %v", err),
+		})
+	}
+	return sha
+}
+
+// finishAttempt records an attempt's outcome on the attempt and its step.
+// Unsuccessful attempts are classified from signals, if any.
+func (e *Engine) finishAttempt(j *Journey, index int, attempt *Attempt, outcome AttemptOutcome, reason string, signals *FailureSignals) AttemptOutcome {
+	var failure *Classification
+	status := e.networkStatus()
+	if outcome == AttemptFailed || outcome == AttemptCancelled {
+		if signals == nil {
+			signals = &FailureSignals{}
+		}
+		signals.Outcome = outcome
+		signals.Network = status
+		c := ClassifyFailure(*signals)
+		failure = &c
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	attempt.Failure = failure
+	attempt.Network = status
+	attempt.Outcome = outcome
+	attempt.Error = reason
+	attempt.FinishedAt = time.Now()
+	j.Steps[index].Error = reason
+	e.saveLocked(j)
+	return outcome
+}
+
+// InputSender is implemented by runners that can write to a running step's stdin.
+// *opencode.Executor implements it.
+type InputSender interface {
+	AcceptsInput(journeyID string, stepIndex int) bool
+	SendInput(journeyID string, stepIndex int, data []byte) error
+}
+
+// newYellowFlagDetector creates a detector from the current settings, or
+// returns nil when detection is disabled.
+func (e *Engine) newYellowFlagDetector(journeyID string) *YellowFlagDetector {
+	s := e.settings()
+	if !s.YellowFlagDetection {
+		return nil
+	}
+	d, err := NewYellowFlagDetector(s.YellowFlagPatterns)
+	if err != nil {
+		e.emit("journey.error", map[string]interface{}{
+			"journeyId": journeyID,
+			"error":     err.Error(),
+		})
+		d, _ = NewYellowFlagDetector(nil)
+	}
+	return d
+}
+
+// raiseYellowFlag pauses the journey on a detected flag and emits
+// journey.yellowFlag. If the runner cannot deliver the answer to the running
+// process, the attempt is stopped and the answer goes into the next one.
+func (e *Engine) raiseYellowFlag(j *Journey, index, attempt int, flag *YellowFlag, stopAttempt context.CancelFunc) {
+	sender, canSend := e.runner.(InputSender)
+	canSend = canSend && sender.AcceptsInput(j.ID, index)
+
+	e.mu.Lock()
+	if j.YellowFlag != nil || j.Status != StatusRunning {
+		// One open question at a time
+		e.mu.Unlock()
+		return
+	}
+	step := j.Steps[index]
+	flag.ID = fmt.Sprintf("yf-%d-%d-%d", index, attempt, len(step.YellowFlags)+1)
+	flag.StepIndex = index
+	flag.Attempt = attempt
+	flag.RaisedAt = time.Now()
+	flag.Delivery = DeliverContext
+	if canSend {
+		flag.Delivery = DeliverStdin
+	}
+	j.YellowFlag = flag
+	j.Status = StatusPaused
+	j.PauseReason = "waiting for user input: " + flag.Question
+	e.saveLocked(j)
+	data := map[string]interface{}{
+		"journeyId": j.ID,
+		"stepIndex": index,
+		"flag":      *flag,
+	}
+	e.mu.Unlock()
+
+	e.emit("journey.yellowFlag", data)
+	if !canSend {
+		stopAttempt()
+	}
+}
+
+// awaitingInput reports whether the journey has an unanswered yellow flag.
+func (e *Engine) awaitingInput(j *Journey) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return j.YellowFlag != nil
+}
+
+// SubmitFeedback answers the journey's open yellow flag, or adds general
+// feedback for the current step. Answers reach a running process through its
+// stdin when possible; otherwise the journey resumes with the answer in the
+// next attempt's context.
+func (e *Engine) SubmitFeedback(id, feedback string) (*Journey, error) {
+	feedback = strings.TrimSpace(feedback)
+	if feedback == "" {
+		return nil, fmt.Errorf("%w: feedback is empty", ErrInvalidState)
+	}
+
+	e.mu.Lock()
+	j, err := e.lookupLocked(id)
+	if err != nil {
+		e.mu.Unlock()
+		return nil, err
+	}
+	_, running := e.cancels[id]
+
+	flag := j.YellowFlag
+	if flag == nil {
+		if running && j.CurrentStep < len(j.Steps) {
+			// Queue it for the step's next attempt
+			step := j.Steps[j.CurrentStep]
+			step.Feedback = append(step.Feedback, feedback)
+			e.saveLocked(j)
+			e.mu.Unlock()
+			return e.Get(id)
+		}
+		e.mu.Unlock()
+		return e.Resume(id, feedback)
+	}
+
+	flag.Answer = feedback
+	flag.AnsweredAt = timePtr(time.Now())
+	step := j.Steps[flag.StepIndex]
+	step.YellowFlags = append(step.YellowFlags, flag)
+	j.YellowFlag = nil
+
+	if running {
+		j.Status = StatusRunning
+		j.PauseReason = ""
+		sender, canSend := e.runner.(InputSender)
+		if !canSend || flag.Delivery != DeliverStdin {
+			// The attempt is stopping; the run loop restarts the step with the answer
+			step.Feedback = append(step.Feedback, flag.feedback())
+			e.saveLocked(j)
+			e.mu.Unlock()
+			return e.Get(id)
+		}
+		flags := e.flags[id]
+		e.saveLocked(j)
+		e.mu.Unlock()
+
+		// The process waits for the answer, so nothing it printed after the
+		// question is lost; clearing first keeps the answered question out
+		// of the next flag's excerpt and options.
+		if flags != nil {
+			flags.Reset()
+		}
+		if err := sender.SendInput(id, flag.StepIndex, []byte(feedback+"\n")); err != nil {
+			return nil, fmt.Errorf("sending answer to step %d: %w", flag.StepIndex, err)
+		}
+		return e.Get(id)
+	}
+
+	e.saveLocked(j)
+	e.mu.Unlock()
+	return e.Resume(id, flag.feedback())
+}
+
+// describeFailure summarizes why a step failed for the step error field.
+func describeFailure(result *opencode.ExecResult, verdict *CompletionVerdict) string {
+	switch {
+	case result.TimedOut:
+		return "step timed out"
+	case result.Stalled:
+		return "step stalled"
+	case result.Error != "":
+		return result.Error
+	}
+	if failed := verdict.FailedChecks(); len(failed) > 0 {
+		return fmt.Sprintf("%s: %s", failed[0].Name, failed[0].Evidence)
+	}
+	return "step failed"
+}
+
+// failStep marks a step failed, persists it and emits step.failed.
+func (e *Engine) failStep(j *Journey, index int, reason string, retryable bool) {
+	e.mu.Lock()
+	step := j.Steps[index]
+	step.Status = StepFailed
+	step.Error = reason
+	step.CompletedAt = timePtr(time.Now())
+	e.saveLocked(j)
+	e.mu.Unlock()
+
+	e.emit("step.failed", map[string]interface{}{
+		"journeyId": j.ID,
+		"stepIndex": index,
+		"error":     reason,
+		"retryable": retryable,
+	})
+}
+
+// escalate pauses a journey whose step ran out of retries and hands the
+// decision to the user, who can resume it with feedback or abort it.
+func (e *Engine) escalate(j *Journey, index int) {
+	e.mu.Lock()
+	step := j.Steps[index]
+	j.Status = StatusPaused
+	j.PauseReason = fmt.Sprintf("step %d (%s) failed after %d attempts", index, step.Workflow, len(step.Attempts))
+	delete(e.cancels, j.ID)
+	e.saveLocked(j)
+	data := map[string]interface{}{
+		"journeyId": j.ID,
+		"stepIndex": index,
+		"attempts":  len(step.Attempts),
+		"reason":    j.PauseReason,
+		"lastError": step.Error,
+	}
+	e.mu.Unlock()
+
+	e.emit("journey.escalated", data)
+}
+
+// Resume restarts a paused journey at its current step. Non-empty feedback
+// is added to the context of the step's next attempt, which gets a fresh
+// retry budget.
+func (e *Engine) Resume(id, feedback string) (*Journey, error) {
+	e.mu.Lock()
+	j, err := e.lookupLocked(id)
+	if err != nil {
+		e.mu.Unlock()
+		return nil, err
+	}
+	if _, running := e.cancels[id]; running {
+		e.mu.Unlock()
+		return nil, fmt.Errorf("%w: journey is already running", ErrInvalidState)
+	}
+	// A running journey without a goroutine was interrupted by a restart
+	if j.Status != StatusPaused && j.Status != StatusRunning && j.Status != StatusPending {
+		e.mu.Unlock()
+		return nil, fmt.Errorf("%w: journey is %s", ErrInvalidState, j.Status)
+	}
+
+	if flag := j.YellowFlag; flag != nil {
+		// Resuming without an answer dismisses the open question
+		j.Steps[flag.StepIndex].YellowFlags = append(j.Steps[flag.StepIndex].YellowFlags, flag)
+		j.YellowFlag = nil
+	}
+	if hold := j.NetworkHold; hold != nil {
+		j.NetworkHold = nil
+		j.record("journey.networkResumed", &hold.StepIndex, "resumed by user")
+	}
+	if feedback = strings.TrimSpace(feedback); feedback != "" && j.CurrentStep < len(j.Steps) {
+		step := j.Steps[j.CurrentStep]
+		step.Feedback = append(step.Feedback, feedback)
+	}
+	j.Status = StatusRunning
+	j.PauseReason = ""
+	e.saveLocked(j)
+	e.mu.Unlock()
+
+	e.launch(j)
+	return e.Get(id)
+}
+
+// finish moves the journey to a terminal state and emits journey.completed.
+func (e *Engine) finish(j *Journey, status Status, reason string) {
+	e.mu.Lock()
+	e.finishLocked(j, status, reason)
+	delete(e.cancels, j.ID)
+	e.mu.Unlock()
+}
+
+// finishLocked is finish without locking. Caller must hold e.mu.
+func (e *Engine) finishLocked(j *Journey, status Status, reason string) {
+	j.Status = status
+	j.Error = reason
+	j.NetworkHold = nil
+	j.CompletedAt = timePtr(time.Now())
+	e.saveLocked(j)
+	e.recordHistoryLocked(j)
+
+	e.emit("journey.completed", map[string]interface{}{
+		"journeyId":   j.ID,
+		"status":      status,
+		"completedAt": *j.CompletedAt,
+	})
+}
+
+// saveLocked persists the journey. Caller must hold e.mu.
+// Persistence errors are reported to the client but do not stop the journey.
+func (e *Engine) saveLocked(j *Journey) error {
+	if err := e.store.SaveJourney(j.ID, j); err != nil {
+		e.emit("journey.error", map[string]interface{}{
+			"journeyId": j.ID,
+			"error":     err.Error(),
+		})
+		return err
+	}
+	return nil
+}
+
+// networkStatus returns the current connectivity from the configured
+// source, or the last status passed to NetworkChanged.
+func (e *Engine) networkStatus() network.Status {
+	if e.NetworkStatus != nil {
+		return e.NetworkStatus()
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.lastNetwork
+}
+
+// settings returns the current settings or defaults.
+func (e *Engine) settings() *state.Settings {
+	if e.Settings != nil {
+		if s := e.Settings(); s != nil {
+			return s
+		}
+	}
+	return state.DefaultSettings()
+}
+
+// projectContext returns the stored project description, if any.
+func (e *Engine) projectContext() string {
+	if e.ProjectContext != nil {
+		return e.ProjectContext()
+	}
+	return ""
+}
+
+// emit forwards an event if an emitter is configured.
+func (e *Engine) emit(event string, data interface{}) {
+	if e.Emit != nil {
+		e.Emit(event, data)
+	}
+}
@@ -0,0 +1,654 @@
+package journey
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/opencode"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/state"
+)
+
+// fakeRunner simulates OpenCode runs. Each call invokes fn, which may write
+// artifacts and returns the exit code to report.
+type fakeRunner struct {
+	mu       sync.Mutex
+	requests []opencode.ExecRequest
+	fn       func(ctx context.Context, req opencode.ExecRequest) *opencode.ExecResult
+}
+
+func (r *fakeRunner) Run(ctx context.Context, req opencode.ExecRequest) (*opencode.ExecResult, error) {
+	r.mu.Lock()
+	r.requests = append(r.requests, req)
+	r.mu.Unlock()
+	result := r.fn(ctx, req)
+	result.JourneyID = req.JourneyID
+	result.StepIndex = req.StepIndex
+	return result, nil
+}
+
+func (r *fakeRunner) calls() []opencode.ExecRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]opencode.ExecRequest(nil), r.requests...)
+}
+
+// eventLog records emitted events.
+type eventLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *eventLog) emit(event string, data interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+}
+
+func (l *eventLog) has(event string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, e := range l.events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// writePRD writes a valid PRD artifact into the project.
+func writePRD(t *testing.T, project string) {
+	t.Helper()
+	path := filepath.Join(project, "_bmad-output", "planning-artifacts", "prd.md")
+	content := "# PRD\n## Executive Summary\n## Success Criteria\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Error(err)
+	}
+}
+
+func newTestEngine(t *testing.T, runner Runner) (*Engine, *eventLog) {
+	t.Helper()
+	engine, err := NewEngine(newTestProject(t), runner)
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	log := &eventLog{}
+	engine.Emit = log.emit
+	return engine, log
+}
+
+func TestEngine_RunsJourneyToCompletion(t *testing.T) {
+	var engine *Engine
+	runner := &fakeRunner{fn: func(ctx context.Context, req opencode.ExecRequest) *opencode.ExecResult {
+		writePRD(t, engine.projectPath)
+		return &opencode.ExecResult{ExitCode: 0, Output: "done"}
+	}}
+	engine, events := newTestEngine(t, runner)
+
+	j, err := engine.Start([]string{"prd"}, "")
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	engine.Wait()
+
+	got, err := engine.Get(j.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.Status != StatusCompleted {
+		t.Fatalf("Status = %s, want completed (step error: %s)", got.Status, got.Steps[0].Error)
+	}
+	if got.Steps[0].Artifacts[0] != "planning-artifacts/prd.md" {
+		t.Errorf("Artifacts = %v", got.Steps[0].Artifacts)
+	}
+
+	// The context file is written and passed to the executor
+	req := runner.calls()[0]
+	wantFile := filepath.Join(engine.Store().StepDir(j.ID, 0), ContextFileName)
+	if req.Args[0] != "--file" || req.Args[1] != wantFile {
+		t.Errorf("Args = %v, want --file %s", req.Args, wantFile)
+	}
+	if _, err := os.Stat(wantFile); err != nil {
+		t.Errorf("context file not written: %v", err)
+	}
+
+	for _, event := range []string{"journey.started", "step.started", "step.completed", "journey.completed"} {
+		if !events.has(event) {
+			t.Errorf("missing event %s", event)
+		}
+	}
+
+	// State survives a new engine instance
+	reloaded, _ := NewEngine(engine.projectPath, runner)
+	persisted, err := reloaded.Get(j.ID)
+	if err != nil || persisted.Status != StatusCompleted {
+		t.Errorf("persisted journey = %+v, %v", persisted, err)
+	}
+
+	// The finished journey is in the history index
+	rec, err := engine.History().Get(j.ID)
+	if err != nil {
+		t.Fatalf("history record missing: %v", err)
+	}
+	if rec.Status != "completed" || rec.StepsCompleted != 1 || rec.Artifacts[0] != "planning-artifacts/prd.md" {
+		t.Errorf("history record = %+v", rec)
+	}
+}
+
+func TestEngine_CheckpointsCompletedStep(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	var engine *Engine
+	runner := &fakeRunner{fn: func(ctx context.Context, req opencode.ExecRequest) *opencode.ExecResult {
+		writePRD(t, engine.projectPath)
+		return &opencode.ExecResult{ExitCode: 0, Output: "done"}
+	}}
+	engine, _ = newTestEngine(t, runner)
+	if out, err := exec.Command("git", "-C", engine.projectPath, "init", "-q").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+
+	j, err := engine.Start([]string{"prd"}, "")
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	engine.Wait()
+
+	got, _ := engine.Get(j.ID)
+	if got.Status != StatusCompleted {
+		t.Fatalf("Status = %s, want completed", got.Status)
+	}
+	head, err := exec.Command("git", "-C", engine.projectPath, "rev-parse", "HEAD").Output()
+	if err != nil {
+		t.Fatalf("no checkpoint commit: %v", err)
+	}
+	if sha := strings.TrimSpace(string(head)); got.Steps[0].Checkpoint != sha {
+		t.Errorf("Checkpoint = %q, want HEAD %s", got.Steps[0].Checkpoint, sha)
+	}
+	if s := BuildSummary(got, time.Now()); len(s.Checkpoints) != 1 || s.Checkpoints[0] != got.Steps[0].Checkpoint {
+		t.Errorf("summary Checkpoints = %v", s.Checkpoints)
+	}
+}
+
+// withRetries configures the engine to retry failed steps without delay.
+func withRetries(engine *Engine, maxRetries int) {
+	engine.Settings = func() *state.Settings {
+		s := state.DefaultSettings()
+		s.MaxRetries = maxRetries
+		s.RetryDelay = 0
+		return s
+	}
+}
+
+func TestEngine_EscalatesWhenArtifactMissing(t *testing.T) {
+	runner := &fakeRunner{fn: func(ctx context.Context, req opencode.ExecRequest) *opencode.ExecResult {
+		return &opencode.ExecResult{ExitCode: 0}
+	}}
+	engine, events := newTestEngine(t, runner)
+	withRetries(engine, 2)
+
+	j, _ := engine.Start([]string{"prd", "create-architecture"}, "")
+	engine.Wait()
+
+	got, _ := engine.Get(j.ID)
+	if got.Status != StatusPaused || got.PauseReason == "" {
+		t.Errorf("Status = %s (%q), want paused with reason", got.Status, got.PauseReason)
+	}
+	if got.Steps[0].Status != StepFailed || got.Steps[1].Status != StepPending {
+		t.Errorf("step statuses = %s, %s", got.Steps[0].Status, got.Steps[1].Status)
+	}
+	if n := len(got.Steps[0].Attempts); n != 3 {
+		t.Fatalf("attempts = %d, want 3", n)
+	}
+	for _, a := range got.Steps[0].Attempts {
+		if a.Outcome != AttemptFailed || a.Verdict == nil {
+			t.Errorf("attempt %d = %+v, want failed with verdict", a.Number, a)
+		}
+		if a.Failure == nil || a.Failure.Category != FailureValidation {
+			t.Errorf("attempt %d failure = %+v, want validation", a.Number, a.Failure)
+		}
+	}
+	for _, event := range []string{"step.retrying", "step.failed", "journey.escalated"} {
+		if !events.has(event) {
+			t.Errorf("missing event %s", event)
+		}
+	}
+	if events.has("journey.completed") {
+		t.Error("escalated journey must not complete")
+	}
+}
+
+func TestEngine_RetryCarriesFailureEvidence(t *testing.T) {
+	var engine *Engine
+	var contexts []string
+	runner := &fakeRunner{fn: func(ctx context.Context, req opencode.ExecRequest) *opencode.ExecResult {
+		data, _ := os.ReadFile(req.Args[1])
+		contexts = append(contexts, string(data))
+		if len(contexts) == 2 {
+			writePRD(t, engine.projectPath)
+		}
+		return &opencode.ExecResult{ExitCode: 0}
+	}}
+	engine, _ = newTestEngine(t, runner)
+	withRetries(engine, 3)
+
+	j, _ := engine.Start([]string{"prd"}, "")
+	engine.Wait()
+
+	got, _ := engine.Get(j.ID)
+	if got.Status != StatusCompleted {
+		t.Fatalf("Status = %s, want completed", got.Status)
+	}
+	attempts := got.Steps[0].Attempts
+	if len(attempts) != 2 || attempts[0].Outcome != AttemptFailed || attempts[1].Outcome != AttemptSucceeded {
+		t.Fatalf("attempts = %+v", attempts)
+	}
+	if strings.Contains(contexts[0], "## Previous Attempts") {
+		t.Error("first attempt must not list previous attempts")
+	}
+	if !strings.Contains(contexts[1], "### Attempt 1 (failed)") || !strings.Contains(contexts[1], "artifact:") {
+		t.Errorf("second context lacks failure evidence:\n%s", contexts[1])
+	}
+}
+
+func TestEngine_StalledAttempt(t *testing.T) {
+	tests := []struct {
+		action       string
+		wantStatus   Status
+		wantAttempts int
+	}{
+		{action: "retry", wantStatus: StatusCompleted, wantAttempts: 2},
+		{action: "kill", wantStatus: StatusPaused, wantAttempts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			var engine *Engine
+			runs := 0
+			runner := &fakeRunner{fn: func(ctx context.Context, req opencode.ExecRequest) *opencode.ExecResult {
+				// The first run leaves the artifact behind but is stopped as stalled
+				writePRD(t, engine.projectPath)
+				if runs++; runs == 1 {
+					return &opencode.ExecResult{ExitCode: 0, Stalled: true, StallAction: req.StallAction}
+				}
+				return &opencode.ExecResult{ExitCode: 0}
+			}}
+			engine, _ = newTestEngine(t, runner)
+			engine.Settings = func() *state.Settings {
+				s := state.DefaultSettings()
+				s.MaxRetries = 2
+				s.RetryDelay = 0
+				s.StallAction = tt.action
+				return s
+			}
+
+			j, _ := engine.Start([]string{"prd"}, "")
+			engine.Wait()
+
+			got, _ := engine.Get(j.ID)
+			attempts := got.Steps[0].Attempts
+			if got.Status != tt.wantStatus || len(attempts) != tt.wantAttempts {
+				t.Fatalf("Status = %s, attempts = %d; want %s after %d", got.Status, len(attempts), tt.wantStatus, tt.wantAttempts)
+			}
+			a := attempts[0]
+			if a.Outcome != AttemptFailed || a.Error != "step stalled" || a.Failure == nil || a.Failure.Category != FailureTimeout {
+				t.Errorf("stalled attempt = %+v", a)
+			}
+			if string(a.StallAction) != tt.action {
+				t.Errorf("StallAction = %q, want %q", a.StallAction, tt.action)
+			}
+		})
+	}
+}
+
+func TestEngine_WorkflowOverrides(t *testing.T) {
+	runner := &fakeRunner{fn: func(ctx context.Context, req opencode.ExecRequest) *opencode.ExecResult {
+		return &opencode.ExecResult{ExitCode: 0} // Never writes the artifact
+	}}
+	engine, _ := newTestEngine(t, runner)
+	timeout, retries, delay := 3600000, 1, 0
+	engine.Settings = func() *state.Settings {
+		s := state.DefaultSettings()
+		s.MaxRetries = 5
+		s.HeartbeatInterval = 2000
+		s.StallAction = "kill"
+		s.InputMode = "pipe"
+		s.WorkflowOverrides["prd"] = state.WorkflowOverride{
+			StepTimeout: &timeout,
+			MaxRetries:  &retries,
+			RetryDelay:  &delay,
+			Profile:     "strong",
+			ExtraArgs:   []string{"--model", "big"},
+		}
+		return s
+	}
+
+	j, _ := engine.Start([]string{"prd"}, "fast")
+	engine.Wait()
+
+	calls := runner.calls()
+	if len(calls) != 2 {
+		t.Fatalf("attempts = %d, want 2 (override maxRetries 1)", len(calls))
+	}
+	req := calls[0]
+	if req.Profile != "strong" || req.Timeout != time.Hour {
+		t.Errorf("profile = %q, timeout = %v", req.Profile, req.Timeout)
+	}
+	if req.HeartbeatInterval != 2*time.Second || req.StallAction != opencode.StallActionKill || req.InputMode != opencode.InputPipe {
+		t.Errorf("heartbeat = %v, stall action = %q, input mode = %q", req.HeartbeatInterval, req.StallAction, req.InputMode)
+	}
+	wantFile := filepath.Join(engine.Store().StepDir(j.ID, 0), ContextFileName)
+	if want := []string{"--model", "big", "--file", wantFile, "--format", "json", contextPrompt}; !reflect.DeepEqual(req.Args, want) {
+		t.Errorf("Args = %v, want %v", req.Args, want)
+	}
+
+	// The override's timeout is at the maximum; only a plain retry is left
+	r := &FailureReport{Workflow: "prd", Category: FailureTimeout}
+	overrideTimeoutAction(j, r, engine.Settings())
+	if r.Actions[0].ID != "retry" {
+		t.Errorf("actions at the maximum timeout = %+v", r.Actions)
+	}
+
+	// A timeout suggestion targets the override, capped at the maximum
+	settings := engine.Settings()
+	shorter := 2400000
+	o := settings.WorkflowOverrides["prd"]
+	o.StepTimeout = &shorter
+	settings.WorkflowOverrides["prd"] = o
+	overrideTimeoutAction(j, r, settings)
+	overrides := r.Actions[0].Params["workflowOverrides"].(map[string]state.WorkflowOverride)
+	if r.Actions[0].ID != "increase-timeout" || *overrides["prd"].StepTimeout != state.MaxStepTimeout {
+		t.Errorf("suggested override = %+v", overrides["prd"])
+	}
+}
+
+func TestEngine_ResumeWithFeedback(t *testing.T) {
+	var engine *Engine
+	var contexts []string
+	runner := &fakeRunner{fn: func(ctx context.Context, req opencode.ExecRequest) *opencode.ExecResult {
+		data, _ := os.ReadFile(req.Args[1])
+		contexts = append(contexts, string(data))
+		if len(contexts) == 2 {
+			writePRD(t, engine.projectPath)
+		}
+		return &opencode.ExecResult{ExitCode: 0}
+	}}
+	engine, _ = newTestEngine(t, runner)
+	withRetries(engine, 0)
+
+	j, _ := engine.Start([]string{"prd"}, "")
+	engine.Wait()
+	if got, _ := engine.Get(j.ID); got.Status != StatusPaused {
+		t.Fatalf("Status = %s, want paused", got.Status)
+	}
+
+	if _, err := engine.Resume(j.ID, "Write the PRD to planning-artifacts"); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	engine.Wait()
+
+	got, _ := engine.Get(j.ID)
+	if got.Status != StatusCompleted {
+		t.Fatalf("Status = %s, want completed", got.Status)
+	}
+	if a := got.Steps[0].Attempts[1]; a.Number != 2 || len(a.Feedback) != 1 {
+		t.Errorf("second attempt = %+v, want number 2 with feedback", a)
+	}
+	if !strings.Contains(contexts[1], "## User Feedback") || !strings.Contains(contexts[1], "Write the PRD to planning-artifacts") {
+		t.Errorf("resumed context lacks feedback:\n%s", contexts[1])
+	}
+
+	if _, err := engine.Resume(j.ID, ""); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("Resume of completed journey err = %v, want ErrInvalidState", err)
+	}
+}
+
+func TestEngine_Abort(t *testing.T) {
+	started := make(chan struct{})
+	runner := &fakeRunner{fn: func(ctx context.Context, req opencode.ExecRequest) *opencode.ExecResult {
+		close(started)
+		<-ctx.Done()
+		return &opencode.ExecResult{ExitCode: -1, Cancelled: true}
+	}}
+	engine, _ := newTestEngine(t, runner)
+
+	j, _ := engine.Start([]string{"prd"}, "")
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("step did not start")
+	}
+
+	if _, err := engine.Abort(j.ID); err != nil {
+		t.Fatalf("Abort failed: %v", err)
+	}
+	engine.Wait()
+
+	got, _ := engine.Get(j.ID)
+	if got.Status != StatusAborted {
+		t.Errorf("Status = %s, want aborted", got.Status)
+	}
+
+	if _, err := engine.Abort(j.ID); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("second Abort err = %v, want ErrInvalidState", err)
+	}
+}
+
+func TestEngine_GetUnknown(t *testing.T) {
+	engine, _ := newTestEngine(t, &fakeRunner{})
+	if _, err := engine.Get("j-unknown"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestEngine_PreviewContext(t *testing.T) {
+	engine, _ := newTestEngine(t, &fakeRunner{})
+	engine.ProjectContext = func() string { return "Project notes" }
+
+	sc, content, err := engine.PreviewContext("prd", "", 0)
+	if err != nil {
+		t.Fatalf("PreviewContext failed: %v", err)
+	}
+	if sc.Workflow.Name != "prd" || content == "" {
+		t.Errorf("unexpected preview: %+v", sc)
+	}
+	if sc.ProjectContext != "Project notes" {
+		t.Errorf("ProjectContext = %q", sc.ProjectContext)
+	}
+}
+
+func TestEngine_YellowFlagPausesAndAnswerResumes(t *testing.T) {
+	var engine *Engine
+	var contexts []string
+	runner := &fakeRunner{fn: func(ctx context.Context, req opencode.ExecRequest) *opencode.ExecResult {
+		data, _ := os.ReadFile(req.Args[1])
+		contexts = append(contexts, string(data))
+		if len(contexts) == 1 {
+			req.OnOutput(opencode.StreamStdout, []byte("Should I include a competitor analysis?\n"))
+			<-ctx.Done()
+			return &opencode.ExecResult{ExitCode: -1, Cancelled: true}
+		}
+		writePRD(t, engine.projectPath)
+		return &opencode.ExecResult{ExitCode: 0}
+	}}
+	engine, events := newTestEngine(t, runner)
+
+	j, _ := engine.Start([]string{"prd"}, "")
+	engine.Wait()
+
+	got, _ := engine.Get(j.ID)
+	if got.Status != StatusPaused || got.YellowFlag == nil {
+		t.Fatalf("Status = %s, flag = %v, want paused with flag", got.Status, got.YellowFlag)
+	}
+	if got.YellowFlag.Delivery != DeliverContext || got.Steps[0].Attempts[0].Outcome != AttemptInterrupted {
+		t.Errorf("flag = %+v, attempt = %+v", got.YellowFlag, got.Steps[0].Attempts[0])
+	}
+	if !events.has("journey.yellowFlag") || events.has("step.retrying") {
+		t.Errorf("events = %v", events.events)
+	}
+
+	if _, err := engine.SubmitFeedback(j.ID, "Yes, three competitors"); err != nil {
+		t.Fatalf("SubmitFeedback failed: %v", err)
+	}
+	engine.Wait()
+
+	got, _ = engine.Get(j.ID)
+	if got.Status != StatusCompleted || got.YellowFlag != nil {
+		t.Fatalf("Status = %s, flag = %v, want completed", got.Status, got.YellowFlag)
+	}
+	if flags := got.Steps[0].YellowFlags; len(flags) != 1 || flags[0].Answer != "Yes, three competitors" {
+		t.Errorf("answered flags = %+v", flags)
+	}
+	if !strings.Contains(contexts[1], "Yes, three competitors") {
+		t.Errorf("answer missing from next context:\n%s", contexts[1])
+	}
+}
+
+// stdinRunner is a fakeRunner that also accepts input for the running step.
+type stdinRunner struct {
+	*fakeRunner
+	input chan string
+}
+
+func (r *stdinRunner) AcceptsInput(journeyID string, stepIndex int) bool {
+	return true
+}
+
+func (r *stdinRunner) SendInput(journeyID string, stepIndex int, data []byte) error {
+	r.input <- string(data)
+	return nil
+}
+
+func TestEngine_YellowFlagAnswerViaStdin(t *testing.T) {
+	var engine *Engine
+	runner := &stdinRunner{input: make(chan string, 1)}
+	runner.fakeRunner = &fakeRunner{fn: func(ctx context.Context, req opencode.ExecRequest) *opencode.ExecResult {
+		req.OnOutput(opencode.StreamStdout, []byte("1. Keep\n2. Rewrite\nChoose one:"))
+		if answer := <-runner.input; answer != "2\n" {
+			t.Errorf("stdin = %q, want \"2\\n\"", answer)
+		}
+		writePRD(t, engine.projectPath)
+		return &opencode.ExecResult{ExitCode: 0}
+	}}
+	engine, _ = newTestEngine(t, runner)
+
+	j, _ := engine.Start([]string{"prd"}, "")
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		got, _ := engine.Get(j.ID)
+		if got.YellowFlag != nil {
+			if got.YellowFlag.Kind != YellowFlagChoice || got.YellowFlag.Delivery != DeliverStdin {
+				t.Errorf("flag = %+v", got.YellowFlag)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("yellow flag not raised")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if _, err := engine.SubmitFeedback(j.ID, "2"); err != nil {
+		t.Fatalf("SubmitFeedback failed: %v", err)
+	}
+	engine.Wait()
+
+	got, _ := engine.Get(j.ID)
+	if got.Status != StatusCompleted || len(got.Steps[0].Attempts) != 1 {
+		t.Errorf("Status = %s with %d attempts, want completed in 1", got.Status, len(got.Steps[0].Attempts))
+	}
+}
+
+func TestEngine_YellowFlagAnswerViaStdinResetsDetector(t *testing.T) {
+	var engine *Engine
+	runner := &stdinRunner{input: make(chan string, 1)}
+	runner.fakeRunner = &fakeRunner{fn: func(ctx context.Context, req opencode.ExecRequest) *opencode.ExecResult {
+		req.OnOutput(opencode.StreamStdout, []byte("1. Keep\n2. Rewrite\nChoose one:"))
+		<-runner.input
+		req.OnOutput(opencode.StreamStdout, []byte("Overwrite the existing PRD?\n"))
+		<-runner.input
+		writePRD(t, engine.projectPath)
+		return &opencode.ExecResult{ExitCode: 0}
+	}}
+	engine, _ = newTestEngine(t, runner)
+
+	j, _ := engine.Start([]string{"prd"}, "")
+
+	// waitForFlag waits for an open flag asking question
+	waitForFlag := func(question string) *YellowFlag {
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			got, _ := engine.Get(j.ID)
+			if got.YellowFlag != nil && got.YellowFlag.Question == question {
+				return got.YellowFlag
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("yellow flag %q not raised", question)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	waitForFlag("Choose one:")
+	if _, err := engine.SubmitFeedback(j.ID, "2"); err != nil {
+		t.Fatalf("SubmitFeedback failed: %v", err)
+	}
+	flag := waitForFlag("Overwrite the existing PRD?")
+	if strings.Contains(flag.Excerpt, "Keep") {
+		t.Errorf("Excerpt = %q, still contains the answered menu", flag.Excerpt)
+	}
+	if _, err := engine.SubmitFeedback(j.ID, "y"); err != nil {
+		t.Fatalf("SubmitFeedback failed: %v", err)
+	}
+	engine.Wait()
+
+	got, _ := engine.Get(j.ID)
+	if got.Status != StatusCompleted || len(got.Steps[0].YellowFlags) != 2 {
+		t.Errorf("Status = %s with %d answered flags, want completed with 2", got.Status, len(got.Steps[0].YellowFlags))
+	}
+}
+
+func TestEngine_ReplanPausedJourney(t *testing.T) {
+	var engine *Engine
+	runner := &fakeRunner{fn: func(ctx context.Context, req opencode.ExecRequest) *opencode.ExecResult {
+		if strings.Contains(req.Args[1], "steps/1/") {
+			writePRD(t, engine.projectPath)
+		}
+		return &opencode.ExecResult{ExitCode: 0}
+	}}
+	engine, events := newTestEngine(t, runner)
+	withRetries(engine, 0)
+
+	// create-architecture fails: its artifact is never written
+	j, _ := engine.Start([]string{"create-architecture"}, "")
+	engine.Wait()
+
+	got, rev, err := engine.Replan(j.ID, ReplanRequest{Feedback: "Write the PRD instead", Route: []string{"prd"}})
+	if err != nil {
+		t.Fatalf("Replan failed: %v", err)
+	}
+	if rev.Version != 2 || got.Steps[0].Status != StepSkipped || got.Destination != "prd" {
+		t.Errorf("journey = %+v, revision = %+v", got, rev)
+	}
+	if !events.has("journey.replanned") {
+		t.Error("missing journey.replanned event")
+	}
+
+	if _, err := engine.Resume(j.ID, ""); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	engine.Wait()
+
+	got, _ = engine.Get(j.ID)
+	if got.Status != StatusCompleted || got.Steps[1].Status != StepCompleted {
+		t.Errorf("Status = %s, steps = %s/%s", got.Status, got.Steps[0].Status, got.Steps[1].Status)
+	}
+}
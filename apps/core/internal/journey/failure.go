@@ -0,0 +1,403 @@
+package journey
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/network"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/state"
+)
+
+// FailureCategory is the root-cause category of a failed attempt (FR30).
+type FailureCategory string
+
+const (
+	FailureNetwork    FailureCategory = "network"
+	FailureAuth       FailureCategory = "auth" // Authentication or provider quota
+	FailureCrash      FailureCategory = "crash"
+	FailureTimeout    FailureCategory = "timeout"
+	FailureValidation FailureCategory = "validation"
+	FailureCancelled  FailureCategory = "cancelled"
+	FailureUnknown    FailureCategory = "unknown"
+)
+
+// Patterns matched against the stderr and stdout tails of a failed process.
+var (
+	authPattern = regexp.MustCompile(`(?i)\b(401|403|429)\b|unauthori[sz]ed|invalid (api )?key|api key|authentication|forbidden|rate.?limit|quota|insufficient (credits|balance)|billing`)
+
+	networkPattern = regexp.MustCompile(`(?i)ECONNREFUSED|ECONNRESET|ENOTFOUND|ETIMEDOUT|EAI_AGAIN|getaddrinfo|network is unreachable|no such host|connection (refused|reset)|fetch failed|socket hang up|tls handshake`)
+
+	crashPattern = regexp.MustCompile(`(?m)^(panic:|fatal error:|Segmentation fault|Traceback \(most recent call last\))|out of memory|core dumped`)
+)
+
+// FailureSignals are the facts known about an attempt when it ends.
+type FailureSignals struct {
+	Outcome  AttemptOutcome
+	ExitCode int
+	Stdout   string
+	Stderr   string
+	TimedOut bool
+	Stalled  bool
+	RunError string // Error starting or supervising the process
+	Network  network.Status
+	Verdict  *CompletionVerdict
+}
+
+// Classification explains why an attempt failed.
+type Classification struct {
+	Category    FailureCategory `json:"category"`
+	Explanation string          `json:"explanation"`
+	Evidence    []string        `json:"evidence,omitempty"`
+}
+
+// ClassifyFailure picks the most specific root cause for a failed attempt.
+// Signals are checked from the most to the least conclusive: user cancel,
+// provider errors, connectivity, time limits, process crashes and finally
+// the completion verdict.
+func ClassifyFailure(sig FailureSignals) Classification {
+	output := sig.Stderr + "\n" + sig.Stdout
+
+	if sig.Outcome == AttemptCancelled {
+		return Classification{
+			Category:    FailureCancelled,
+			Explanation: "The step was cancelled before it finished.",
+		}
+	}
+
+	if m := authPattern.FindString(output); m != "" && sig.ExitCode != 0 {
+		return Classification{
+			Category:    FailureAuth,
+			Explanation: "The AI provider rejected the request. Check the credentials and quota of the selected profile.",
+			Evidence:    []string{evidenceLine(output, m)},
+		}
+	}
+
+	if sig.Network == network.StatusOffline {
+		return Classification{
+			Category:    FailureNetwork,
+			Explanation: "The network was offline when the step failed.",
+			Evidence:    []string{"network status: offline"},
+		}
+	}
+	if m := networkPattern.FindString(output); m != "" && sig.ExitCode != 0 {
+		return Classification{
+			Category:    FailureNetwork,
+			Explanation: "OpenCode could not reach the AI provider.",
+			Evidence:    []string{evidenceLine(output, m)},
+		}
+	}
+
+	if sig.TimedOut || sig.Stalled {
+		explanation := "The step exceeded its timeout."
+		if sig.Stalled {
+			explanation = "The step stopped producing output and was stopped as stalled."
+		}
+		return Classification{Category: FailureTimeout, Explanation: explanation}
+	}
+
+	if sig.RunError != "" {
+		return Classification{
+			Category:    FailureCrash,
+			Explanation: "OpenCode could not be run.",
+			Evidence:    []string{sig.RunError},
+		}
+	}
+	if sig.ExitCode != 0 {
+		c := Classification{
+			Category:    FailureCrash,
+			Explanation: fmt.Sprintf("OpenCode exited with code %d.", sig.ExitCode),
+			Evidence:    []string{fmt.Sprintf("exit code %d", sig.ExitCode)},
+		}
+		if m := crashPattern.FindString(output); m != "" {
+			c.Evidence = append(c.Evidence, evidenceLine(output, m))
+		}
+		return c
+	}
+
+	if sig.Verdict != nil && !sig.Verdict.Success {
+		c := Classification{
+			Category:    FailureValidation,
+			Explanation: "OpenCode finished but the workflow's completion checks failed.",
+		}
+		for _, check := range sig.Verdict.FailedChecks() {
+			c.Evidence = append(c.Evidence, fmt.Sprintf("%s: %s", check.Name, check.Evidence))
+		}
+		return c
+	}
+
+	return Classification{Category: FailureUnknown, Explanation: "The cause of the failure could not be determined."}
+}
+
+// evidenceLine returns the trimmed line of output containing match.
+func evidenceLine(output, match string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if strings.Contains(line, match) {
+			return truncate(strings.TrimSpace(line), 200)
+		}
+	}
+	return match
+}
+
+// TimelineEntry is one event of a journey.
+type TimelineEntry struct {
+	Time      time.Time `json:"time"`
+	Event     string    `json:"event"`
+	StepIndex *int      `json:"stepIndex,omitempty"`
+	Detail    string    `json:"detail,omitempty"`
+}
+
+// RecoveryAction is a suggested action the client can execute by calling Method with Params.
+type RecoveryAction struct {
+	ID          string                 `json:"id"`
+	Label       string                 `json:"label"`
+	Description string                 `json:"description,omitempty"`
+	Method      string                 `json:"method"`
+	Params      map[string]interface{} `json:"params,omitempty"`
+	Recommended bool                   `json:"recommended,omitempty"`
+}
+
+// FailureReport explains the latest failure of a journey (FR29-FR35).
+type FailureReport struct {
+	JourneyID    string          `json:"journeyId"`
+	Status       Status          `json:"status"`
+	StepIndex    int             `json:"stepIndex"`
+	Workflow     string          `json:"workflow"`
+	Attempt      int             `json:"attempt"`
+	Error        string          `json:"error,omitempty"`
+	Category     FailureCategory `json:"category"`
+	Explanation  string          `json:"explanation"`
+	Evidence     []string        `json:"evidence,omitempty"`
+	FailedChecks []CheckResult   `json:"failedChecks,omitempty"`
+	Network      network.Status  `json:"network,omitempty"`
+	LogFile      string          `json:"logFile,omitempty"`
+
+	Timeline     []TimelineEntry `json:"timeline"`
+	Checkpointed []string        `json:"checkpointed"` // Artifacts of completed steps still on disk
+	Lost         []string        `json:"lost"`         // Work that has to be redone
+
+	Actions     []RecoveryAction `json:"actions"`
+	GeneratedAt time.Time        `json:"generatedAt"`
+}
+
+// BuildFailureReport builds the report for the journey's most recent failed
+// or cancelled attempt. The journey must not be shared with other goroutines.
+// stepTimeoutMs is the current default step timeout, used to suggest a longer one.
+func BuildFailureReport(j *Journey, stepTimeoutMs int) (*FailureReport, error) {
+	step, attempt := lastFailure(j)
+	if attempt == nil {
+		return nil, fmt.Errorf("%w: journey %s has no failed attempt", ErrInvalidState, j.ID)
+	}
+
+	r := &FailureReport{
+		JourneyID:   j.ID,
+		Status:      j.Status,
+		StepIndex:   step.Index,
+		Workflow:    step.Workflow,
+		Attempt:     attempt.Number,
+		Error:       attempt.Error,
+		Category:    FailureUnknown,
+		Network:     attempt.Network,
+		LogFile:     attempt.LogFile,
+		Timeline:    buildTimeline(j, step.Index),
+		GeneratedAt: time.Now(),
+	}
+	if c := attempt.Failure; c != nil {
+		r.Category = c.Category
+		r.Explanation = c.Explanation
+		r.Evidence = c.Evidence
+	}
+	if attempt.Verdict != nil {
+		r.FailedChecks = attempt.Verdict.FailedChecks()
+	}
+
+	r.Checkpointed, r.Lost = recoveryState(j, step, attempt)
+	r.Actions = suggestActions(j, r.Category, stepTimeoutMs)
+	return r, nil
+}
+
+// lastFailure returns the most recent failed or cancelled attempt and its step.
+func lastFailure(j *Journey) (*Step, *Attempt) {
+	for i := len(j.Steps) - 1; i >= 0; i-- {
+		s := j.Steps[i]
+		for k := len(s.Attempts) - 1; k >= 0; k-- {
+			if o := s.Attempts[k].Outcome; o == AttemptFailed || o == AttemptCancelled {
+				return s, s.Attempts[k]
+			}
+		}
+	}
+	return nil, nil
+}
+
+// buildTimeline reconstructs journey events up to and including the failed step.
+func buildTimeline(j *Journey, failedIndex int) []TimelineEntry {
+	var entries []TimelineEntry
+	add := func(t *time.Time, event string, index *int, detail string) {
+		if t != nil && !t.IsZero() {
+			entries = append(entries, TimelineEntry{Time: *t, Event: event, StepIndex: index, Detail: detail})
+		}
+	}
+
+	add(&j.CreatedAt, "journey.created", nil, j.Destination)
+	add(j.StartedAt, "journey.started", nil, "")
+	for _, s := range j.Steps {
+		if s.Index > failedIndex {
+			break
+		}
+		index := s.Index
+		add(s.StartedAt, "step.started", &index, s.Workflow)
+		for _, a := range s.Attempts {
+			add(timePtr(a.StartedAt), "attempt.started", &index, fmt.Sprintf("attempt %d", a.Number))
+			detail := fmt.Sprintf("attempt %d %s", a.Number, a.Outcome)
+			if a.Error != "" {
+				detail += ": " + a.Error
+			}
+			add(timePtr(a.FinishedAt), "attempt.finished", &index, detail)
+		}
+		if s.Status == StepCompleted {
+			add(s.CompletedAt, "step.completed", &index, strings.Join(s.Artifacts, ", "))
+		}
+	}
+	if j.Status == StatusPaused && j.PauseReason != "" && j.NetworkHold == nil {
+		// The pause time is not stored; it immediately follows the last attempt
+		last := entries[len(entries)-1].Time
+		entries = append(entries, TimelineEntry{Time: last, Event: "journey.paused", Detail: j.PauseReason})
+	}
+	// Network events are stored as they happen
+	for _, entry := range j.Timeline {
+		if entry.StepIndex == nil || *entry.StepIndex <= failedIndex {
+			entries = append(entries, entry)
+		}
+	}
+	add(j.CompletedAt, "journey."+string(j.Status), nil, j.Error)
+
+	sort.SliceStable(entries, func(a, b int) bool { return entries[a].Time.Before(entries[b].Time) })
+	return entries
+}
+
+// recoveryState lists what survives the failure and what has to be redone.
+// Completed steps are persisted in state.json, so their artifacts are kept
+// as long as they are still on disk.
+func recoveryState(j *Journey, failed *Step, attempt *Attempt) (checkpointed, lost []string) {
+	checkpointed, lost = []string{}, []string{}
+	output := filepath.Join(j.ProjectPath, "_bmad-output")
+
+	for _, s := range j.Steps {
+		if s.Status != StepCompleted {
+			continue
+		}
+		for _, a := range s.Artifacts {
+			if _, err := os.Stat(filepath.Join(output, filepath.FromSlash(a))); err == nil {
+				checkpointed = append(checkpointed, a)
+			} else {
+				lost = append(lost, fmt.Sprintf("%s (step %d artifact no longer on disk)", a, s.Index))
+			}
+		}
+	}
+
+	if attempt.Verdict != nil {
+		for _, a := range attempt.Verdict.Artifacts {
+			lost = append(lost, fmt.Sprintf("%s (incomplete output of step %d)", a, failed.Index))
+		}
+	}
+	lost = append(lost, fmt.Sprintf("OpenCode session of step %d attempt %d", failed.Index, attempt.Number))
+	return checkpointed, lost
+}
+
+// longerTimeout doubles a step timeout up to state.MaxStepTimeout, so the
+// suggested value passes validation. It reports false if the timeout is
+// already at the maximum.
+func longerTimeout(ms int) (int, bool) {
+	if ms >= state.MaxStepTimeout {
+		return ms, false
+	}
+	return min(ms*2, state.MaxStepTimeout), true
+}
+
+// suggestActions returns the recovery options for a failure category,
+// recommended option first.
+func suggestActions(j *Journey, category FailureCategory, stepTimeoutMs int) []RecoveryAction {
+	id := map[string]interface{}{"journeyId": j.ID}
+	retry := RecoveryAction{
+		ID:          "retry",
+		Label:       "Retry step",
+		Description: "Run the failed step again.",
+		Method:      "journey.resume",
+		Params:      id,
+	}
+	feedback := RecoveryAction{
+		ID:          "retry-with-feedback",
+		Label:       "Retry with feedback",
+		Description: "Tell the agent what to do differently and run the step again.",
+		Method:      "journey.resume",
+		Params:      map[string]interface{}{"journeyId": j.ID, "feedback": ""},
+	}
+	abort := RecoveryAction{
+		ID:          "abort",
+		Label:       "Abort journey",
+		Description: "Stop the journey. Completed steps and their artifacts are kept.",
+		Method:      "journey.abort",
+		Params:      id,
+	}
+
+	var actions []RecoveryAction
+	switch category {
+	case FailureNetwork:
+		actions = append(actions, RecoveryAction{
+			ID:          "check-network",
+			Label:       "Check connection",
+			Description: "Check whether the network is back online.",
+			Method:      "network.getStatus",
+		}, retry)
+	case FailureAuth:
+		actions = append(actions, RecoveryAction{
+			ID:          "check-profiles",
+			Label:       "Review profiles",
+			Description: "Check the provider credentials or switch to another profile.",
+			Method:      "opencode.getProfiles",
+		}, retry)
+	case FailureTimeout:
+		if longer, ok := longerTimeout(stepTimeoutMs); ok {
+			actions = append(actions, RecoveryAction{
+				ID:          "increase-timeout",
+				Label:       "Increase timeout",
+				Description: fmt.Sprintf("Raise the default step timeout to %s, then retry.", formatDuration(int64(longer))),
+				Method:      "settings.set",
+				Params:      map[string]interface{}{"stepTimeoutDefault": longer},
+			})
+		}
+		actions = append(actions, retry)
+	case FailureCrash:
+		actions = append(actions, RecoveryAction{
+			ID:          "check-opencode",
+			Label:       "Check OpenCode",
+			Description: "Verify the OpenCode installation.",
+			Method:      "opencode.detect",
+		}, retry)
+	case FailureValidation:
+		actions = append(actions, feedback, retry)
+	default:
+		actions = append(actions, retry, feedback)
+	}
+
+	if j.Status.IsFinished() {
+		// Finished journeys cannot be resumed; only diagnostics remain useful
+		kept := []RecoveryAction{}
+		for _, a := range actions {
+			if a.Method != "journey.resume" {
+				kept = append(kept, a)
+			}
+		}
+		actions = kept
+	} else {
+		actions = append(actions, abort)
+	}
+	if len(actions) > 0 {
+		actions[0].Recommended = true
+	}
+	return actions
+}
@@ -0,0 +1,173 @@
+package journey
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/network"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/state"
+)
+
+func TestClassifyFailure(t *testing.T) {
+	failedVerdict := &CompletionVerdict{Checks: []CheckResult{{Name: "artifact:prd.md", Evidence: "no match"}}}
+
+	tests := []struct {
+		name string
+		sig  FailureSignals
+		want FailureCategory
+	}{
+		{"cancelled", FailureSignals{Outcome: AttemptCancelled, ExitCode: -1}, FailureCancelled},
+		{"auth", FailureSignals{ExitCode: 1, Stderr: "Error: 401 Unauthorized: invalid api key"}, FailureAuth},
+		{"quota", FailureSignals{ExitCode: 1, Stderr: "rate limit exceeded"}, FailureAuth},
+		{"offline", FailureSignals{ExitCode: 1, Network: network.StatusOffline}, FailureNetwork},
+		{"network stderr", FailureSignals{ExitCode: 1, Stderr: "fetch failed: getaddrinfo ENOTFOUND api.example.com"}, FailureNetwork},
+		{"timeout", FailureSignals{ExitCode: -1, TimedOut: true}, FailureTimeout},
+		{"stalled", FailureSignals{ExitCode: -1, Stalled: true}, FailureTimeout},
+		{"missing binary", FailureSignals{RunError: "exec: not found"}, FailureCrash},
+		{"crash", FailureSignals{ExitCode: 2, Stderr: "panic: nil map"}, FailureCrash},
+		{"validation", FailureSignals{Verdict: failedVerdict}, FailureValidation},
+		{"auth text with clean exit", FailureSignals{Stdout: "Configure the api key", Verdict: failedVerdict}, FailureValidation},
+		{"unknown", FailureSignals{}, FailureUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.sig.Outcome == "" {
+				tt.sig.Outcome = AttemptFailed
+			}
+			got := ClassifyFailure(tt.sig)
+			if got.Category != tt.want {
+				t.Errorf("Category = %s, want %s (%+v)", got.Category, tt.want, got)
+			}
+			if got.Explanation == "" {
+				t.Error("Explanation is empty")
+			}
+		})
+	}
+}
+
+func TestBuildFailureReport(t *testing.T) {
+	project := t.TempDir()
+	briefPath := filepath.Join(project, "_bmad-output", "planning-artifacts", "product-brief.md")
+	if err := os.MkdirAll(filepath.Dir(briefPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(briefPath, []byte("# Brief\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Date(2026, 1, 21, 10, 0, 0, 0, time.UTC)
+	j := NewJourney(project, []string{"create-product-brief", "prd"}, "")
+	j.CreatedAt = base
+	j.StartedAt = timePtr(base.Add(time.Second))
+	j.Status = StatusPaused
+	j.PauseReason = "step 1 (prd) failed after 1 attempts"
+	j.CurrentStep = 1
+
+	brief := j.Steps[0]
+	brief.Status = StepCompleted
+	brief.StartedAt = timePtr(base.Add(2 * time.Second))
+	brief.CompletedAt = timePtr(base.Add(3 * time.Second))
+	brief.Artifacts = []string{"planning-artifacts/product-brief.md", "planning-artifacts/deleted.md"}
+
+	prd := j.Steps[1]
+	prd.Status = StepFailed
+	prd.StartedAt = timePtr(base.Add(4 * time.Second))
+	prd.Attempts = []*Attempt{{
+		Number:     1,
+		Outcome:    AttemptFailed,
+		StartedAt:  base.Add(4 * time.Second),
+		FinishedAt: base.Add(5 * time.Second),
+		Error:      "sections:prd.md: missing Success Criteria",
+		Verdict: &CompletionVerdict{
+			Artifacts: []string{"planning-artifacts/prd.md"},
+			Checks:    []CheckResult{{Name: "sections:prd.md", Evidence: "missing Success Criteria"}},
+		},
+		Failure: &Classification{Category: FailureValidation, Explanation: "checks failed"},
+	}}
+	index := 1
+	j.Timeline = []TimelineEntry{{Time: base.Add(4500 * time.Millisecond), Event: "network.degraded", StepIndex: &index}}
+
+	report, err := BuildFailureReport(j, 1000)
+	if err != nil {
+		t.Fatalf("BuildFailureReport failed: %v", err)
+	}
+
+	if report.StepIndex != 1 || report.Category != FailureValidation || len(report.FailedChecks) != 1 {
+		t.Errorf("report = %+v", report)
+	}
+	if len(report.Checkpointed) != 1 || report.Checkpointed[0] != "planning-artifacts/product-brief.md" {
+		t.Errorf("Checkpointed = %v", report.Checkpointed)
+	}
+	// Deleted artifact, incomplete PRD and the OpenCode session
+	if len(report.Lost) != 3 {
+		t.Errorf("Lost = %v", report.Lost)
+	}
+
+	var events []string
+	for i, e := range report.Timeline {
+		events = append(events, e.Event)
+		if i > 0 && e.Time.Before(report.Timeline[i-1].Time) {
+			t.Errorf("timeline not sorted at %d", i)
+		}
+	}
+	want := []string{"journey.created", "journey.started", "step.started", "step.completed", "step.started", "attempt.started", "network.degraded", "attempt.finished", "journey.paused"}
+	if len(events) != len(want) {
+		t.Fatalf("timeline = %v, want %v", events, want)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("timeline[%d] = %s, want %s", i, events[i], want[i])
+		}
+	}
+
+	if a := report.Actions[0]; a.ID != "retry-with-feedback" || !a.Recommended || a.Method != "journey.resume" {
+		t.Errorf("first action = %+v", a)
+	}
+	if last := report.Actions[len(report.Actions)-1]; last.Method != "journey.abort" {
+		t.Errorf("last action = %+v, want abort", last)
+	}
+}
+
+func TestBuildFailureReport_NoFailure(t *testing.T) {
+	j := NewJourney(t.TempDir(), []string{"prd"}, "")
+	if _, err := BuildFailureReport(j, 1000); err == nil {
+		t.Error("expected error for journey without failures")
+	}
+}
+
+func TestSuggestActions_FinishedJourney(t *testing.T) {
+	j := NewJourney(t.TempDir(), []string{"prd"}, "")
+	j.Status = StatusFailed
+
+	actions := suggestActions(j, FailureTimeout, 1000)
+	for _, a := range actions {
+		if a.Method == "journey.resume" || a.Method == "journey.abort" {
+			t.Errorf("finished journey offered %s", a.Method)
+		}
+	}
+	if actions[0].Params["stepTimeoutDefault"] != 2000 {
+		t.Errorf("increase-timeout params = %v", actions[0].Params)
+	}
+}
+
+func TestSuggestActions_TimeoutCappedAtMaximum(t *testing.T) {
+	j := NewJourney(t.TempDir(), []string{"prd"}, "")
+
+	actions := suggestActions(j, FailureTimeout, 2400000)
+	if actions[0].ID != "increase-timeout" || actions[0].Params["stepTimeoutDefault"] != state.MaxStepTimeout {
+		t.Errorf("increase-timeout = %+v, want the schema maximum", actions[0])
+	}
+
+	actions = suggestActions(j, FailureTimeout, state.MaxStepTimeout)
+	for _, a := range actions {
+		if a.ID == "increase-timeout" {
+			t.Error("increase-timeout offered at the maximum timeout")
+		}
+	}
+	if !actions[0].Recommended || actions[0].ID != "retry" {
+		t.Errorf("first action = %+v, want recommended retry", actions[0])
+	}
+}
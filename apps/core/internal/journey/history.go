@@ -0,0 +1,76 @@
+package journey
+
+import (
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/state"
+)
+
+// HistoryRecord summarizes a finished journey for the history index.
+// It returns nil while the journey is still in progress.
+func (j *Journey) HistoryRecord() *state.HistoryRecord {
+	if !j.Status.IsFinished() || j.CompletedAt == nil {
+		return nil
+	}
+
+	rec := &state.HistoryRecord{
+		ID:          j.ID,
+		Destination: j.Destination,
+		Route:       j.Route(),
+		Status:      string(j.Status),
+		Profile:     j.Profile,
+		CreatedAt:   j.CreatedAt,
+		StartedAt:   j.StartedAt,
+		CompletedAt: *j.CompletedAt,
+		Error:       j.Error,
+	}
+	if j.StartedAt != nil {
+		rec.DurationMs = j.CompletedAt.Sub(*j.StartedAt).Milliseconds()
+	}
+	for _, s := range j.Steps {
+		if s.Status == StepCompleted {
+			rec.StepsCompleted++
+		}
+		if len(s.Attempts) > 1 {
+			rec.Retries += len(s.Attempts) - 1
+		}
+		rec.Artifacts = append(rec.Artifacts, s.Artifacts...)
+	}
+	return rec
+}
+
+// summarize loads a journey's state file and builds its history record.
+func (e *Engine) summarize(id string) (*state.HistoryRecord, error) {
+	return summarizeFrom(e.store)(id)
+}
+
+// summarizeFrom returns a SummarizeFunc reading journeys from store.
+func summarizeFrom(store *state.Manager) state.SummarizeFunc {
+	return func(id string) (*state.HistoryRecord, error) {
+		j := &Journey{}
+		if err := store.LoadJourney(id, j); err != nil {
+			return nil, err
+		}
+		return j.HistoryRecord(), nil
+	}
+}
+
+// OpenHistory returns the history index of a project that has no engine,
+// such as a recent project that is not open.
+func OpenHistory(projectPath string) *state.History {
+	store := state.NewManager(projectPath)
+	return state.NewHistory(store, summarizeFrom(store))
+}
+
+// recordHistoryLocked adds a finished journey to the history index.
+// Caller must hold e.mu. Index errors are reported but do not affect the journey.
+func (e *Engine) recordHistoryLocked(j *Journey) {
+	rec := j.HistoryRecord()
+	if rec == nil {
+		return
+	}
+	if err := e.history.Record(*rec); err != nil {
+		e.emit("journey.error", map[string]interface{}{
+			"journeyId": j.ID,
+			"error":     err.Error(),
+		})
+	}
+}
@@ -3,7 +3,179 @@
 // tracking progress, handling yellow flags, and coordinating checkpoints.
 package journey
 
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// Status is the lifecycle state of a journey.
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusPaused    Status = "paused"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+	StatusAborted   Status = "aborted"
+)
+
+// IsFinished reports whether the journey has reached a terminal state.
+func (s Status) IsFinished() bool {
+	return s == StatusCompleted || s == StatusFailed || s == StatusAborted
+}
+
+// StepStatus is the lifecycle state of a single step.
+type StepStatus string
+
+const (
+	StepPending   StepStatus = "pending"
+	StepRunning   StepStatus = "running"
+	StepCompleted StepStatus = "completed"
+	StepFailed    StepStatus = "failed"
+	StepSkipped   StepStatus = "skipped"
+)
+
 // Journey represents an executing BMAD workflow journey.
+// It is persisted as .autobmad/journeys/<id>/state.json.
 type Journey struct {
-	// TODO: Story 2.1 will implement journey state management
+	ID          string      `json:"id"`
+	ProjectPath string      `json:"projectPath"`
+	Destination string      `json:"destination"` // Final workflow of the route
+	Profile     string      `json:"profile,omitempty"`
+	Status      Status      `json:"status"`
+	CurrentStep int         `json:"currentStepIndex"`
+	Steps       []*Step     `json:"steps"`
+	CreatedAt   time.Time   `json:"createdAt"`
+	StartedAt   *time.Time  `json:"startedAt,omitempty"`
+	CompletedAt *time.Time  `json:"completedAt,omitempty"`
+	PauseReason string      `json:"pauseReason,omitempty"`
+	YellowFlag  *YellowFlag `json:"yellowFlag,omitempty"` // Open question awaiting an answer
+	Error       string      `json:"error,omitempty"`
+
+	NetworkHold *NetworkHold    `json:"networkHold,omitempty"` // Paused until the network is back
+	Timeline    []TimelineEntry `json:"timeline,omitempty"`    // Network events; other entries are derived from the steps
+
+	Revisions []*RouteRevision `json:"revisions,omitempty"` // Route versions, oldest first
+}
+
+// Step is a single workflow execution within a journey.
+type Step struct {
+	Index       int                `json:"index"`
+	Workflow    string             `json:"workflow"`
+	Status      StepStatus         `json:"status"`
+	ContextFile string             `json:"contextFile,omitempty"`
+	StartedAt   *time.Time         `json:"startedAt,omitempty"`
+	CompletedAt *time.Time         `json:"completedAt,omitempty"`
+	ExitCode    int                `json:"exitCode"`
+	Verdict     *CompletionVerdict `json:"verdict,omitempty"`
+	Artifacts   []string           `json:"artifacts,omitempty"`  // Relative to _bmad-output/
+	Baseline    []string           `json:"baseline,omitempty"`   // Expected artifacts that existed before the first attempt
+	Checkpoint  string             `json:"checkpoint,omitempty"` // Git checkpoint commit SHA (Story 3.6)
+	Attempts    []*Attempt         `json:"attempts,omitempty"`
+	Feedback    []string           `json:"feedback,omitempty"` // Pending feedback for the next attempt
+	YellowFlags []*YellowFlag      `json:"yellowFlags,omitempty"`
+	Error       string             `json:"error,omitempty"`
+}
+
+// attemptSummaries returns the evidence of every finished, unsuccessful attempt.
+func (s *Step) attemptSummaries() []AttemptSummary {
+	var summaries []AttemptSummary
+	for _, a := range s.Attempts {
+		if a.Outcome == AttemptSucceeded || a.Outcome == AttemptRunning {
+			continue
+		}
+		summary := AttemptSummary{
+			Number:   a.Number,
+			Outcome:  a.Outcome,
+			Error:    a.Error,
+			Feedback: a.Feedback,
+		}
+		if a.Verdict != nil {
+			summary.FailedChecks = a.Verdict.FailedChecks()
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+// NewJourney creates a pending journey that runs the given route of workflows.
+// The destination is the last workflow of the route.
+func NewJourney(projectPath string, route []string, profile string) *Journey {
+	j := &Journey{
+		ID:          NewJourneyID(time.Now()),
+		ProjectPath: projectPath,
+		Profile:     profile,
+		Status:      StatusPending,
+		CreatedAt:   time.Now(),
+	}
+	for i, workflow := range route {
+		j.Steps = append(j.Steps, &Step{Index: i, Workflow: workflow, Status: StepPending})
+	}
+	if len(route) > 0 {
+		j.Destination = route[len(route)-1]
+	}
+	j.Revisions = []*RouteRevision{{Version: 1, CreatedAt: j.CreatedAt, Route: j.Route()}}
+	return j
+}
+
+// NewJourneyID returns an ID like j-20260121-100000-a1b2.
+// The random suffix prevents collisions between journeys started in the same second.
+func NewJourneyID(now time.Time) string {
+	suffix := make([]byte, 2)
+	_, _ = rand.Read(suffix)
+	return "j-" + now.Format("20060102-150405") + "-" + hex.EncodeToString(suffix)
+}
+
+// Clone returns a deep copy of the journey safe to hand to other goroutines.
+func (j *Journey) Clone() *Journey {
+	c := *j
+	c.Steps = make([]*Step, len(j.Steps))
+	for i, s := range j.Steps {
+		step := *s
+		step.Artifacts = append([]string(nil), s.Artifacts...)
+		step.Baseline = append([]string(nil), s.Baseline...)
+		step.Feedback = append([]string(nil), s.Feedback...)
+		step.Attempts = make([]*Attempt, len(s.Attempts))
+		for k, a := range s.Attempts {
+			attempt := *a
+			step.Attempts[k] = &attempt
+		}
+		step.YellowFlags = make([]*YellowFlag, len(s.YellowFlags))
+		for k, f := range s.YellowFlags {
+			flag := *f
+			step.YellowFlags[k] = &flag
+		}
+		c.Steps[i] = &step
+	}
+	if j.YellowFlag != nil {
+		flag := *j.YellowFlag
+		c.YellowFlag = &flag
+	}
+	if j.NetworkHold != nil {
+		hold := *j.NetworkHold
+		c.NetworkHold = &hold
+	}
+	c.Timeline = append([]TimelineEntry(nil), j.Timeline...)
+	// Revisions are never modified after they are recorded
+	c.Revisions = append([]*RouteRevision(nil), j.Revisions...)
+	return &c
+}
+
+// PriorArtifacts returns artifacts produced by steps before index, in step order.
+func (j *Journey) PriorArtifacts(index int) []string {
+	var artifacts []string
+	for _, s := range j.Steps {
+		if s.Index >= index {
+			break
+		}
+		artifacts = append(artifacts, s.Artifacts...)
+	}
+	return artifacts
+}
+
+// timePtr returns a pointer to t.
+func timePtr(t time.Time) *time.Time {
+	return &t
 }
@@ -0,0 +1,254 @@
+package journey
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/network"
+)
+
+// NetworkHold records that a journey was paused because its step needs the
+// network while the network is offline (FR52).
+type NetworkHold struct {
+	Since     time.Time      `json:"since"`
+	Status    network.Status `json:"status"`
+	StepIndex int            `json:"stepIndex"`
+}
+
+// NetworkChanged reacts to a connectivity change. Going offline pauses the
+// running journeys whose current step needs the network; once the network
+// has been online for the networkResumeDelay setting they resume on their
+// own. A degraded network only warns. Every change is recorded in the
+// timeline of the affected journeys.
+func (e *Engine) NetworkChanged(status network.Status) {
+	e.mu.Lock()
+	e.lastNetwork = status
+	e.mu.Unlock()
+
+	switch status {
+	case network.StatusOffline:
+		e.stopResumeTimer()
+		e.holdForNetwork(status)
+	case network.StatusDegraded:
+		// Not stable yet; held journeys keep waiting
+		e.stopResumeTimer()
+		e.warnNetwork(status)
+	case network.StatusOnline:
+		e.scheduleNetworkResume()
+	}
+}
+
+// needsNetwork reports whether a workflow's steps need the network, which
+// is true unless its override marks it offline.
+func (e *Engine) needsNetwork(workflow string) bool {
+	return !e.settings().ForWorkflow(workflow).Offline
+}
+
+// holdForNetwork pauses running journeys whose current step needs the
+// network and stops their attempts.
+func (e *Engine) holdForNetwork(status network.Status) {
+	e.mu.Lock()
+	var stops []context.CancelFunc
+	var events []map[string]interface{}
+	for id, j := range e.journeys {
+		if _, running := e.cancels[id]; !running || j.Status != StatusRunning || j.CurrentStep >= len(j.Steps) {
+			continue
+		}
+		if !e.needsNetwork(j.Steps[j.CurrentStep].Workflow) {
+			continue
+		}
+		events = append(events, e.holdLocked(j, status))
+		if stop := e.stops[id]; stop != nil {
+			stops = append(stops, stop)
+		}
+	}
+	e.mu.Unlock()
+
+	for _, data := range events {
+		e.emit("journey.networkPaused", data)
+	}
+	for _, stop := range stops {
+		stop()
+	}
+}
+
+// holdLocked pauses a journey for the network and returns the event data.
+// Caller must hold e.mu.
+func (e *Engine) holdLocked(j *Journey, status network.Status) map[string]interface{} {
+	index := j.CurrentStep
+	j.NetworkHold = &NetworkHold{Since: time.Now(), Status: status, StepIndex: index}
+	j.Status = StatusPaused
+	j.PauseReason = fmt.Sprintf("waiting for network: %s", status)
+	j.record("journey.networkPaused", &index, j.PauseReason)
+	e.saveLocked(j)
+	return map[string]interface{}{
+		"journeyId": j.ID,
+		"stepIndex": index,
+		"network":   status,
+		"reason":    j.PauseReason,
+	}
+}
+
+// heldForNetwork reports whether the journey is paused for the network.
+func (e *Engine) heldForNetwork(j *Journey) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return j.NetworkHold != nil
+}
+
+// holdIfOffline pauses the journey before its step runs if the step needs
+// the network and the network is offline, or if it is already held. It
+// reports whether the step must not run.
+func (e *Engine) holdIfOffline(j *Journey, index int) bool {
+	status := e.networkStatus()
+	offline := status == network.StatusOffline && e.needsNetwork(j.Steps[index].Workflow)
+
+	e.mu.Lock()
+	if j.NetworkHold == nil && !offline {
+		e.mu.Unlock()
+		return false
+	}
+	var data map[string]interface{}
+	if j.NetworkHold == nil {
+		data = e.holdLocked(j, status)
+	}
+	delete(e.cancels, j.ID)
+	e.saveLocked(j)
+	e.mu.Unlock()
+
+	if data != nil {
+		e.emit("journey.networkPaused", data)
+	}
+	return true
+}
+
+// warnNetwork records a degraded network on the journeys that are running
+// or waiting for the network, and emits journey.networkWarning.
+func (e *Engine) warnNetwork(status network.Status) {
+	e.mu.Lock()
+	var events []map[string]interface{}
+	for id, j := range e.journeys {
+		if _, running := e.cancels[id]; !running && j.NetworkHold == nil {
+			continue
+		}
+		index := j.CurrentStep
+		detail := fmt.Sprintf("network %s", status)
+		j.record("network."+string(status), &index, detail)
+		e.saveLocked(j)
+		events = append(events, map[string]interface{}{
+			"journeyId": j.ID,
+			"stepIndex": index,
+			"network":   status,
+			"message":   detail + ": steps may fail or run slowly",
+		})
+	}
+	e.mu.Unlock()
+
+	for _, data := range events {
+		e.emit("journey.networkWarning", data)
+	}
+}
+
+// adoptNetworkHolds loads the persisted journeys that are held for the
+// network, so they resume once it is back even after a restart. Journeys
+// whose state cannot be read are left alone.
+func (e *Engine) adoptNetworkHolds() error {
+	ids, err := e.store.ListJourneyIDs()
+	if err != nil {
+		return fmt.Errorf("loading journeys held for the network: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, id := range ids {
+		j := &Journey{}
+		if err := e.store.LoadJourney(id, j); err != nil || j.NetworkHold == nil {
+			continue
+		}
+		e.journeys[id] = j
+	}
+	return nil
+}
+
+// scheduleNetworkResume (re)starts the wait before held journeys resume.
+func (e *Engine) scheduleNetworkResume() {
+	delay := time.Duration(e.settings().NetworkResumeDelay) * time.Millisecond
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	held := false
+	for _, j := range e.journeys {
+		held = held || j.NetworkHold != nil
+	}
+	if !held {
+		return
+	}
+	if e.resumeTimer != nil {
+		e.resumeTimer.Stop()
+	}
+	e.resumeTimer = time.AfterFunc(delay, func() { e.resumeAfterNetwork(delay) })
+}
+
+// stopResumeTimer cancels a pending automatic resume.
+func (e *Engine) stopResumeTimer() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.resumeTimer != nil {
+		e.resumeTimer.Stop()
+		e.resumeTimer = nil
+	}
+}
+
+// resumeAfterNetwork resumes the journeys held for the network once it has
+// stayed online for the resume delay.
+func (e *Engine) resumeAfterNetwork(stable time.Duration) {
+	e.mu.Lock()
+	e.resumeTimer = nil
+	if e.lastNetwork != network.StatusOnline {
+		e.mu.Unlock()
+		return
+	}
+	var ids []string
+	var events []map[string]interface{}
+	for id, j := range e.journeys {
+		if j.NetworkHold == nil {
+			continue
+		}
+		index := j.NetworkHold.StepIndex
+		j.NetworkHold = nil
+		detail := fmt.Sprintf("network online for %s", stable)
+		j.record("journey.networkResumed", &index, detail)
+		if _, running := e.cancels[id]; running {
+			// Still stopping its attempt; the run loop restarts the step
+			j.Status = StatusRunning
+			j.PauseReason = ""
+		} else {
+			ids = append(ids, id)
+		}
+		e.saveLocked(j)
+		events = append(events, map[string]interface{}{
+			"journeyId": j.ID,
+			"stepIndex": index,
+			"reason":    detail,
+		})
+	}
+	e.mu.Unlock()
+
+	for _, data := range events {
+		e.emit("journey.networkResumed", data)
+	}
+	for _, id := range ids {
+		if _, err := e.Resume(id, ""); err != nil {
+			e.emit("journey.error", map[string]interface{}{
+				"journeyId": id,
+				"error":     err.Error(),
+			})
+		}
+	}
+}
+
+// record appends a network event to the journey's timeline.
+func (j *Journey) record(event string, index *int, detail string) {
+	j.Timeline = append(j.Timeline, TimelineEntry{Time: time.Now(), Event: event, StepIndex: index, Detail: detail})
+}
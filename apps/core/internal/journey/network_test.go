@@ -0,0 +1,184 @@
+package journey
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/network"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/opencode"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/state"
+)
+
+// waitForJourney polls until the journey satisfies cond or fails the test.
+func waitForJourney(t *testing.T, engine *Engine, id string, cond func(*Journey) bool) *Journey {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		j, err := engine.Get(id)
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if cond(j) {
+			return j
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("journey did not reach the expected state: status %s, hold %+v", j.Status, j.NetworkHold)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func timelineEvents(j *Journey) []string {
+	var events []string
+	for _, e := range j.Timeline {
+		events = append(events, e.Event)
+	}
+	return events
+}
+
+func TestEngine_NetworkOfflinePausesAndResumes(t *testing.T) {
+	var engine *Engine
+	var calls atomic.Int32
+	started := make(chan struct{})
+	runner := &fakeRunner{fn: func(ctx context.Context, req opencode.ExecRequest) *opencode.ExecResult {
+		if calls.Add(1) == 1 {
+			close(started)
+			<-ctx.Done()
+			return &opencode.ExecResult{ExitCode: -1, Cancelled: true}
+		}
+		writePRD(t, engine.projectPath)
+		return &opencode.ExecResult{ExitCode: 0}
+	}}
+	engine, events := newTestEngine(t, runner)
+	engine.Settings = func() *state.Settings {
+		s := state.DefaultSettings()
+		s.NetworkResumeDelay = 20
+		return s
+	}
+
+	j, _ := engine.Start([]string{"prd"}, "")
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("step did not start")
+	}
+
+	engine.NetworkChanged(network.StatusOffline)
+	held := waitForJourney(t, engine, j.ID, func(j *Journey) bool {
+		return j.Status == StatusPaused && len(engine.Running()) == 0
+	})
+	if held.NetworkHold == nil || held.NetworkHold.StepIndex != 0 {
+		t.Fatalf("NetworkHold = %+v", held.NetworkHold)
+	}
+	if a := held.Steps[0].Attempts[0]; a.Outcome != AttemptInterrupted || a.Error != "stopped: network offline" {
+		t.Errorf("attempt = %s %q, want interrupted by the network", a.Outcome, a.Error)
+	}
+	if !events.has("journey.networkPaused") {
+		t.Error("journey.networkPaused not emitted")
+	}
+
+	// Degraded is not stable enough to resume
+	engine.NetworkChanged(network.StatusDegraded)
+	time.Sleep(50 * time.Millisecond)
+	if got, _ := engine.Get(j.ID); got.Status != StatusPaused {
+		t.Fatalf("Status = %s after degraded, want paused", got.Status)
+	}
+
+	engine.NetworkChanged(network.StatusOnline)
+	done := waitForJourney(t, engine, j.ID, func(j *Journey) bool { return j.Status == StatusCompleted })
+	engine.Wait()
+
+	if done.NetworkHold != nil {
+		t.Errorf("NetworkHold = %+v after resume", done.NetworkHold)
+	}
+	if !events.has("journey.networkResumed") || !events.has("journey.networkWarning") {
+		t.Error("resume or warning event not emitted")
+	}
+	want := []string{"journey.networkPaused", "network.degraded", "journey.networkResumed"}
+	got := timelineEvents(done)
+	if len(got) != len(want) {
+		t.Fatalf("timeline = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("timeline[%d] = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestEngine_NetworkOfflineAtStart(t *testing.T) {
+	var engine *Engine
+	runner := &fakeRunner{fn: func(ctx context.Context, req opencode.ExecRequest) *opencode.ExecResult {
+		writePRD(t, engine.projectPath)
+		return &opencode.ExecResult{ExitCode: 0}
+	}}
+	engine, _ = newTestEngine(t, runner)
+	engine.NetworkChanged(network.StatusOffline)
+
+	j, _ := engine.Start([]string{"prd"}, "")
+	engine.Wait()
+	got, _ := engine.Get(j.ID)
+	if got.Status != StatusPaused || got.NetworkHold == nil || len(runner.calls()) != 0 {
+		t.Fatalf("Status = %s, hold = %+v, calls = %d; want held before running", got.Status, got.NetworkHold, len(runner.calls()))
+	}
+
+	// A manual resume clears the hold; the step needs the network so it is held again
+	if _, err := engine.Resume(j.ID, ""); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	engine.Wait()
+	got, _ = engine.Get(j.ID)
+	if got.Status != StatusPaused || len(got.Timeline) != 3 || got.Timeline[1].Detail != "resumed by user" {
+		t.Errorf("Status = %s, timeline = %+v", got.Status, got.Timeline)
+	}
+
+	// Workflows marked offline run without the network
+	engine.Settings = func() *state.Settings {
+		s := state.DefaultSettings()
+		s.WorkflowOverrides["prd"] = state.WorkflowOverride{Offline: true}
+		return s
+	}
+	if _, err := engine.Resume(j.ID, ""); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	engine.Wait()
+	if got, _ := engine.Get(j.ID); got.Status != StatusCompleted {
+		t.Errorf("Status = %s, want completed for an offline workflow", got.Status)
+	}
+}
+
+func TestEngine_NetworkHoldSurvivesReload(t *testing.T) {
+	var calls atomic.Int32
+	runner := &fakeRunner{fn: func(ctx context.Context, req opencode.ExecRequest) *opencode.ExecResult {
+		calls.Add(1)
+		writePRD(t, req.Dir)
+		return &opencode.ExecResult{ExitCode: 0}
+	}}
+	engine, _ := newTestEngine(t, runner)
+	engine.NetworkChanged(network.StatusOffline)
+	j, _ := engine.Start([]string{"prd"}, "")
+	engine.Wait()
+	if got, _ := engine.Get(j.ID); got.NetworkHold == nil {
+		t.Fatalf("NetworkHold = nil, want held before reloading")
+	}
+
+	// A new engine for the same project, as after a restart or a reopen
+	reloaded, err := NewEngine(engine.projectPath, runner)
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	reloaded.Settings = func() *state.Settings {
+		s := state.DefaultSettings()
+		s.NetworkResumeDelay = 20
+		return s
+	}
+	reloaded.NetworkChanged(network.StatusOnline)
+	done := waitForJourney(t, reloaded, j.ID, func(j *Journey) bool { return j.Status == StatusCompleted })
+	reloaded.Wait()
+
+	if done.NetworkHold != nil || calls.Load() != 1 {
+		t.Errorf("NetworkHold = %+v, calls = %d; want resumed and run once", done.NetworkHold, calls.Load())
+	}
+}
@@ -0,0 +1,227 @@
+package journey
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RouteEditOp is a single change to the remaining route.
+type RouteEditOp string
+
+const (
+	RouteInsert  RouteEditOp = "insert"  // Insert Workflow before StepIndex (len(steps) appends)
+	RouteRemove  RouteEditOp = "remove"  // Drop the step at StepIndex
+	RouteReplace RouteEditOp = "replace" // Swap the step at StepIndex for Workflow
+)
+
+// RouteEdit changes the remaining route relative to current step indexes.
+type RouteEdit struct {
+	Op        RouteEditOp `json:"op"`
+	StepIndex int         `json:"stepIndex"`
+	Workflow  string      `json:"workflow,omitempty"`
+}
+
+// ReplanRequest describes a new direction for a journey (FR10). Either Route
+// replaces the whole remaining route or Edits are applied to it in order.
+type ReplanRequest struct {
+	Feedback string
+	Route    []string
+	Edits    []RouteEdit
+}
+
+// RouteChange is a step added to or removed from the route.
+type RouteChange struct {
+	StepIndex int    `json:"stepIndex"`
+	Workflow  string `json:"workflow"`
+}
+
+// RouteRevision is one version of a journey's route.
+type RouteRevision struct {
+	Version   int           `json:"version"`
+	CreatedAt time.Time     `json:"createdAt"`
+	Feedback  string        `json:"feedback,omitempty"`
+	FromStep  int           `json:"fromStep"` // First step the revision could change
+	Route     []string      `json:"route"`    // Full route after the revision, including skipped steps
+	Added     []RouteChange `json:"added,omitempty"`
+	Removed   []RouteChange `json:"removed,omitempty"`
+}
+
+// Route returns the workflows of all steps in order.
+func (j *Journey) Route() []string {
+	route := make([]string, len(j.Steps))
+	for i, s := range j.Steps {
+		route[i] = s.Workflow
+	}
+	return route
+}
+
+// firstEditable returns the index of the first step a replan may change.
+// Steps before the current one are history. The current step is kept while
+// it runs; once attempted it can only be skipped, never rewritten.
+func (j *Journey) firstEditable(active bool) int {
+	first := j.CurrentStep
+	if active && first < len(j.Steps) {
+		first++
+	}
+	return first
+}
+
+// replan applies a request to the journey and records the revision.
+// active reports whether the journey's current step is executing.
+func (j *Journey) replan(req ReplanRequest, active bool) (*RouteRevision, error) {
+	first := j.firstEditable(active)
+	from := first
+	if len(j.Revisions) == 0 {
+		// Journeys created before revisions were tracked get their original route as version 1
+		j.Revisions = append(j.Revisions, &RouteRevision{Version: 1, CreatedAt: j.CreatedAt, Route: j.Route()})
+	}
+
+	oldRemaining := j.Route()[first:]
+	newRemaining, err := applyEdits(oldRemaining, first, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// The current step keeps its attempts and directory: if the new route
+	// drops or swaps it, it is skipped and the new steps follow it.
+	kept := j.Steps[:first]
+	skipCurrent := false
+	if first < len(j.Steps) && len(j.Steps[first].Attempts) > 0 {
+		if len(newRemaining) > 0 && newRemaining[0] == j.Steps[first].Workflow {
+			newRemaining = newRemaining[1:]
+		} else {
+			skipCurrent = true
+		}
+		kept = j.Steps[:first+1]
+		oldRemaining = oldRemaining[1:]
+		first++
+	}
+
+	steps := append([]*Step{}, kept...)
+	for i, workflow := range newRemaining {
+		index := first + i
+		if index < len(j.Steps) && j.Steps[index].Workflow == workflow {
+			// Unchanged pending step; keep queued feedback
+			steps = append(steps, j.Steps[index])
+			continue
+		}
+		steps = append(steps, &Step{Index: index, Workflow: workflow, Status: StepPending})
+	}
+
+	if skipCurrent {
+		cur := j.Steps[j.CurrentStep]
+		cur.Status = StepSkipped
+		cur.Feedback = nil
+		j.CurrentStep++
+	}
+
+	added, removed := diffRoutes(oldRemaining, newRemaining, first)
+	if len(added) == 0 && len(removed) == 0 && !skipCurrent {
+		return nil, fmt.Errorf("%w: the new route is unchanged", ErrInvalidState)
+	}
+	if skipCurrent {
+		removed = append([]RouteChange{{StepIndex: first - 1, Workflow: j.Steps[first-1].Workflow}}, removed...)
+	}
+
+	j.Steps = steps
+	j.Destination = ""
+	for i := len(steps) - 1; i >= 0; i-- {
+		if steps[i].Status != StepSkipped {
+			j.Destination = steps[i].Workflow
+			break
+		}
+	}
+
+	rev := &RouteRevision{
+		Version:   j.Revisions[len(j.Revisions)-1].Version + 1,
+		CreatedAt: time.Now(),
+		Feedback:  strings.TrimSpace(req.Feedback),
+		FromStep:  from,
+		Route:     j.Route(),
+		Added:     added,
+		Removed:   removed,
+	}
+	j.Revisions = append(j.Revisions, rev)
+	return rev, nil
+}
+
+// applyEdits computes the new remaining route. first is the absolute index
+// of remaining[0]; edit indexes are absolute and refer to the route before
+// the edit is applied.
+func applyEdits(remaining []string, first int, req ReplanRequest) ([]string, error) {
+	if req.Route != nil {
+		if len(req.Edits) > 0 {
+			return nil, fmt.Errorf("%w: give either a route or edits, not both", ErrInvalidState)
+		}
+		return append([]string{}, req.Route...), nil
+	}
+	if len(req.Edits) == 0 {
+		return nil, fmt.Errorf("%w: no route changes given", ErrInvalidState)
+	}
+
+	route := append([]string{}, remaining...)
+	for _, edit := range req.Edits {
+		pos := edit.StepIndex - first
+		if edit.StepIndex < first {
+			return nil, fmt.Errorf("%w: step %d is already done or running and cannot be changed", ErrInvalidState, edit.StepIndex)
+		}
+
+		switch edit.Op {
+		case RouteInsert:
+			if pos > len(route) {
+				return nil, fmt.Errorf("%w: insert position %d is past the end of the route", ErrInvalidState, edit.StepIndex)
+			}
+			route = append(route[:pos], append([]string{edit.Workflow}, route[pos:]...)...)
+		case RouteRemove:
+			if pos >= len(route) {
+				return nil, fmt.Errorf("%w: step %d does not exist", ErrInvalidState, edit.StepIndex)
+			}
+			route = append(route[:pos], route[pos+1:]...)
+		case RouteReplace:
+			if pos >= len(route) {
+				return nil, fmt.Errorf("%w: step %d does not exist", ErrInvalidState, edit.StepIndex)
+			}
+			route[pos] = edit.Workflow
+		default:
+			return nil, fmt.Errorf("%w: unknown route edit %q", ErrInvalidState, edit.Op)
+		}
+	}
+	return route, nil
+}
+
+// diffRoutes returns the steps added to and removed from a route, using the
+// longest common subsequence so unchanged steps are not reported. first is
+// the absolute index of both routes' first element.
+func diffRoutes(old, new []string, first int) (added, removed []RouteChange) {
+	// lcs[i][k] is the LCS length of old[i:] and new[k:]
+	lcs := make([][]int, len(old)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(new)+1)
+	}
+	for i := len(old) - 1; i >= 0; i-- {
+		for k := len(new) - 1; k >= 0; k-- {
+			if old[i] == new[k] {
+				lcs[i][k] = lcs[i+1][k+1] + 1
+			} else {
+				lcs[i][k] = max(lcs[i+1][k], lcs[i][k+1])
+			}
+		}
+	}
+
+	i, k := 0, 0
+	for i < len(old) || k < len(new) {
+		switch {
+		case i < len(old) && k < len(new) && old[i] == new[k]:
+			i++
+			k++
+		case k < len(new) && (i == len(old) || lcs[i][k+1] >= lcs[i+1][k]):
+			added = append(added, RouteChange{StepIndex: first + k, Workflow: new[k]})
+			k++
+		default:
+			removed = append(removed, RouteChange{StepIndex: first + i, Workflow: old[i]})
+			i++
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,113 @@
+package journey
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// routeJourney returns a journey over route whose first done steps are completed.
+func routeJourney(route []string, done int) *Journey {
+	j := NewJourney("/tmp/project", route, "")
+	for i := 0; i < done; i++ {
+		j.Steps[i].Status = StepCompleted
+		j.Steps[i].Attempts = []*Attempt{{Number: 1, Outcome: AttemptSucceeded}}
+	}
+	j.CurrentStep = done
+	return j
+}
+
+func TestReplan_InsertStep(t *testing.T) {
+	j := routeJourney([]string{"create-product-brief", "prd", "create-architecture"}, 2)
+	completed := j.Steps[0]
+
+	rev, err := j.replan(ReplanRequest{
+		Feedback: "We need a UX design first",
+		Edits:    []RouteEdit{{Op: RouteInsert, StepIndex: 2, Workflow: "create-ux-design"}},
+	}, false)
+	if err != nil {
+		t.Fatalf("replan failed: %v", err)
+	}
+
+	want := []string{"create-product-brief", "prd", "create-ux-design", "create-architecture"}
+	if !reflect.DeepEqual(j.Route(), want) {
+		t.Errorf("Route = %v, want %v", j.Route(), want)
+	}
+	for i, s := range j.Steps {
+		if s.Index != i {
+			t.Errorf("step %d has Index %d", i, s.Index)
+		}
+	}
+	if j.Steps[0] != completed || j.Destination != "create-architecture" {
+		t.Error("completed step was rewritten or destination is wrong")
+	}
+	if rev.Version != 2 || rev.FromStep != 2 || rev.Feedback != "We need a UX design first" {
+		t.Errorf("revision = %+v", rev)
+	}
+	if !reflect.DeepEqual(rev.Added, []RouteChange{{StepIndex: 2, Workflow: "create-ux-design"}}) || len(rev.Removed) != 0 {
+		t.Errorf("diff = +%v -%v", rev.Added, rev.Removed)
+	}
+	if len(j.Revisions) != 2 || !reflect.DeepEqual(j.Revisions[1].Route, want) {
+		t.Errorf("Revisions = %+v", j.Revisions)
+	}
+}
+
+func TestReplan_CompletedStepsAreFrozen(t *testing.T) {
+	j := routeJourney([]string{"create-product-brief", "prd", "create-epics-and-stories"}, 2)
+
+	_, err := j.replan(ReplanRequest{Edits: []RouteEdit{{Op: RouteRemove, StepIndex: 1}}}, false)
+	if !errors.Is(err, ErrInvalidState) {
+		t.Errorf("removing a completed step err = %v, want ErrInvalidState", err)
+	}
+
+	// The running step cannot be changed either
+	_, err = j.replan(ReplanRequest{Edits: []RouteEdit{{Op: RouteReplace, StepIndex: 2, Workflow: "prd"}}}, true)
+	if !errors.Is(err, ErrInvalidState) {
+		t.Errorf("replacing the running step err = %v, want ErrInvalidState", err)
+	}
+
+	_, err = j.replan(ReplanRequest{Route: []string{"create-epics-and-stories"}}, false)
+	if !errors.Is(err, ErrInvalidState) {
+		t.Errorf("unchanged route err = %v, want ErrInvalidState", err)
+	}
+}
+
+func TestReplan_SwapAttemptedStep(t *testing.T) {
+	j := routeJourney([]string{"prd", "create-epics-and-stories", "sprint-planning"}, 1)
+	failed := j.Steps[1]
+	failed.Status = StepFailed
+	failed.Attempts = []*Attempt{{Number: 1, Outcome: AttemptFailed}}
+	j.Status = StatusPaused
+
+	rev, err := j.replan(ReplanRequest{Route: []string{"create-architecture", "sprint-planning"}}, false)
+	if err != nil {
+		t.Fatalf("replan failed: %v", err)
+	}
+
+	want := []string{"prd", "create-epics-and-stories", "create-architecture", "sprint-planning"}
+	if !reflect.DeepEqual(j.Route(), want) {
+		t.Errorf("Route = %v, want %v", j.Route(), want)
+	}
+	if failed.Status != StepSkipped || len(failed.Attempts) != 1 || j.Steps[1] != failed {
+		t.Errorf("attempted step = %+v, want skipped with attempts kept", failed)
+	}
+	if j.CurrentStep != 2 {
+		t.Errorf("CurrentStep = %d, want 2", j.CurrentStep)
+	}
+	wantRemoved := []RouteChange{{StepIndex: 1, Workflow: "create-epics-and-stories"}}
+	wantAdded := []RouteChange{{StepIndex: 2, Workflow: "create-architecture"}}
+	if !reflect.DeepEqual(rev.Removed, wantRemoved) || !reflect.DeepEqual(rev.Added, wantAdded) {
+		t.Errorf("diff = +%v -%v", rev.Added, rev.Removed)
+	}
+}
+
+func TestDiffRoutes(t *testing.T) {
+	added, removed := diffRoutes([]string{"a", "b", "c"}, []string{"a", "x", "c", "y"}, 3)
+
+	if !reflect.DeepEqual(added, []RouteChange{{4, "x"}, {6, "y"}}) {
+		t.Errorf("added = %v", added)
+	}
+	if !reflect.DeepEqual(removed, []RouteChange{{4, "b"}}) {
+		t.Errorf("removed = %v", removed)
+	}
+}
@@ -0,0 +1,212 @@
+package journey
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+)
+
+// ReportFormat is an export format for journey reports.
+type ReportFormat string
+
+const (
+	ReportMarkdown ReportFormat = "md"
+	ReportHTML     ReportFormat = "html"
+	ReportJSON     ReportFormat = "json"
+)
+
+// ParseReportFormat validates a format name; "markdown" is accepted for md.
+func ParseReportFormat(name string) (ReportFormat, error) {
+	switch strings.ToLower(name) {
+	case "md", "markdown":
+		return ReportMarkdown, nil
+	case "html":
+		return ReportHTML, nil
+	case "json":
+		return ReportJSON, nil
+	default:
+		return "", fmt.Errorf("%w: unknown report format %q (md, html or json)", ErrInvalidState, name)
+	}
+}
+
+// RenderReport renders a summary in the given format.
+func RenderReport(s *Summary, format ReportFormat) ([]byte, error) {
+	switch format {
+	case ReportMarkdown:
+		return renderMarkdownReport(s), nil
+	case ReportHTML:
+		var buf bytes.Buffer
+		if err := htmlReport.Execute(&buf, s); err != nil {
+			return nil, fmt.Errorf("rendering html report: %w", err)
+		}
+		return buf.Bytes(), nil
+	case ReportJSON:
+		data, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("marshaling report: %w", err)
+		}
+		return append(data, '\n'), nil
+	default:
+		return nil, fmt.Errorf("%w: unknown report format %q", ErrInvalidState, format)
+	}
+}
+
+// renderMarkdownReport renders the summary as a Markdown document.
+func renderMarkdownReport(s *Summary) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Journey Report: %s\n\n", s.Destination)
+	fmt.Fprintf(&b, "- **Journey:** %s\n", s.JourneyID)
+	fmt.Fprintf(&b, "- **Status:** %s\n", s.Status)
+	if s.Profile != "" {
+		fmt.Fprintf(&b, "- **Profile:** %s\n", s.Profile)
+	}
+	if s.StartedAt != nil {
+		fmt.Fprintf(&b, "- **Started:** %s\n", s.StartedAt.Format(time.RFC3339))
+	}
+	if s.CompletedAt != nil {
+		fmt.Fprintf(&b, "- **Completed:** %s\n", s.CompletedAt.Format(time.RFC3339))
+	}
+	fmt.Fprintf(&b, "- **Wall time:** %s\n", formatDuration(s.WallTimeMs))
+	fmt.Fprintf(&b, "- **Attempts:** %d (%d retries)\n", s.Attempts, s.Retries)
+	if s.Usage != nil {
+		fmt.Fprintf(&b, "- **Tokens:** %s\n", formatUsage(s.Usage))
+	}
+	if s.Error != "" {
+		fmt.Fprintf(&b, "- **Error:** %s\n", s.Error)
+	}
+
+	b.WriteString("\n## Steps\n\n")
+	b.WriteString("| # | Workflow | Status | Duration | Attempts | Tokens | Checkpoint |\n")
+	b.WriteString("|---|----------|--------|----------|----------|--------|------------|\n")
+	for _, step := range s.Steps {
+		tokens := "-"
+		if step.Usage != nil {
+			tokens = formatUsage(step.Usage)
+		}
+		checkpoint := "-"
+		if step.Checkpoint != "" {
+			checkpoint = shortSHA(step.Checkpoint)
+		}
+		fmt.Fprintf(&b, "| %d | %s | %s | %s | %d | %s | %s |\n",
+			step.Index+1, step.Workflow, step.Status, formatDuration(step.DurationMs), step.Attempts, tokens, checkpoint)
+	}
+
+	b.WriteString("\n## Artifacts\n\n")
+	if len(s.Artifacts) == 0 {
+		b.WriteString("No artifacts were produced.\n")
+	}
+	for _, a := range s.Artifacts {
+		if a.Exists {
+			fmt.Fprintf(&b, "- `%s` (%s, %d lines)\n", a.Path, a.Change, a.Lines)
+		} else {
+			fmt.Fprintf(&b, "- `%s` (%s, since removed)\n", a.Path, a.Change)
+		}
+	}
+
+	if len(s.Checkpoints) > 0 {
+		b.WriteString("\n## Checkpoints\n\n")
+		for _, sha := range s.Checkpoints {
+			fmt.Fprintf(&b, "- `%s`\n", sha)
+		}
+	}
+
+	fmt.Fprintf(&b, "\n_Generated by Auto-BMAD at %s._\n", s.GeneratedAt.Format(time.RFC3339))
+	return []byte(b.String())
+}
+
+// htmlReport renders the summary as a standalone HTML page.
+var htmlReport = template.Must(template.New("report").Funcs(template.FuncMap{
+	"duration": formatDuration,
+	"usage":    formatUsage,
+	"short":    shortSHA,
+	"time":     func(t time.Time) string { return t.Format(time.RFC3339) },
+	"inc":      func(i int) int { return i + 1 },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Journey Report: {{.Destination}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem; color: #222; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.3rem 0.6rem; text-align: left; }
+code { background: #f3f3f3; padding: 0 0.2rem; }
+</style>
+</head>
+<body>
+<h1>Journey Report: {{.Destination}}</h1>
+<ul>
+<li><strong>Journey:</strong> {{.JourneyID}}</li>
+<li><strong>Status:</strong> {{.Status}}</li>
+{{- if .Profile}}
+<li><strong>Profile:</strong> {{.Profile}}</li>
+{{- end}}
+{{- if .StartedAt}}
+<li><strong>Started:</strong> {{time .StartedAt}}</li>
+{{- end}}
+{{- if .CompletedAt}}
+<li><strong>Completed:</strong> {{time .CompletedAt}}</li>
+{{- end}}
+<li><strong>Wall time:</strong> {{duration .WallTimeMs}}</li>
+<li><strong>Attempts:</strong> {{.Attempts}} ({{.Retries}} retries)</li>
+{{- if .Usage}}
+<li><strong>Tokens:</strong> {{usage .Usage}}</li>
+{{- end}}
+{{- if .Error}}
+<li><strong>Error:</strong> {{.Error}}</li>
+{{- end}}
+</ul>
+<h2>Steps</h2>
+<table>
+<tr><th>#</th><th>Workflow</th><th>Status</th><th>Duration</th><th>Attempts</th><th>Tokens</th><th>Checkpoint</th></tr>
+{{- range .Steps}}
+<tr><td>{{inc .Index}}</td><td>{{.Workflow}}</td><td>{{.Status}}</td><td>{{duration .DurationMs}}</td><td>{{.Attempts}}</td><td>{{if .Usage}}{{usage .Usage}}{{else}}-{{end}}</td><td>{{if .Checkpoint}}<code>{{short .Checkpoint}}</code>{{else}}-{{end}}</td></tr>
+{{- end}}
+</table>
+<h2>Artifacts</h2>
+{{- if .Artifacts}}
+<ul>
+{{- range .Artifacts}}
+<li><code>{{.Path}}</code> ({{.Change}}{{if .Exists}}, {{.Lines}} lines{{else}}, since removed{{end}})</li>
+{{- end}}
+</ul>
+{{- else}}
+<p>No artifacts were produced.</p>
+{{- end}}
+{{- if .Checkpoints}}
+<h2>Checkpoints</h2>
+<ul>
+{{- range .Checkpoints}}
+<li><code>{{.}}</code></li>
+{{- end}}
+</ul>
+{{- end}}
+<p><em>Generated by Auto-BMAD at {{time .GeneratedAt}}.</em></p>
+</body>
+</html>
+`))
+
+// formatDuration renders milliseconds as e.g. "1h2m3s" or "850ms".
+func formatDuration(ms int64) string {
+	d := time.Duration(ms) * time.Millisecond
+	if d < time.Second {
+		return d.String()
+	}
+	return d.Round(time.Second).String()
+}
+
+// formatUsage renders token counts and cost on one line.
+func formatUsage(u *Usage) string {
+	return fmt.Sprintf("%d in / %d out, $%.4f", u.InputTokens, u.OutputTokens, u.Cost)
+}
+
+// shortSHA abbreviates a commit SHA.
+func shortSHA(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
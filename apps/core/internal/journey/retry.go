@@ -0,0 +1,157 @@
+package journey
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/network"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/opencode"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/state"
+)
+
+// maxBackoffDelay caps exponential backoff between attempts.
+const maxBackoffDelay = 5 * time.Minute
+
+// AttemptOutcome is the result of a single step attempt.
+type AttemptOutcome string
+
+const (
+	AttemptRunning   AttemptOutcome = "running"
+	AttemptSucceeded AttemptOutcome = "succeeded"
+	AttemptFailed    AttemptOutcome = "failed"
+	AttemptCancelled AttemptOutcome = "cancelled"
+	// AttemptInterrupted means the attempt was stopped to wait for a yellow flag answer.
+	AttemptInterrupted AttemptOutcome = "interrupted"
+)
+
+// Attempt records one execution of a step.
+type Attempt struct {
+	Number      int                `json:"number"` // 1-based
+	Outcome     AttemptOutcome     `json:"outcome"`
+	StartedAt   time.Time          `json:"startedAt"`
+	FinishedAt  time.Time          `json:"finishedAt"`
+	ExitCode    int                `json:"exitCode"`
+	Error       string             `json:"error,omitempty"`
+	Verdict     *CompletionVerdict `json:"verdict,omitempty"`
+	Failure     *Classification    `json:"failure,omitempty"`
+	Network     network.Status     `json:"network,omitempty"`  // Network status when the attempt ended
+	Feedback    []string           `json:"feedback,omitempty"` // User feedback given to this attempt
+	ContextFile string             `json:"contextFile,omitempty"`
+	LogFile     string             `json:"logFile,omitempty"`
+
+	TranscriptFile string `json:"transcriptFile,omitempty"` // Output and input in order
+	Usage          *Usage `json:"usage,omitempty"`          // Tokens and cost, when OpenCode reports them
+
+	StallAction opencode.StallAction `json:"stallAction,omitempty"` // How a stalled process was stopped
+}
+
+// RetryPolicy controls how often and how fast a failed step is re-run.
+type RetryPolicy struct {
+	MaxRetries int           // Retries after the first attempt (0 = no retries)
+	Delay      time.Duration // Base delay before a retry
+	Backoff    bool          // Double the delay for every further retry
+	Jitter     bool          // Randomize the delay between 50% and 100%
+}
+
+// RetryPolicyFromSettings builds a policy from the persisted settings.
+func RetryPolicyFromSettings(s *state.Settings) RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: s.MaxRetries,
+		Delay:      time.Duration(s.RetryDelay) * time.Millisecond,
+		Backoff:    s.RetryBackoff,
+		Jitter:     s.RetryJitter,
+	}
+}
+
+// RetryPolicyForWorkflow builds a policy for the steps of a workflow,
+// applying its retry overrides (see state.WorkflowOverride).
+func RetryPolicyForWorkflow(s *state.Settings, workflow string) RetryPolicy {
+	ws := s.ForWorkflow(workflow)
+	policy := RetryPolicyFromSettings(s)
+	policy.MaxRetries = ws.MaxRetries
+	policy.Delay = time.Duration(ws.RetryDelay) * time.Millisecond
+	return policy
+}
+
+// DelayFor returns the wait before the given retry (1-based).
+// rnd returns a value in [0, 1) and is only used when Jitter is enabled.
+func (p RetryPolicy) DelayFor(retry int, rnd func() float64) time.Duration {
+	delay := p.Delay
+	if p.Backoff {
+		for i := 1; i < retry && delay < maxBackoffDelay; i++ {
+			delay *= 2
+		}
+		if delay > maxBackoffDelay {
+			delay = maxBackoffDelay
+		}
+	}
+	if p.Jitter && delay > 0 {
+		half := delay / 2
+		delay = half + time.Duration(rnd()*float64(half))
+	}
+	return delay
+}
+
+// RetryController re-runs a failed step according to a RetryPolicy.
+type RetryController struct {
+	Policy RetryPolicy
+
+	// OnRetry is called before waiting for the next attempt.
+	OnRetry func(nextAttempt int, delay time.Duration)
+	// Final reports whether a failed attempt must not run again even if
+	// the budget allows it. Optional.
+	Final func(attempt int) bool
+
+	rnd   func() float64
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewRetryController creates a controller for the given policy.
+func NewRetryController(policy RetryPolicy) *RetryController {
+	return &RetryController{
+		Policy: policy,
+		rnd:    rand.Float64,
+		sleep:  sleepContext,
+	}
+}
+
+// Run calls attempt with attempt numbers starting at first until an attempt
+// succeeds, is cancelled, fails for good, or the retry budget is spent. It
+// returns the last outcome and whether retries were exhausted.
+func (rc *RetryController) Run(ctx context.Context, first int, attempt func(n int) AttemptOutcome) (AttemptOutcome, bool) {
+	last := first + rc.Policy.MaxRetries
+	for n := first; ; n++ {
+		outcome := attempt(n)
+		if outcome != AttemptFailed {
+			return outcome, false
+		}
+		if n >= last || (rc.Final != nil && rc.Final(n)) {
+			return outcome, true
+		}
+
+		retry := n - first + 1
+		delay := rc.Policy.DelayFor(retry, rc.rnd)
+		if rc.OnRetry != nil {
+			rc.OnRetry(n+1, delay)
+		}
+		if err := rc.sleep(ctx, delay); err != nil {
+			return AttemptCancelled, false
+		}
+	}
+}
+
+// sleepContext waits for d or until ctx is cancelled.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
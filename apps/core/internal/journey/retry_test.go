@@ -0,0 +1,99 @@
+package journey
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_DelayFor(t *testing.T) {
+	half := func() float64 { return 0.5 }
+
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		retry  int
+		want   time.Duration
+	}{
+		{"fixed", RetryPolicy{Delay: time.Second}, 3, time.Second},
+		{"backoff first", RetryPolicy{Delay: time.Second, Backoff: true}, 1, time.Second},
+		{"backoff third", RetryPolicy{Delay: time.Second, Backoff: true}, 3, 4 * time.Second},
+		{"backoff capped", RetryPolicy{Delay: time.Minute, Backoff: true}, 10, maxBackoffDelay},
+		{"jitter", RetryPolicy{Delay: time.Second, Jitter: true}, 1, 750 * time.Millisecond},
+		{"jitter zero delay", RetryPolicy{Jitter: true}, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.DelayFor(tt.retry, half); got != tt.want {
+				t.Errorf("DelayFor(%d) = %v, want %v", tt.retry, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryController_Run(t *testing.T) {
+	var delays []time.Duration
+	rc := NewRetryController(RetryPolicy{MaxRetries: 2, Delay: time.Second, Backoff: true})
+	rc.sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+	var retries []int
+	rc.OnRetry = func(next int, delay time.Duration) { retries = append(retries, next) }
+
+	var attempts []int
+	outcome, exhausted := rc.Run(context.Background(), 1, func(n int) AttemptOutcome {
+		attempts = append(attempts, n)
+		return AttemptFailed
+	})
+
+	if outcome != AttemptFailed || !exhausted {
+		t.Errorf("Run = %s, %v, want failed, exhausted", outcome, exhausted)
+	}
+	if len(attempts) != 3 || attempts[2] != 3 {
+		t.Errorf("attempts = %v, want [1 2 3]", attempts)
+	}
+	if len(retries) != 2 || retries[0] != 2 {
+		t.Errorf("OnRetry = %v, want [2 3]", retries)
+	}
+	if len(delays) != 2 || delays[1] != 2*time.Second {
+		t.Errorf("delays = %v, want [1s 2s]", delays)
+	}
+}
+
+func TestRetryController_StopsOnSuccessAndCancel(t *testing.T) {
+	rc := NewRetryController(RetryPolicy{MaxRetries: 5})
+	calls := 0
+	outcome, exhausted := rc.Run(context.Background(), 4, func(n int) AttemptOutcome {
+		calls++
+		if n == 5 {
+			return AttemptSucceeded
+		}
+		return AttemptFailed
+	})
+	if outcome != AttemptSucceeded || exhausted || calls != 2 {
+		t.Errorf("Run = %s, %v after %d calls", outcome, exhausted, calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rc = NewRetryController(RetryPolicy{MaxRetries: 5, Delay: time.Hour})
+	outcome, exhausted = rc.Run(ctx, 1, func(n int) AttemptOutcome { return AttemptFailed })
+	if outcome != AttemptCancelled || exhausted {
+		t.Errorf("Run after cancel = %s, %v, want cancelled", outcome, exhausted)
+	}
+}
+
+func TestRetryController_StopsOnFinalFailure(t *testing.T) {
+	rc := NewRetryController(RetryPolicy{MaxRetries: 5})
+	rc.Final = func(n int) bool { return n == 2 }
+	calls := 0
+	outcome, exhausted := rc.Run(context.Background(), 1, func(n int) AttemptOutcome {
+		calls++
+		return AttemptFailed
+	})
+	if outcome != AttemptFailed || !exhausted || calls != 2 {
+		t.Errorf("Run = %s, %v after %d calls, want failed, exhausted after 2", outcome, exhausted, calls)
+	}
+}
@@ -0,0 +1,153 @@
+package journey
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// ArtifactChange is what a step did to an artifact.
+type ArtifactChange string
+
+const (
+	ArtifactCreated  ArtifactChange = "created"
+	ArtifactModified ArtifactChange = "modified"
+)
+
+// ArtifactSummary describes an artifact a step produced.
+type ArtifactSummary struct {
+	Path   string         `json:"path"` // Relative to _bmad-output/
+	Change ArtifactChange `json:"change"`
+	Lines  int            `json:"lines"`
+	Exists bool           `json:"exists"` // False if the file was removed since
+}
+
+// StepSummary is the outcome of one step.
+type StepSummary struct {
+	Index       int               `json:"index"`
+	Workflow    string            `json:"workflow"`
+	Status      StepStatus        `json:"status"`
+	StartedAt   *time.Time        `json:"startedAt,omitempty"`
+	CompletedAt *time.Time        `json:"completedAt,omitempty"`
+	DurationMs  int64             `json:"durationMs"`
+	Attempts    int               `json:"attempts"`
+	Usage       *Usage            `json:"usage,omitempty"`
+	Artifacts   []ArtifactSummary `json:"artifacts"`
+	Checkpoint  string            `json:"checkpoint,omitempty"`
+	Error       string            `json:"error,omitempty"`
+}
+
+// Summary is the completion summary of a journey (FR41). For a journey
+// still in progress it covers the work done so far.
+type Summary struct {
+	JourneyID   string            `json:"journeyId"`
+	Destination string            `json:"destination"`
+	Status      Status            `json:"status"`
+	Profile     string            `json:"profile,omitempty"`
+	StartedAt   *time.Time        `json:"startedAt,omitempty"`
+	CompletedAt *time.Time        `json:"completedAt,omitempty"`
+	WallTimeMs  int64             `json:"wallTimeMs"`
+	Steps       []StepSummary     `json:"steps"`
+	Attempts    int               `json:"attempts"`
+	Retries     int               `json:"retries"`
+	Usage       *Usage            `json:"usage,omitempty"` // Totals; nil if OpenCode reported no usage
+	Artifacts   []ArtifactSummary `json:"artifacts"`
+	Checkpoints []string          `json:"checkpoints"` // Commit SHAs in step order
+	Error       string            `json:"error,omitempty"`
+	GeneratedAt time.Time         `json:"generatedAt"`
+}
+
+// BuildSummary summarizes a journey, reading artifact line counts from disk.
+// The journey must not be shared with other goroutines.
+func BuildSummary(j *Journey, now time.Time) *Summary {
+	s := &Summary{
+		JourneyID:   j.ID,
+		Destination: j.Destination,
+		Status:      j.Status,
+		Profile:     j.Profile,
+		StartedAt:   j.StartedAt,
+		CompletedAt: j.CompletedAt,
+		WallTimeMs:  elapsedMs(j.StartedAt, j.CompletedAt, now),
+		Steps:       []StepSummary{},
+		Artifacts:   []ArtifactSummary{},
+		Checkpoints: []string{},
+		Error:       j.Error,
+		GeneratedAt: now,
+	}
+
+	outputPath := filepath.Join(j.ProjectPath, "_bmad-output")
+	for _, step := range j.Steps {
+		ss := StepSummary{
+			Index:       step.Index,
+			Workflow:    step.Workflow,
+			Status:      step.Status,
+			StartedAt:   step.StartedAt,
+			CompletedAt: step.CompletedAt,
+			Attempts:    len(step.Attempts),
+			Artifacts:   []ArtifactSummary{},
+			Checkpoint:  step.Checkpoint,
+			Error:       step.Error,
+		}
+		if step.StartedAt != nil {
+			ss.DurationMs = elapsedMs(step.StartedAt, step.CompletedAt, now)
+		}
+		for _, a := range step.Attempts {
+			if a.Usage != nil {
+				if ss.Usage == nil {
+					ss.Usage = &Usage{}
+				}
+				ss.Usage.add(a.Usage)
+			}
+		}
+		for _, path := range step.Artifacts {
+			as := ArtifactSummary{Path: path, Change: ArtifactCreated}
+			if slices.Contains(step.Baseline, path) {
+				as.Change = ArtifactModified
+			}
+			if data, err := os.ReadFile(filepath.Join(outputPath, filepath.FromSlash(path))); err == nil {
+				as.Exists = true
+				as.Lines = countLines(data)
+			}
+			ss.Artifacts = append(ss.Artifacts, as)
+			s.Artifacts = append(s.Artifacts, as)
+		}
+
+		s.Attempts += ss.Attempts
+		if ss.Attempts > 1 {
+			s.Retries += ss.Attempts - 1
+		}
+		if ss.Usage != nil {
+			if s.Usage == nil {
+				s.Usage = &Usage{}
+			}
+			s.Usage.add(ss.Usage)
+		}
+		if step.Checkpoint != "" {
+			s.Checkpoints = append(s.Checkpoints, step.Checkpoint)
+		}
+		s.Steps = append(s.Steps, ss)
+	}
+	return s
+}
+
+// elapsedMs returns the time from start to end, or to now while unfinished.
+func elapsedMs(start, end *time.Time, now time.Time) int64 {
+	if start == nil {
+		return 0
+	}
+	if end != nil {
+		return end.Sub(*start).Milliseconds()
+	}
+	return now.Sub(*start).Milliseconds()
+}
+
+// countLines counts lines, including a last line without a newline.
+func countLines(data []byte) int {
+	n := bytes.Count(data, []byte("\n"))
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,114 @@
+package journey
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/opencode"
+)
+
+func TestEngine_SummaryAndReports(t *testing.T) {
+	var engine *Engine
+	runner := &fakeRunner{fn: func(ctx context.Context, req opencode.ExecRequest) *opencode.ExecResult {
+		writePRD(t, engine.projectPath)
+		req.OnOutput(opencode.StreamStdout, []byte(`{"type":"step_finish","part":{"tokens":{"input":1200,"output":300},"cost":0.02}}`+"\n"))
+		return &opencode.ExecResult{ExitCode: 0}
+	}}
+	engine, _ = newTestEngine(t, runner)
+
+	// The PRD exists before the journey, so the step modifies it
+	writePRD(t, engine.projectPath)
+	past := time.Now().Add(-time.Hour)
+	prd := filepath.Join(engine.projectPath, "_bmad-output", "planning-artifacts", "prd.md")
+	if err := os.Chtimes(prd, past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	j, _ := engine.Start([]string{"prd"}, "")
+	engine.Wait()
+
+	s, err := engine.Summary(j.ID)
+	if err != nil {
+		t.Fatalf("Summary failed: %v", err)
+	}
+	if s.Status != StatusCompleted || s.Attempts != 1 || s.Retries != 0 {
+		t.Errorf("summary = %+v", s)
+	}
+	if s.Usage == nil || s.Usage.InputTokens != 1200 || s.Steps[0].Usage.OutputTokens != 300 {
+		t.Errorf("usage = %+v", s.Usage)
+	}
+	want := ArtifactSummary{Path: "planning-artifacts/prd.md", Change: ArtifactModified, Lines: 3, Exists: true}
+	if len(s.Artifacts) != 1 || s.Artifacts[0] != want {
+		t.Errorf("Artifacts = %+v, want %+v", s.Artifacts, want)
+	}
+	if s.Steps[0].DurationMs < 0 || s.WallTimeMs < s.Steps[0].DurationMs {
+		t.Errorf("wall time %d, step duration %d", s.WallTimeMs, s.Steps[0].DurationMs)
+	}
+
+	for _, tt := range []struct {
+		format ReportFormat
+		want   string
+	}{
+		{ReportMarkdown, "| 1 | prd | completed |"},
+		{ReportHTML, "<code>planning-artifacts/prd.md</code> (modified, 3 lines)"},
+		{ReportJSON, `"journeyId": "` + j.ID + `"`},
+	} {
+		path, err := engine.ExportReport(j.ID, tt.format)
+		if err != nil {
+			t.Fatalf("ExportReport(%s) failed: %v", tt.format, err)
+		}
+		if filepath.Base(path) != j.ID+"."+string(tt.format) {
+			t.Errorf("path = %s", path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), tt.want) {
+			t.Errorf("%s report missing %q:\n%s", tt.format, tt.want, data)
+		}
+		if tt.format == ReportJSON {
+			var decoded Summary
+			if err := json.Unmarshal(data, &decoded); err != nil || decoded.Usage.Cost != 0.02 {
+				t.Errorf("JSON report does not round-trip: %v", err)
+			}
+		}
+	}
+}
+
+func TestBuildSummary_CreatedArtifactsAndCheckpoints(t *testing.T) {
+	j := NewJourney(t.TempDir(), []string{"prd", "create-architecture"}, "")
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	end := start.Add(90 * time.Second)
+	j.StartedAt = &start
+	j.Steps[0].StartedAt = &start
+	j.Steps[0].CompletedAt = &end
+	j.Steps[0].Status = StepCompleted
+	j.Steps[0].Artifacts = []string{"planning-artifacts/prd.md"}
+	j.Steps[0].Checkpoint = "0123456789abcdef0123456789abcdef01234567"
+	j.Steps[0].Attempts = []*Attempt{{Number: 1, Outcome: AttemptFailed}, {Number: 2, Outcome: AttemptSucceeded}}
+
+	s := BuildSummary(j, start.Add(2*time.Minute))
+	if s.WallTimeMs != 120000 || s.Steps[0].DurationMs != 90000 {
+		t.Errorf("wall time = %d, step = %d", s.WallTimeMs, s.Steps[0].DurationMs)
+	}
+	if s.Retries != 1 || s.Usage != nil {
+		t.Errorf("Retries = %d, Usage = %+v", s.Retries, s.Usage)
+	}
+	if a := s.Artifacts[0]; a.Change != ArtifactCreated || a.Exists {
+		t.Errorf("artifact = %+v, want created and missing", a)
+	}
+	if len(s.Checkpoints) != 1 || s.Checkpoints[0] != j.Steps[0].Checkpoint {
+		t.Errorf("Checkpoints = %v", s.Checkpoints)
+	}
+
+	md := string(renderMarkdownReport(s))
+	if !strings.Contains(md, "| 0123456789ab |") || !strings.Contains(md, "1m30s") {
+		t.Errorf("markdown report:\n%s", md)
+	}
+}
@@ -0,0 +1,168 @@
+package journey
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Usage is the token count and cost OpenCode reported for an attempt.
+// OpenCode reports it in the step-finish events of its JSON event stream
+// (--format json); plain text output carries no usage, so Usage stays nil.
+type Usage struct {
+	InputTokens      int64   `json:"inputTokens"`
+	OutputTokens     int64   `json:"outputTokens"`
+	ReasoningTokens  int64   `json:"reasoningTokens,omitempty"`
+	CacheReadTokens  int64   `json:"cacheReadTokens,omitempty"`
+	CacheWriteTokens int64   `json:"cacheWriteTokens,omitempty"`
+	Cost             float64 `json:"cost"` // USD
+}
+
+// add accumulates other into u.
+func (u *Usage) add(other *Usage) {
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.ReasoningTokens += other.ReasoningTokens
+	u.CacheReadTokens += other.CacheReadTokens
+	u.CacheWriteTokens += other.CacheWriteTokens
+	u.Cost += other.Cost
+}
+
+// openCodeEvent is one line of OpenCode's JSON event stream. Usage is
+// either at the top level or inside "part" (step-finish events).
+type openCodeEvent struct {
+	Type string `json:"type"`
+	eventPart
+	Part  *eventPart `json:"part"`
+	Error *struct {
+		Name string `json:"name"`
+		Data struct {
+			Message string `json:"message"`
+		} `json:"data"`
+	} `json:"error"`
+}
+
+type eventPart struct {
+	Text  string `json:"text"`
+	Tool  string `json:"tool"`
+	State *struct {
+		Status string `json:"status"`
+		Title  string `json:"title"`
+	} `json:"state"`
+	Tokens *struct {
+		Input     int64 `json:"input"`
+		Output    int64 `json:"output"`
+		Reasoning int64 `json:"reasoning"`
+		Cache     struct {
+			Read  int64 `json:"read"`
+			Write int64 `json:"write"`
+		} `json:"cache"`
+	} `json:"tokens"`
+	Cost float64 `json:"cost"`
+}
+
+// eventStream turns OpenCode's JSON event stream back into readable output
+// and sums the usage of its step-finish events. Lines that are not events
+// pass through unchanged, and as soon as they arrive, so plain output and
+// prompts without a trailing newline still reach the yellow-flag detector.
+// It is not safe for concurrent use.
+type eventStream struct {
+	partial string // Incomplete line that may be an event
+	passing bool   // The current line is plain text and already passed through
+	usage   *Usage
+}
+
+// Feed processes a chunk of stdout and returns its readable text.
+func (s *eventStream) Feed(chunk []byte) string {
+	var out strings.Builder
+	data := string(chunk)
+	for data != "" {
+		piece := data
+		if nl := strings.IndexByte(data, '\n'); nl >= 0 {
+			piece = data[:nl+1]
+		}
+		data = data[len(piece):]
+		complete := strings.HasSuffix(piece, "\n")
+
+		if s.passing || (s.partial == "" && !strings.HasPrefix(strings.TrimSpace(piece), "{") && strings.TrimSpace(piece) != "") {
+			out.WriteString(piece)
+			s.passing = !complete
+			continue
+		}
+		s.partial += piece
+		if complete {
+			out.WriteString(s.line(s.partial))
+			s.partial = ""
+		}
+	}
+	return out.String()
+}
+
+// Flush returns the readable text of a last line without a newline.
+func (s *eventStream) Flush() string {
+	line := s.partial
+	s.partial, s.passing = "", false
+	if line == "" {
+		return ""
+	}
+	return s.line(line)
+}
+
+// Usage returns the total usage seen, or nil if the output reported none.
+func (s *eventStream) Usage() *Usage {
+	return s.usage
+}
+
+// line decodes one line. Events give their text, tool call or error;
+// anything else is returned as is.
+func (s *eventStream) line(line string) string {
+	var ev openCodeEvent
+	if err := json.Unmarshal([]byte(strings.TrimSpace(line)), &ev); err != nil {
+		return line
+	}
+	part := ev.eventPart
+	if ev.Part != nil {
+		part = *ev.Part
+	}
+	if ev.Type == "" && part.Tokens == nil {
+		return line // JSON, but not an event
+	}
+
+	if part.Tokens != nil {
+		if s.usage == nil {
+			s.usage = &Usage{}
+		}
+		s.usage.add(&Usage{
+			InputTokens:      part.Tokens.Input,
+			OutputTokens:     part.Tokens.Output,
+			ReasoningTokens:  part.Tokens.Reasoning,
+			CacheReadTokens:  part.Tokens.Cache.Read,
+			CacheWriteTokens: part.Tokens.Cache.Write,
+			Cost:             part.Cost,
+		})
+	}
+
+	switch {
+	case ev.Type == "text" && part.Text != "":
+		return strings.TrimSuffix(part.Text, "\n") + "\n"
+	case ev.Type == "tool_use" && part.Tool != "":
+		if part.State != nil && part.State.Title != "" {
+			return fmt.Sprintf("[%s] %s\n", part.Tool, part.State.Title)
+		}
+		return fmt.Sprintf("[%s]\n", part.Tool)
+	case ev.Type == "error" && ev.Error != nil:
+		message := ev.Error.Data.Message
+		if message == "" {
+			message = ev.Error.Name
+		}
+		return "Error: " + message + "\n"
+	}
+	return ""
+}
+
+// readableOutput decodes captured stdout, such as the tail kept in
+// opencode.ExecResult.Output, for completion and failure checks.
+func readableOutput(output string) string {
+	var s eventStream
+	return s.Feed([]byte(output)) + s.Flush()
+}
@@ -0,0 +1,44 @@
+package journey
+
+import "testing"
+
+func TestEventStream(t *testing.T) {
+	s := &eventStream{}
+	if s.Usage() != nil {
+		t.Fatal("usage reported before any output")
+	}
+
+	// Events split across chunks, plain text and events without usage are handled
+	var out string
+	out += s.Feed([]byte("Thinking...\n{\"type\":\"step_finish\",\"part\":{\"tokens\":{\"input\":100,\"out"))
+	out += s.Feed([]byte("put\":20,\"reasoning\":5,\"cache\":{\"read\":7,\"write\":0}},\"cost\":0.01}}\n"))
+	out += s.Feed([]byte("{\"type\":\"text\",\"part\":{\"type\":\"text\",\"text\":\"PRD complete\"}}\n"))
+	out += s.Feed([]byte("{\"type\":\"tool_use\",\"part\":{\"tool\":\"write\",\"state\":{\"status\":\"completed\",\"title\":\"prd.md\"}}}\n"))
+	out += s.Feed([]byte("{\"type\":\"error\",\"error\":{\"name\":\"APIError\",\"data\":{\"message\":\"rate limited\"}}}\n"))
+	out += s.Feed([]byte(`{"tokens":{"input":50,"output":10},"cost":0.005}`))
+	out += s.Feed([]byte("\nChoose one:"))
+	out += s.Flush()
+
+	want := "Thinking...\nPRD complete\n[write] prd.md\nError: rate limited\nChoose one:"
+	if out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+	u := s.Usage()
+	if u == nil {
+		t.Fatal("expected usage")
+	}
+	if u.InputTokens != 150 || u.OutputTokens != 30 || u.ReasoningTokens != 5 || u.CacheReadTokens != 7 {
+		t.Errorf("usage = %+v", u)
+	}
+	if u.Cost < 0.0149 || u.Cost > 0.0151 {
+		t.Errorf("Cost = %f, want 0.015", u.Cost)
+	}
+}
+
+func TestReadableOutput(t *testing.T) {
+	// A captured tail may start in the middle of an event
+	got := readableOutput("t\":\"x\"}}\n{\"type\":\"text\",\"part\":{\"text\":\"done\"}}\n{\"json\":true}\n")
+	if want := "t\":\"x\"}}\ndone\n{\"json\":true}\n"; got != want {
+		t.Errorf("readableOutput = %q, want %q", got, want)
+	}
+}
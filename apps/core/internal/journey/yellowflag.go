@@ -0,0 +1,200 @@
+package journey
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxQuestionLength bounds what counts as a prompt line; longer lines are prose.
+const maxQuestionLength = 300
+
+// recentLineCount is how many output lines are kept as context for a flag.
+const recentLineCount = 10
+
+// YellowFlagKind is what made the detector ask for a human decision.
+type YellowFlagKind string
+
+const (
+	YellowFlagQuestion YellowFlagKind = "question" // A direct question ending the output
+	YellowFlagChoice   YellowFlagKind = "choice"   // A numbered or lettered menu followed by a prompt
+	YellowFlagTrigger  YellowFlagKind = "trigger"  // A configured regex trigger
+)
+
+// FeedbackDelivery is how the user's answer reaches the AI.
+type FeedbackDelivery string
+
+const (
+	DeliverStdin   FeedbackDelivery = "stdin"   // Written to the running process
+	DeliverContext FeedbackDelivery = "context" // Added to the next attempt's context
+)
+
+// YellowFlag is a moment where the AI needs a human decision (Epic 4).
+type YellowFlag struct {
+	ID         string           `json:"id"`
+	Kind       YellowFlagKind   `json:"kind"`
+	StepIndex  int              `json:"stepIndex"`
+	Attempt    int              `json:"attempt"`
+	Question   string           `json:"question"`
+	Options    []string         `json:"options,omitempty"`
+	Trigger    string           `json:"trigger,omitempty"` // Matching pattern for trigger flags
+	Excerpt    string           `json:"excerpt,omitempty"` // Output leading up to the flag
+	Delivery   FeedbackDelivery `json:"delivery"`
+	RaisedAt   time.Time        `json:"raisedAt"`
+	Answer     string           `json:"answer,omitempty"`
+	AnsweredAt *time.Time       `json:"answeredAt,omitempty"`
+}
+
+// feedback formats the answer for the next attempt's context.
+func (f *YellowFlag) feedback() string {
+	return fmt.Sprintf("You asked: %q. Answer: %s", f.Question, strings.TrimSpace(f.Answer))
+}
+
+var (
+	// optionPattern matches menu entries like "1. Foo", "2) Bar", "[C] Continue" or "- **[A]** Advanced".
+	optionPattern = regexp.MustCompile(`^\s*(?:[-*]\s+)?(?:\*\*)?(?:\[([A-Za-z0-9]{1,2})\]|(\d{1,2})[.)])(?:\*\*)?\s+(.+?)\s*$`)
+
+	// promptPattern matches lines that ask for a selection without a question mark.
+	promptPattern = regexp.MustCompile(`(?i)\b(select|choose|pick|enter|type|reply|respond)\b.*[:>]\s*$`)
+
+	// questionPattern matches a line ending with a question mark, ignoring markdown emphasis.
+	questionPattern = regexp.MustCompile(`\?\s*(?:\*\*|\*|_)?\s*$`)
+)
+
+// YellowFlagDetector watches streamed OpenCode output for moments that need
+// a human decision. It is safe for concurrent use: output is fed from the
+// process while answers reset it from the caller.
+type YellowFlagDetector struct {
+	triggers []*regexp.Regexp
+
+	mu      sync.Mutex
+	partial string   // Incomplete last line
+	recent  []string // Last complete lines, for the excerpt
+	options []string // Menu entries seen since the last prose line
+	inFence bool
+}
+
+// NewYellowFlagDetector creates a detector with extra regex triggers.
+func NewYellowFlagDetector(patterns []string) (*YellowFlagDetector, error) {
+	d := &YellowFlagDetector{}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid yellow flag pattern %q: %w", p, err)
+		}
+		d.triggers = append(d.triggers, re)
+	}
+	return d, nil
+}
+
+// Feed processes a chunk of output and returns a flag if one was detected.
+// Triggers and menus are checked on every line. A plain question only counts
+// when it is the last line of the chunk, since a process waiting for an
+// answer stops writing.
+func (d *YellowFlagDetector) Feed(chunk []byte) *YellowFlag {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	text := d.partial + strings.ReplaceAll(string(chunk), "\r\n", "\n")
+	lines := strings.Split(text, "\n")
+	d.partial = lines[len(lines)-1]
+	complete := lines[:len(lines)-1]
+
+	last := lastNonBlank(complete)
+	for i, line := range complete {
+		if flag := d.line(line, i == last && strings.TrimSpace(d.partial) == ""); flag != nil {
+			return flag
+		}
+	}
+
+	// A prompt without a trailing newline is the process waiting for input
+	if p := strings.TrimSpace(d.partial); p != "" && (questionPattern.MatchString(p) || promptPattern.MatchString(p)) {
+		d.partial = ""
+		return d.line(p, true)
+	}
+	return nil
+}
+
+// Reset clears buffered output, e.g. after the user answered a flag.
+func (d *YellowFlagDetector) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.partial = ""
+	d.recent = nil
+	d.options = nil
+	d.inFence = false
+}
+
+// line checks one complete output line. last reports whether it ends the output so far.
+func (d *YellowFlagDetector) line(line string, last bool) *YellowFlag {
+	trimmed := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmed, "```") {
+		d.inFence = !d.inFence
+	}
+	d.remember(trimmed)
+	if trimmed == "" || d.inFence {
+		return nil
+	}
+
+	for _, re := range d.triggers {
+		if re.MatchString(trimmed) {
+			return d.flag(YellowFlagTrigger, trimmed, re.String())
+		}
+	}
+
+	if m := optionPattern.FindStringSubmatch(trimmed); m != nil {
+		key := m[1] + m[2]
+		d.options = append(d.options, key+": "+strings.TrimRight(m[3], "*"))
+		return nil
+	}
+
+	if len(trimmed) > maxQuestionLength {
+		d.options = nil
+		return nil
+	}
+
+	isQuestion := questionPattern.MatchString(trimmed)
+	if len(d.options) >= 2 && (isQuestion || promptPattern.MatchString(trimmed)) {
+		return d.flag(YellowFlagChoice, trimmed, "")
+	}
+	d.options = nil
+
+	if isQuestion && last && !strings.HasPrefix(trimmed, "#") {
+		return d.flag(YellowFlagQuestion, trimmed, "")
+	}
+	return nil
+}
+
+// remember keeps the last recentLineCount lines for the excerpt.
+func (d *YellowFlagDetector) remember(line string) {
+	d.recent = append(d.recent, line)
+	if len(d.recent) > recentLineCount {
+		d.recent = d.recent[len(d.recent)-recentLineCount:]
+	}
+}
+
+// flag builds a detected flag and resets the menu state.
+func (d *YellowFlagDetector) flag(kind YellowFlagKind, question, trigger string) *YellowFlag {
+	f := &YellowFlag{
+		Kind:     kind,
+		Question: strings.Trim(question, "*_ "),
+		Trigger:  trigger,
+		Excerpt:  strings.TrimSpace(strings.Join(d.recent, "\n")),
+	}
+	if kind == YellowFlagChoice {
+		f.Options = d.options
+	}
+	d.options = nil
+	return f
+}
+
+// lastNonBlank returns the index of the last non-blank line, or -1.
+func lastNonBlank(lines []string) int {
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.TrimSpace(lines[i]) != "" {
+			return i
+		}
+	}
+	return -1
+}
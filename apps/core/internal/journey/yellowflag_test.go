@@ -0,0 +1,101 @@
+package journey
+
+import (
+	"testing"
+)
+
+func TestYellowFlagDetector(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		chunks   []string
+		want     YellowFlagKind // Empty: no flag
+		question string
+		options  int
+	}{
+		{
+			name:     "trailing question",
+			chunks:   []string{"I drafted the goals.\nShould I include a competitor analysis?\n"},
+			want:     YellowFlagQuestion,
+			question: "Should I include a competitor analysis?",
+		},
+		{
+			name:   "question followed by more output",
+			chunks: []string{"Why does this matter?\nBecause users churn.\n"},
+		},
+		{
+			name:     "numbered menu",
+			chunks:   []string{"Next steps:\n1. Continue\n2) Revise goals\n", "\nSelect an option:"},
+			want:     YellowFlagChoice,
+			question: "Select an option:",
+			options:  2,
+		},
+		{
+			name:     "bmad letter menu",
+			chunks:   []string{"**[A]** Advanced Elicitation\n**[P]** Party Mode\n**[C]** Continue\nWhat would you like to do?\n"},
+			want:     YellowFlagChoice,
+			question: "What would you like to do?",
+			options:  3,
+		},
+		{
+			name:   "question in code fence",
+			chunks: []string{"```\nif ok?\n```\n"},
+		},
+		{
+			name:   "markdown heading",
+			chunks: []string{"## What is the problem?\n"},
+		},
+		{
+			name:     "split prompt without newline",
+			chunks:   []string{"Do you want to ", "proceed?"},
+			want:     YellowFlagQuestion,
+			question: "Do you want to proceed?",
+		},
+		{
+			name:     "configured trigger",
+			patterns: []string{`(?i)awaiting approval`},
+			chunks:   []string{"Draft saved. AWAITING APPROVAL from the architect.\nContinuing.\n"},
+			want:     YellowFlagTrigger,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := NewYellowFlagDetector(tt.patterns)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var flag *YellowFlag
+			for _, c := range tt.chunks {
+				if f := d.Feed([]byte(c)); f != nil && flag == nil {
+					flag = f
+				}
+			}
+
+			if tt.want == "" {
+				if flag != nil {
+					t.Errorf("unexpected flag %+v", flag)
+				}
+				return
+			}
+			if flag == nil {
+				t.Fatalf("no flag detected, want %s", tt.want)
+			}
+			if flag.Kind != tt.want {
+				t.Errorf("Kind = %s, want %s", flag.Kind, tt.want)
+			}
+			if tt.question != "" && flag.Question != tt.question {
+				t.Errorf("Question = %q, want %q", flag.Question, tt.question)
+			}
+			if len(flag.Options) != tt.options {
+				t.Errorf("Options = %v, want %d", flag.Options, tt.options)
+			}
+		})
+	}
+}
+
+func TestNewYellowFlagDetector_InvalidPattern(t *testing.T) {
+	if _, err := NewYellowFlagDetector([]string{"("}); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
@@ -3,7 +3,6 @@ package network
 
 import (
 	"context"
-	"net"
 	"sync"
 	"time"
 )
@@ -16,15 +15,19 @@ const (
 	StatusOnline Status = "online"
 	// StatusOffline indicates network is unavailable
 	StatusOffline Status = "offline"
+	// StatusDegraded indicates some probes failed, e.g. a captive portal or
+	// an unreachable AI provider
+	StatusDegraded Status = "degraded"
 	// StatusChecking indicates status check is in progress
 	StatusChecking Status = "checking"
 )
 
 // NetworkStatus represents the result of a network connectivity check.
 type NetworkStatus struct {
-	Status      Status    `json:"status"`
-	LastChecked time.Time `json:"lastChecked"`
-	Latency     int64     `json:"latency,omitempty"` // milliseconds
+	Status      Status        `json:"status"`
+	LastChecked time.Time     `json:"lastChecked"`
+	Latency     int64         `json:"latency,omitempty"` // milliseconds
+	Probes      []ProbeResult `json:"probes,omitempty"`  // Per-probe results of the last check
 }
 
 // Monitor continuously monitors network connectivity status.
@@ -34,6 +37,9 @@ type Monitor struct {
 	interval time.Duration
 	onChange func(old, new Status)
 	stopCh   chan struct{}
+	resetCh  chan struct{}  // Restarts the current wait after SetInterval
+	probes   func() []Probe // Probes of the next check; nil uses DefaultProbes
+	timeout  time.Duration  // Per probe
 }
 
 // NewMonitor creates a new network monitor that checks connectivity at the given interval.
@@ -44,6 +50,43 @@ func NewMonitor(interval time.Duration, onChange func(old, new Status)) *Monitor
 		interval: interval,
 		onChange: onChange,
 		stopCh:   make(chan struct{}),
+		resetCh:  make(chan struct{}, 1),
+		timeout:  DefaultProbeTimeout,
+	}
+}
+
+// SetProbes sets the function returning the probes of each check, so
+// configuration changes apply from the next check. If it returns no probes,
+// DefaultProbes are used.
+func (m *Monitor) SetProbes(probes func() []Probe) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.probes = probes
+}
+
+// SetProbeTimeout bounds each probe of a check.
+func (m *Monitor) SetProbeTimeout(timeout time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.timeout = timeout
+}
+
+// SetInterval changes the time between checks. The current wait restarts
+// with the new interval. Non-positive intervals are ignored.
+func (m *Monitor) SetInterval(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	m.mu.Lock()
+	changed := m.interval != interval
+	m.interval = interval
+	m.mu.Unlock()
+
+	if changed {
+		select {
+		case m.resetCh <- struct{}{}:
+		default: // A restart is already pending
+		}
 	}
 }
 
@@ -53,16 +96,22 @@ func (m *Monitor) Start(ctx context.Context) {
 	// Initial check
 	m.check()
 
-	ticker := time.NewTicker(m.interval)
-	defer ticker.Stop()
-
 	for {
+		// Read on every wait so SetInterval applies without a restart
+		m.mu.RLock()
+		timer := time.NewTimer(m.interval)
+		m.mu.RUnlock()
+
 		select {
-		case <-ticker.C:
+		case <-timer.C:
 			m.check()
+		case <-m.resetCh:
+			timer.Stop()
 		case <-ctx.Done():
+			timer.Stop()
 			return
 		case <-m.stopCh:
+			timer.Stop()
 			return
 		}
 	}
@@ -77,22 +126,29 @@ func (m *Monitor) Stop() {
 func (m *Monitor) check() {
 	m.mu.Lock()
 	oldStatus := m.status.Status
+	source, timeout := m.probes, m.timeout
 	m.mu.Unlock()
 
+	var probes []Probe
+	if source != nil {
+		probes = source()
+	}
+	if len(probes) == 0 {
+		probes = DefaultProbes()
+	}
+
 	start := time.Now()
-	online := isOnline()
+	results := RunProbes(context.Background(), probes, timeout)
 	latency := time.Since(start).Milliseconds()
 
-	newStatus := StatusOffline
-	if online {
-		newStatus = StatusOnline
-	}
+	newStatus := Evaluate(results)
 
 	m.mu.Lock()
 	m.status = NetworkStatus{
 		Status:      newStatus,
 		LastChecked: time.Now(),
 		Latency:     latency,
+		Probes:      results,
 	}
 	m.mu.Unlock()
 
@@ -112,20 +168,6 @@ func (m *Monitor) GetStatus() NetworkStatus {
 	return m.status
 }
 
-// isOnline checks if the system has network connectivity.
-// It uses DNS lookups to well-known hosts as a connectivity test.
-func isOnline() bool {
-	// Method 1: DNS lookup to Google DNS (fast, reliable)
-	_, err := net.LookupHost("dns.google")
-	if err == nil {
-		return true
-	}
-
-	// Method 2: Fallback to another DNS provider
-	_, err = net.LookupHost("cloudflare.com")
-	return err == nil
-}
-
 // DebouncedMonitor wraps Monitor with debouncing for status change notifications.
 // This prevents rapid status changes from triggering too many callbacks.
 type DebouncedMonitor struct {
@@ -150,6 +192,13 @@ func NewDebouncedMonitor(interval, debounce time.Duration, onChange func(old, ne
 	return dm
 }
 
+// SetDebounce changes the debounce window of later status changes.
+func (dm *DebouncedMonitor) SetDebounce(window time.Duration) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	dm.debounceWindow = window
+}
+
 // debouncedNotify delays the onChange callback by the debounce window.
 // If another change occurs within the window, the timer is reset.
 func (dm *DebouncedMonitor) debouncedNotify(old, new Status, callback func(Status, Status)) {
@@ -103,6 +103,31 @@ func TestMonitor_Stop(t *testing.T) {
 	time.Sleep(50 * time.Millisecond)
 }
 
+// TestMonitor_SetInterval verifies a new interval applies to the running monitor
+func TestMonitor_SetInterval(t *testing.T) {
+	checks := make(chan struct{}, 10)
+	m := NewMonitor(time.Hour, nil)
+	m.SetProbes(func() []Probe {
+		select {
+		case checks <- struct{}{}:
+		default:
+		}
+		return []Probe{&stubProbe{name: "local"}}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go m.Start(ctx)
+
+	<-checks // Initial check
+	m.SetInterval(20 * time.Millisecond)
+	select {
+	case <-checks:
+	case <-time.After(2 * time.Second):
+		t.Fatal("no check after shortening the interval")
+	}
+}
+
 // TestMonitor_OnChange verifies onChange callback is called on status change
 func TestMonitor_OnChange(t *testing.T) {
 	var callbackNew Status
@@ -129,15 +154,6 @@ func TestMonitor_OnChange(t *testing.T) {
 	}
 }
 
-// TestIsOnline verifies connectivity check function
-func TestIsOnline(t *testing.T) {
-	// This test may fail if network is actually offline
-	// We test that the function executes without panic
-	result := isOnline()
-	t.Logf("isOnline() returned: %v", result)
-	// We can't assert true/false as it depends on actual network
-}
-
 // TestNetworkStatus_Latency verifies latency is captured
 func TestNetworkStatus_Latency(t *testing.T) {
 	m := NewMonitor(100*time.Millisecond, nil)
@@ -0,0 +1,228 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ProbeKind names the built-in probe types.
+type ProbeKind string
+
+const (
+	ProbeDNS      ProbeKind = "dns"      // Resolve a host name
+	ProbeTCP      ProbeKind = "tcp"      // Connect to host:port
+	ProbeHTTP     ProbeKind = "http"     // HEAD a URL and expect a status
+	ProbeProvider ProbeKind = "provider" // HEAD an AI provider endpoint; any response counts
+)
+
+// DefaultProbeTimeout bounds each probe of a check.
+const DefaultProbeTimeout = 5 * time.Second
+
+// Probe is one reachability test. Implementations must return promptly
+// when ctx is done.
+type Probe interface {
+	Name() string
+	Kind() ProbeKind
+	Target() string
+	Check(ctx context.Context) error
+}
+
+// ProbeResult is the outcome of one probe.
+type ProbeResult struct {
+	Name    string    `json:"name"`
+	Kind    ProbeKind `json:"kind"`
+	Target  string    `json:"target"`
+	OK      bool      `json:"ok"`
+	Latency int64     `json:"latency"` // milliseconds
+	Error   string    `json:"error,omitempty"`
+}
+
+// DNSProbe resolves Host.
+type DNSProbe struct {
+	ProbeName string
+	Host      string
+}
+
+func (p *DNSProbe) Name() string    { return p.ProbeName }
+func (p *DNSProbe) Kind() ProbeKind { return ProbeDNS }
+func (p *DNSProbe) Target() string  { return p.Host }
+
+// Check resolves the host.
+func (p *DNSProbe) Check(ctx context.Context) error {
+	_, err := net.DefaultResolver.LookupHost(ctx, p.Host)
+	return err
+}
+
+// TCPProbe connects to Address (host:port).
+type TCPProbe struct {
+	ProbeName string
+	Address   string
+}
+
+func (p *TCPProbe) Name() string    { return p.ProbeName }
+func (p *TCPProbe) Kind() ProbeKind { return ProbeTCP }
+func (p *TCPProbe) Target() string  { return p.Address }
+
+// Check opens and closes a connection.
+func (p *TCPProbe) Check(ctx context.Context) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", p.Address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// HTTPProbe sends a HEAD request to URL without following redirects. A
+// captive portal answers with a redirect or its own page, so the status
+// must equal ExpectStatus (default 200). With AnyStatus every response
+// counts, which suits API endpoints that answer 401 or 404 without
+// credentials.
+type HTTPProbe struct {
+	ProbeName    string
+	URL          string
+	ExpectStatus int
+	AnyStatus    bool
+	Client       *http.Client // Defaults to a client that does not follow redirects
+}
+
+func (p *HTTPProbe) Name() string { return p.ProbeName }
+func (p *HTTPProbe) Kind() ProbeKind {
+	if p.AnyStatus {
+		return ProbeProvider
+	}
+	return ProbeHTTP
+}
+func (p *HTTPProbe) Target() string { return p.URL }
+
+// noRedirectClient reports redirects as responses instead of following them.
+var noRedirectClient = &http.Client{
+	CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+}
+
+// Check sends the request and compares the status.
+func (p *HTTPProbe) Check(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, p.URL, nil)
+	if err != nil {
+		return err
+	}
+	client := p.Client
+	if client == nil {
+		client = noRedirectClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if p.AnyStatus {
+		return nil
+	}
+	want := p.ExpectStatus
+	if want == 0 {
+		want = http.StatusOK
+	}
+	if resp.StatusCode != want {
+		return fmt.Errorf("got status %d, want %d", resp.StatusCode, want)
+	}
+	return nil
+}
+
+// ProbeConfig describes a probe in settings.
+type ProbeConfig struct {
+	Kind         ProbeKind
+	Target       string // Host for dns, host:port for tcp, URL for http and provider
+	ExpectStatus int    // http only; default 200
+}
+
+// NewProbe builds a built-in probe from its configuration.
+func NewProbe(name string, cfg ProbeConfig) (Probe, error) {
+	if cfg.Target == "" {
+		return nil, fmt.Errorf("probe %q: target is required", name)
+	}
+	switch cfg.Kind {
+	case ProbeDNS:
+		return &DNSProbe{ProbeName: name, Host: cfg.Target}, nil
+	case ProbeTCP:
+		if _, _, err := net.SplitHostPort(cfg.Target); err != nil {
+			return nil, fmt.Errorf("probe %q: %v", name, err)
+		}
+		return &TCPProbe{ProbeName: name, Address: cfg.Target}, nil
+	case ProbeHTTP, ProbeProvider:
+		u, err := url.Parse(cfg.Target)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("probe %q: target must be an http or https URL", name)
+		}
+		return &HTTPProbe{ProbeName: name, URL: cfg.Target, ExpectStatus: cfg.ExpectStatus, AnyStatus: cfg.Kind == ProbeProvider}, nil
+	default:
+		return nil, fmt.Errorf("probe %q: unknown kind %q (dns, tcp, http or provider)", name, cfg.Kind)
+	}
+}
+
+// DefaultProbes are used when no probes are configured: a name lookup,
+// and a captive-portal check that only passes on the open internet.
+func DefaultProbes() []Probe {
+	return []Probe{
+		&DNSProbe{ProbeName: "dns", Host: "dns.google"},
+		&HTTPProbe{ProbeName: "internet", URL: "http://cp.cloudflare.com/generate_204", ExpectStatus: http.StatusNoContent},
+	}
+}
+
+// RunProbes runs the probes concurrently, each bounded by timeout, and
+// returns their results in the order given.
+func RunProbes(ctx context.Context, probes []Probe, timeout time.Duration) []ProbeResult {
+	if timeout <= 0 {
+		timeout = DefaultProbeTimeout
+	}
+	results := make([]ProbeResult, len(probes))
+	var wg sync.WaitGroup
+	for i, p := range probes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := p.Check(pctx)
+			results[i] = ProbeResult{
+				Name:    p.Name(),
+				Kind:    p.Kind(),
+				Target:  p.Target(),
+				OK:      err == nil,
+				Latency: time.Since(start).Milliseconds(),
+			}
+			if err != nil {
+				results[i].Error = err.Error()
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// Evaluate derives the overall status from probe results: online if all
+// passed, offline if none did, degraded otherwise, such as behind a
+// captive portal or when only the AI provider is unreachable.
+func Evaluate(results []ProbeResult) Status {
+	passed := 0
+	for _, r := range results {
+		if r.OK {
+			passed++
+		}
+	}
+	switch {
+	case len(results) > 0 && passed == len(results):
+		return StatusOnline
+	case passed == 0:
+		return StatusOffline
+	default:
+		return StatusDegraded
+	}
+}
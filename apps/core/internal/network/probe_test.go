@@ -0,0 +1,176 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// stubProbe is an injectable probe with a fixed outcome.
+type stubProbe struct {
+	name string
+	err  error
+}
+
+func (p *stubProbe) Name() string                  { return p.name }
+func (p *stubProbe) Kind() ProbeKind               { return "stub" }
+func (p *stubProbe) Target() string                { return "local" }
+func (p *stubProbe) Check(_ context.Context) error { return p.err }
+
+// TestHTTPProbe verifies status matching against a local server
+func TestHTTPProbe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/generate_204":
+			w.WriteHeader(http.StatusNoContent)
+		case "/portal":
+			http.Redirect(w, r, "/login", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		probe   *HTTPProbe
+		wantErr bool
+	}{
+		{"expected status", &HTTPProbe{URL: srv.URL + "/generate_204", ExpectStatus: 204}, false},
+		{"captive portal redirect", &HTTPProbe{URL: srv.URL + "/portal", ExpectStatus: 204}, true},
+		{"default expects 200", &HTTPProbe{URL: srv.URL + "/api"}, true},
+		{"provider accepts any status", &HTTPProbe{URL: srv.URL + "/api", AnyStatus: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.probe.Check(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestTCPProbe verifies connecting to a local listener
+func TestTCPProbe(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	addr := ln.Addr().String()
+
+	probe := &TCPProbe{Address: addr}
+	if err := probe.Check(context.Background()); err != nil {
+		t.Errorf("Check() against open port failed: %v", err)
+	}
+
+	ln.Close()
+	if err := probe.Check(context.Background()); err == nil {
+		t.Error("Check() against closed port should fail")
+	}
+}
+
+// TestNewProbe verifies probe configuration
+func TestNewProbe(t *testing.T) {
+	tests := []struct {
+		cfg     ProbeConfig
+		want    ProbeKind
+		wantErr bool
+	}{
+		{ProbeConfig{Kind: ProbeDNS, Target: "example.com"}, ProbeDNS, false},
+		{ProbeConfig{Kind: ProbeTCP, Target: "127.0.0.1:443"}, ProbeTCP, false},
+		{ProbeConfig{Kind: ProbeHTTP, Target: "https://example.com/", ExpectStatus: 204}, ProbeHTTP, false},
+		{ProbeConfig{Kind: ProbeProvider, Target: "https://api.example.com"}, ProbeProvider, false},
+		{ProbeConfig{Kind: ProbeTCP, Target: "127.0.0.1"}, "", true},
+		{ProbeConfig{Kind: ProbeHTTP, Target: "ftp://example.com"}, "", true},
+		{ProbeConfig{Kind: ProbeDNS}, "", true},
+		{ProbeConfig{Kind: "ping", Target: "example.com"}, "", true},
+	}
+	for _, tt := range tests {
+		probe, err := NewProbe("p", tt.cfg)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NewProbe(%+v) error = %v, wantErr %v", tt.cfg, err, tt.wantErr)
+			continue
+		}
+		if err == nil && probe.Kind() != tt.want {
+			t.Errorf("NewProbe(%+v) kind = %v, want %v", tt.cfg, probe.Kind(), tt.want)
+		}
+	}
+}
+
+// TestRunProbes_Evaluate verifies per-probe results and the overall status
+func TestRunProbes_Evaluate(t *testing.T) {
+	ok := &stubProbe{name: "ok"}
+	failed := &stubProbe{name: "failed", err: errors.New("unreachable")}
+
+	results := RunProbes(context.Background(), []Probe{ok, failed}, time.Second)
+	if len(results) != 2 || !results[0].OK || results[1].OK || results[1].Error != "unreachable" {
+		t.Fatalf("RunProbes() = %+v", results)
+	}
+
+	tests := []struct {
+		probes []Probe
+		want   Status
+	}{
+		{[]Probe{ok, ok}, StatusOnline},
+		{[]Probe{ok, failed}, StatusDegraded},
+		{[]Probe{failed, failed}, StatusOffline},
+	}
+	for _, tt := range tests {
+		if got := Evaluate(RunProbes(context.Background(), tt.probes, time.Second)); got != tt.want {
+			t.Errorf("Evaluate() = %v, want %v", got, tt.want)
+		}
+	}
+}
+
+// TestRunProbes_Timeout verifies slow probes are cut off
+func TestRunProbes_Timeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	start := time.Now()
+	results := RunProbes(context.Background(), []Probe{&HTTPProbe{ProbeName: "slow", URL: srv.URL}}, 100*time.Millisecond)
+	if results[0].OK {
+		t.Error("Expected slow probe to fail")
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Errorf("RunProbes took %v, want it bounded by the timeout", time.Since(start))
+	}
+}
+
+// TestMonitor_Probes verifies the monitor reports injected probes
+func TestMonitor_Probes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	var changes []Status
+	m := NewMonitor(time.Hour, func(old, new Status) { changes = append(changes, new) })
+	probes := []Probe{&HTTPProbe{ProbeName: "local", URL: srv.URL, ExpectStatus: 204}}
+	m.SetProbes(func() []Probe { return probes })
+
+	m.check()
+	status := m.GetStatus()
+	if status.Status != StatusOnline || len(status.Probes) != 1 || status.Probes[0].Name != "local" {
+		t.Fatalf("GetStatus() = %+v", status)
+	}
+
+	probes = append(probes, &stubProbe{name: "provider", err: errors.New("unreachable")})
+	m.check()
+	if status := m.GetStatus(); status.Status != StatusDegraded {
+		t.Errorf("Status = %v, want degraded", status.Status)
+	}
+	if len(changes) != 1 || changes[0] != StatusDegraded {
+		t.Errorf("onChange calls = %v, want [degraded]", changes)
+	}
+}
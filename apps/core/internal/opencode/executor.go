@@ -0,0 +1,556 @@
+package opencode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultGracePeriod is the time between SIGTERM and SIGKILL when stopping a process.
+	// Source: architecture.md "Failure Detection Mechanisms" - SIGTERM, wait 5s, SIGKILL
+	DefaultGracePeriod = 5 * time.Second
+
+	// outputTailSize is how much of each stream is kept in memory for diagnosis.
+	outputTailSize = 256 * 1024
+)
+
+// Output stream names used in OnOutput callbacks and opencode.output events.
+const (
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+)
+
+// profileNamePattern matches profile names accepted by GetProfiles (alias opencode-{name}).
+var profileNamePattern = regexp.MustCompile(`^\w+$`)
+
+// ErrInvalidProfile is returned when a profile name is not a valid alias suffix.
+var ErrInvalidProfile = errors.New("invalid profile name")
+
+// ExecRequest describes a single OpenCode invocation for a journey step.
+type ExecRequest struct {
+	JourneyID string
+	StepIndex int
+	Dir       string        // Working directory (project root)
+	Profile   string        // OpenCode profile; "" or "default" runs plain opencode
+	Args      []string      // Arguments appended after the executor's base args
+	Env       []string      // Extra environment variables (KEY=value)
+	Timeout   time.Duration // Overall step timeout (0 = no timeout)
+	LogPath   string        // Optional file receiving the full output
+
+	// TranscriptPath is an optional JSON lines file recording output and input in order.
+	TranscriptPath string
+
+	// OnOutput is called for every chunk read from stdout or stderr.
+	OnOutput func(stream string, chunk []byte)
+	// OnInput is called for every chunk written to the process.
+	OnInput func(data []byte)
+
+	// Monitoring and input of this request; zero values use the executor's.
+	HeartbeatInterval time.Duration
+	StallIntervals    int
+	StallAction       StallAction
+	InputMode         InputMode
+}
+
+// ExecResult is the outcome of an OpenCode invocation.
+type ExecResult struct {
+	JourneyID  string    `json:"journeyId"`
+	StepIndex  int       `json:"stepIndex"`
+	ExitCode   int       `json:"exitCode"`
+	Output     string    `json:"output"` // Tail of stdout
+	Stderr     string    `json:"stderr"` // Tail of stderr
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	DurationMs int64     `json:"durationMs"`
+	TimedOut   bool      `json:"timedOut"`
+	Stalled    bool      `json:"stalled"`
+	Cancelled  bool      `json:"cancelled"`
+	Error      string    `json:"error,omitempty"`
+
+	StallAction StallAction `json:"stallAction,omitempty"` // Action that stopped a stalled process
+}
+
+// Executor spawns OpenCode CLI processes for journey steps.
+type Executor struct {
+	// Command is the OpenCode binary (default "opencode").
+	Command string
+	// BaseArgs are passed before every request's Args (default ["run"]).
+	BaseArgs []string
+	// GracePeriod is the SIGTERM to SIGKILL delay.
+	GracePeriod time.Duration
+
+	// HeartbeatInterval enables the process monitor when non-zero.
+	HeartbeatInterval time.Duration
+	// StallIntervals is the number of heartbeats without progress before a stall is raised.
+	StallIntervals int
+	// StallAction is run automatically when a stall is raised.
+	StallAction StallAction
+
+	// InputMode selects how processes receive input via SendInput (default off).
+	InputMode InputMode
+
+	// Emit sends events (opencode.heartbeat, journey.stalled) to the client.
+	Emit func(event string, data interface{})
+
+	mu      sync.Mutex
+	running map[string]*Process
+}
+
+// NewExecutor creates an executor that runs "opencode run".
+func NewExecutor() *Executor {
+	return &Executor{
+		Command:        "opencode",
+		BaseArgs:       []string{"run"},
+		GracePeriod:    DefaultGracePeriod,
+		StallIntervals: 3,
+		StallAction:    StallActionWarn,
+		InputMode:      InputOff,
+		running:        make(map[string]*Process),
+	}
+}
+
+// processKey identifies a running process by journey and step.
+func processKey(journeyID string, stepIndex int) string {
+	return fmt.Sprintf("%s/%d", journeyID, stepIndex)
+}
+
+// withDefaults fills the monitoring and input fields a request leaves unset
+// from the executor.
+func (e *Executor) withDefaults(req ExecRequest) ExecRequest {
+	if req.HeartbeatInterval == 0 {
+		req.HeartbeatInterval = e.HeartbeatInterval
+	}
+	if req.StallIntervals == 0 {
+		req.StallIntervals = e.StallIntervals
+	}
+	if req.StallAction == "" {
+		req.StallAction = e.StallAction
+	}
+	if req.InputMode == "" {
+		req.InputMode = e.InputMode
+	}
+	return req
+}
+
+// command builds the exec.Cmd for a request, resolving the profile alias if needed.
+func (e *Executor) command(req ExecRequest) (*exec.Cmd, error) {
+	args := append(append([]string{}, e.BaseArgs...), req.Args...)
+
+	if req.Profile == "" || req.Profile == "default" {
+		return exec.Command(e.Command, args...), nil
+	}
+
+	// SECURITY: profiles are bash aliases; only the validated alias name is
+	// interpolated into the script, all arguments are passed positionally.
+	if !profileNamePattern.MatchString(req.Profile) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidProfile, req.Profile)
+	}
+	alias := "opencode-" + req.Profile
+	script := "shopt -s expand_aliases; source ~/.bash_aliases 2>/dev/null; " + alias + ` "$@"`
+	return exec.Command("bash", append([]string{"-c", script, alias}, args...)...), nil
+}
+
+// Start spawns the OpenCode process for a request and returns immediately.
+// The process is stopped when ctx is cancelled or the request timeout elapses.
+func (e *Executor) Start(ctx context.Context, req ExecRequest) (*Process, error) {
+	// ctx is watched by the supervisor goroutine rather than exec.CommandContext
+	// so that the whole process group receives SIGTERM before SIGKILL.
+	req = e.withDefaults(req)
+	cmd, err := e.command(req)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Dir = req.Dir
+	cmd.Env = append(os.Environ(), req.Env...)
+	setProcessGroup(cmd)
+
+	grace := e.GracePeriod
+	if grace <= 0 {
+		grace = DefaultGracePeriod
+	}
+	cmd.WaitDelay = grace
+
+	p := &Process{
+		req:    req,
+		cmd:    cmd,
+		grace:  grace,
+		stdout: newTailBuffer(outputTailSize),
+		stderr: newTailBuffer(outputTailSize),
+		done:   make(chan struct{}),
+	}
+	cmd.Stdout = &streamWriter{p: p, stream: StreamStdout}
+	cmd.Stderr = &streamWriter{p: p, stream: StreamStderr}
+
+	var tty *os.File
+	fail := func(err error) (*Process, error) {
+		p.closeFiles()
+		if tty != nil {
+			tty.Close()
+		}
+		return nil, err
+	}
+
+	if req.LogPath != "" {
+		if err := os.MkdirAll(filepath.Dir(req.LogPath), 0755); err != nil {
+			return fail(fmt.Errorf("creating log directory: %w", err))
+		}
+		logFile, err := os.OpenFile(req.LogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return fail(fmt.Errorf("opening step log: %w", err))
+		}
+		p.log = logFile
+	}
+	if req.TranscriptPath != "" {
+		t, err := openTranscript(req.TranscriptPath)
+		if err != nil {
+			return fail(err)
+		}
+		p.transcript = t
+	}
+
+	switch req.InputMode {
+	case InputPipe:
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			return fail(fmt.Errorf("opening stdin: %w", err))
+		}
+		p.stdin = stdin
+	case InputPTY:
+		master, t, err := openPTY()
+		if err != nil {
+			return fail(err)
+		}
+		tty = t
+		attachPTY(cmd, tty)
+		p.pty = master
+		p.ptyDone = make(chan struct{})
+		p.stdin = master
+	}
+
+	p.startedAt = time.Now()
+	p.lastOutput.Store(p.startedAt.UnixNano())
+	if err := cmd.Start(); err != nil {
+		return fail(fmt.Errorf("starting opencode: %w", err))
+	}
+	if tty != nil {
+		// The child holds its own copy; reads on the master end with EIO once it exits
+		tty.Close()
+		go p.readPTY()
+	}
+
+	e.track(p)
+	go p.wait(ctx, e)
+
+	return p, nil
+}
+
+// Run executes a request to completion under the process monitor. A
+// process stopped as stalled returns with Stalled set; whether the step is
+// run again is up to the caller's retry policy.
+func (e *Executor) Run(ctx context.Context, req ExecRequest) (*ExecResult, error) {
+	req = e.withDefaults(req)
+	p, err := e.Start(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.HeartbeatInterval > 0 {
+		monitor := NewProcessMonitor(p, req.HeartbeatInterval, req.StallIntervals)
+		monitor.OnHeartbeat = func(hb Heartbeat) { e.emit("opencode.heartbeat", hb) }
+		monitor.OnStall = func(hb Heartbeat) { e.handleStall(p, hb) }
+		go monitor.Run(ctx)
+	}
+
+	return p.Wait(), nil
+}
+
+// handleStall emits journey.stalled and runs the configured stall action.
+func (e *Executor) handleStall(p *Process, hb Heartbeat) {
+	action := p.req.StallAction
+	e.emit("journey.stalled", StallEvent{Heartbeat: hb, Action: action})
+
+	switch action {
+	case StallActionKill, StallActionRetry:
+		p.stalled.Store(true)
+		p.Stop()
+	}
+}
+
+// Get returns the running process for a journey step, if any.
+func (e *Executor) Get(journeyID string, stepIndex int) (*Process, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	p, ok := e.running[processKey(journeyID, stepIndex)]
+	return p, ok
+}
+
+// track registers a running process.
+func (e *Executor) track(p *Process) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.running == nil {
+		e.running = make(map[string]*Process)
+	}
+	e.running[processKey(p.req.JourneyID, p.req.StepIndex)] = p
+}
+
+// untrack removes a finished process.
+func (e *Executor) untrack(p *Process) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	key := processKey(p.req.JourneyID, p.req.StepIndex)
+	if e.running[key] == p {
+		delete(e.running, key)
+	}
+}
+
+// emit forwards an event if an emitter is configured.
+func (e *Executor) emit(event string, data interface{}) {
+	if e.Emit != nil {
+		e.Emit(event, data)
+	}
+}
+
+// Process is a running OpenCode invocation.
+type Process struct {
+	req       ExecRequest
+	cmd       *exec.Cmd
+	grace     time.Duration
+	startedAt time.Time
+	log       *os.File
+
+	transcript *transcript
+	stdin      io.Writer // Pipe or pty master; nil when input is off
+	inputMu    sync.Mutex
+	pty        *os.File
+	ptyDone    chan struct{} // Closed when all terminal output was read
+
+	stdout *tailBuffer
+	stderr *tailBuffer
+
+	lastOutput  atomic.Int64 // Unix nanoseconds of the last output byte
+	outputBytes atomic.Int64
+	timedOut    atomic.Bool
+	stalled     atomic.Bool
+	cancelled   atomic.Bool
+	stopOnce    sync.Once
+
+	done   chan struct{}
+	result *ExecResult
+}
+
+// PID returns the operating system process ID.
+func (p *Process) PID() int {
+	return p.cmd.Process.Pid
+}
+
+// Request returns the request the process was started with.
+func (p *Process) Request() ExecRequest {
+	return p.req
+}
+
+// StartedAt returns when the process was spawned.
+func (p *Process) StartedAt() time.Time {
+	return p.startedAt
+}
+
+// LastOutputAt returns when the last output byte was received.
+func (p *Process) LastOutputAt() time.Time {
+	return time.Unix(0, p.lastOutput.Load())
+}
+
+// OutputBytes returns the total number of output bytes received so far.
+func (p *Process) OutputBytes() int64 {
+	return p.outputBytes.Load()
+}
+
+// Done is closed when the process has exited and its result is available.
+func (p *Process) Done() <-chan struct{} {
+	return p.done
+}
+
+// Wait blocks until the process exits and returns its result.
+func (p *Process) Wait() *ExecResult {
+	<-p.done
+	return p.result
+}
+
+// Stop terminates the process group: SIGTERM first, SIGKILL after the grace period.
+func (p *Process) Stop() {
+	p.stopOnce.Do(func() {
+		terminateProcessGroup(p.cmd)
+		go func() {
+			select {
+			case <-p.done:
+			case <-time.After(p.grace):
+				killProcessGroup(p.cmd)
+			}
+		}()
+	})
+}
+
+// Cancel stops the process and marks the result as cancelled by the user.
+func (p *Process) Cancel() {
+	p.cancelled.Store(true)
+	p.Stop()
+}
+
+// wait supervises the process until exit and builds the result.
+func (p *Process) wait(ctx context.Context, e *Executor) {
+	var timeout <-chan time.Time
+	if p.req.Timeout > 0 {
+		timer := time.NewTimer(p.req.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- p.cmd.Wait() }()
+
+	var waitErr error
+	for waiting := true; waiting; {
+		select {
+		case waitErr = <-exited:
+			waiting = false
+		case <-ctx.Done():
+			p.Cancel()
+			ctx = context.Background()
+		case <-timeout:
+			p.timedOut.Store(true)
+			p.Stop()
+			timeout = nil
+		}
+	}
+	if p.pty != nil {
+		// Drain the terminal; descendants that keep it open get the grace period
+		select {
+		case <-p.ptyDone:
+		case <-time.After(p.grace):
+		}
+	}
+
+	finished := time.Now()
+	result := &ExecResult{
+		JourneyID:  p.req.JourneyID,
+		StepIndex:  p.req.StepIndex,
+		ExitCode:   -1,
+		Output:     p.stdout.String(),
+		Stderr:     p.stderr.String(),
+		StartedAt:  p.startedAt,
+		FinishedAt: finished,
+		DurationMs: finished.Sub(p.startedAt).Milliseconds(),
+		TimedOut:   p.timedOut.Load(),
+		Stalled:    p.stalled.Load(),
+		Cancelled:  p.cancelled.Load(),
+	}
+	if result.Stalled {
+		result.StallAction = p.req.StallAction
+	}
+	if p.cmd.ProcessState != nil {
+		result.ExitCode = p.cmd.ProcessState.ExitCode()
+	}
+	if waitErr != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(waitErr, &exitErr) {
+			result.Error = waitErr.Error()
+		}
+	}
+
+	p.closeFiles()
+
+	p.result = result
+	e.untrack(p)
+	close(p.done)
+}
+
+// readPTY forwards terminal output to the stdout stream until the terminal closes.
+func (p *Process) readPTY() {
+	defer close(p.ptyDone)
+	w := &streamWriter{p: p, stream: StreamStdout}
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := p.pty.Read(buf)
+		if n > 0 {
+			w.Write(buf[:n])
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// closeFiles closes the log, transcript and terminal of the process.
+func (p *Process) closeFiles() {
+	if p.log != nil {
+		p.log.Close()
+	}
+	p.transcript.close()
+	if p.pty != nil {
+		p.pty.Close()
+	}
+}
+
+// streamWriter receives output from one stream of the process.
+type streamWriter struct {
+	p      *Process
+	stream string
+}
+
+// Write records the chunk, updates progress counters and forwards it.
+func (w *streamWriter) Write(chunk []byte) (int, error) {
+	p := w.p
+	p.lastOutput.Store(time.Now().UnixNano())
+	p.outputBytes.Add(int64(len(chunk)))
+
+	if w.stream == StreamStderr {
+		p.stderr.Write(chunk)
+	} else {
+		p.stdout.Write(chunk)
+	}
+	if p.log != nil {
+		p.log.Write(chunk)
+	}
+	p.transcript.record(w.stream, chunk)
+	if p.req.OnOutput != nil {
+		p.req.OnOutput(w.stream, chunk)
+	}
+	return len(chunk), nil
+}
+
+// tailBuffer keeps the last max bytes written to it.
+type tailBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+	max int
+}
+
+// newTailBuffer creates a buffer bounded to max bytes.
+func newTailBuffer(max int) *tailBuffer {
+	return &tailBuffer{max: max}
+}
+
+// Write appends data, discarding the oldest bytes beyond the limit.
+func (b *tailBuffer) Write(data []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, data...)
+	if len(b.buf) > b.max {
+		b.buf = append([]byte{}, b.buf[len(b.buf)-b.max:]...)
+	}
+}
+
+// String returns the buffered data.
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.buf)
+}
@@ -0,0 +1,244 @@
+package opencode
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newShellExecutor returns an executor that runs its args through sh -c
+// instead of the real OpenCode CLI.
+func newShellExecutor() *Executor {
+	e := NewExecutor()
+	e.Command = "sh"
+	e.BaseArgs = []string{"-c"}
+	e.GracePeriod = 200 * time.Millisecond
+	return e
+}
+
+// eventRecorder collects emitted events.
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *eventRecorder) emit(event string, data interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *eventRecorder) count(event string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, e := range r.events {
+		if e == event {
+			n++
+		}
+	}
+	return n
+}
+
+func TestExecutorRun_Success(t *testing.T) {
+	e := newShellExecutor()
+	logPath := filepath.Join(t.TempDir(), "logs", "step-0.log")
+
+	var mu sync.Mutex
+	var streamed strings.Builder
+	result, err := e.Run(context.Background(), ExecRequest{
+		JourneyID: "j-1",
+		Args:      []string{"echo hello; echo oops >&2"},
+		LogPath:   logPath,
+		OnOutput: func(stream string, chunk []byte) {
+			mu.Lock()
+			defer mu.Unlock()
+			streamed.Write(chunk)
+		},
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if result.ExitCode != 0 {
+		t.Errorf("ExitCode = %d, want 0", result.ExitCode)
+	}
+	if strings.TrimSpace(result.Output) != "hello" {
+		t.Errorf("Output = %q, want hello", result.Output)
+	}
+	if strings.TrimSpace(result.Stderr) != "oops" {
+		t.Errorf("Stderr = %q, want oops", result.Stderr)
+	}
+	if !strings.Contains(streamed.String(), "hello") {
+		t.Errorf("OnOutput did not receive output, got %q", streamed.String())
+	}
+	if data, err := os.ReadFile(logPath); err != nil || !strings.Contains(string(data), "hello") {
+		t.Errorf("step log = %q (err %v), want output", data, err)
+	}
+	if _, running := e.Get("j-1", 0); running {
+		t.Error("finished process should not be tracked")
+	}
+}
+
+func TestExecutorRun_ExitCode(t *testing.T) {
+	e := newShellExecutor()
+	result, err := e.Run(context.Background(), ExecRequest{Args: []string{"exit 3"}})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.ExitCode != 3 {
+		t.Errorf("ExitCode = %d, want 3", result.ExitCode)
+	}
+}
+
+func TestExecutorRun_Timeout(t *testing.T) {
+	e := newShellExecutor()
+	start := time.Now()
+	result, err := e.Run(context.Background(), ExecRequest{
+		Args:    []string{"sleep 10"},
+		Timeout: 100 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !result.TimedOut {
+		t.Error("expected TimedOut")
+	}
+	if time.Since(start) > 3*time.Second {
+		t.Errorf("timeout took too long: %v", time.Since(start))
+	}
+}
+
+func TestExecutorRun_ContextCancel(t *testing.T) {
+	e := newShellExecutor()
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	result, err := e.Run(ctx, ExecRequest{Args: []string{"sleep 10"}})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !result.Cancelled {
+		t.Error("expected Cancelled")
+	}
+}
+
+func TestExecutorRun_StallKill(t *testing.T) {
+	rec := &eventRecorder{}
+	e := newShellExecutor()
+	e.HeartbeatInterval = 50 * time.Millisecond
+	e.StallIntervals = 2
+	e.StallAction = StallActionKill
+	e.Emit = rec.emit
+
+	result, err := e.Run(context.Background(), ExecRequest{Args: []string{"sleep 10"}})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if !result.Stalled || result.StallAction != StallActionKill {
+		t.Errorf("Stalled = %v, StallAction = %q; want stalled and killed", result.Stalled, result.StallAction)
+	}
+	if rec.count("opencode.heartbeat") < 2 {
+		t.Errorf("heartbeats = %d, want >= 2", rec.count("opencode.heartbeat"))
+	}
+	if rec.count("journey.stalled") != 1 {
+		t.Errorf("journey.stalled events = %d, want 1", rec.count("journey.stalled"))
+	}
+}
+
+func TestExecutorRun_StallRetryReturnsStalled(t *testing.T) {
+	rec := &eventRecorder{}
+	e := newShellExecutor()
+	e.HeartbeatInterval = 50 * time.Millisecond
+	e.StallIntervals = 1
+	e.StallAction = StallActionRetry
+	e.Emit = rec.emit
+
+	result, err := e.Run(context.Background(), ExecRequest{Args: []string{"sleep 10"}})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	// The caller's retry policy decides about running the step again
+	if !result.Stalled || result.StallAction != StallActionRetry {
+		t.Errorf("Stalled = %v, StallAction = %q; want stalled for a retry", result.Stalled, result.StallAction)
+	}
+	if rec.count("journey.stalled") != 1 {
+		t.Errorf("journey.stalled events = %d, want 1", rec.count("journey.stalled"))
+	}
+}
+
+func TestExecutorRun_StallWarnDoesNotKill(t *testing.T) {
+	rec := &eventRecorder{}
+	e := newShellExecutor()
+	e.HeartbeatInterval = 50 * time.Millisecond
+	e.StallIntervals = 1
+	e.StallAction = StallActionWarn
+	e.Emit = rec.emit
+
+	result, err := e.Run(context.Background(), ExecRequest{Args: []string{"sleep 0.3; echo done"}})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if result.Stalled || result.ExitCode != 0 {
+		t.Errorf("warn action should not stop the process: %+v", result)
+	}
+	if rec.count("journey.stalled") < 1 {
+		t.Error("expected journey.stalled event")
+	}
+}
+
+func TestExecutorRun_RequestMonitoringSettings(t *testing.T) {
+	rec := &eventRecorder{}
+	e := newShellExecutor() // No heartbeat, warn on stall
+	e.Emit = rec.emit
+
+	result, err := e.Run(context.Background(), ExecRequest{
+		Args:              []string{"sleep 10"},
+		HeartbeatInterval: 50 * time.Millisecond,
+		StallIntervals:    1,
+		StallAction:       StallActionKill,
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if !result.Stalled {
+		t.Error("expected the request's kill action to stop the process")
+	}
+	if rec.count("opencode.heartbeat") < 1 {
+		t.Error("expected heartbeats at the request's interval")
+	}
+}
+
+func TestExecutorStart_InvalidProfile(t *testing.T) {
+	e := newShellExecutor()
+	_, err := e.Start(context.Background(), ExecRequest{Profile: "bad; rm -rf /"})
+	if !errors.Is(err, ErrInvalidProfile) {
+		t.Errorf("err = %v, want ErrInvalidProfile", err)
+	}
+}
+
+func TestExecutorStart_MissingBinary(t *testing.T) {
+	e := NewExecutor()
+	e.Command = "definitely-not-a-real-opencode-binary"
+	if _, err := e.Start(context.Background(), ExecRequest{}); err == nil {
+		t.Error("expected error for missing binary")
+	}
+}
+
+func TestTailBuffer(t *testing.T) {
+	b := newTailBuffer(5)
+	b.Write([]byte("abc"))
+	b.Write([]byte("defg"))
+	if got := b.String(); got != "cdefg" {
+		t.Errorf("tail = %q, want cdefg", got)
+	}
+}
@@ -0,0 +1,166 @@
+package opencode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// InputMode controls whether a running process can receive input.
+type InputMode string
+
+const (
+	// InputOff connects stdin to the null device (default). Some CLIs treat a
+	// piped stdin as the prompt, so input is opt-in.
+	InputOff InputMode = "off"
+	// InputPipe connects stdin to a pipe.
+	InputPipe InputMode = "pipe"
+	// InputPTY runs the process on a pseudo-terminal, for tools that behave
+	// differently without a TTY. stdout and stderr are merged. Linux only.
+	InputPTY InputMode = "pty"
+)
+
+// StreamStdin names input in transcripts.
+const StreamStdin = "stdin"
+
+var (
+	// ErrProcessNotFound is returned when no process runs for a journey step.
+	ErrProcessNotFound = errors.New("no running process for step")
+	// ErrInputClosed is returned when the process does not accept input.
+	ErrInputClosed = errors.New("process does not accept input")
+	// ErrPTYUnsupported is returned when a pseudo-terminal is requested on an unsupported platform.
+	ErrPTYUnsupported = errors.New("pseudo-terminal not supported on this platform")
+)
+
+// TranscriptEntry is one chunk of traffic between Auto-BMAD and a process.
+type TranscriptEntry struct {
+	Time   time.Time `json:"time"`
+	Stream string    `json:"stream"` // stdout, stderr or stdin
+	Data   string    `json:"data"`
+	Error  string    `json:"error,omitempty"` // set when writing the input failed
+}
+
+// transcript appends entries as JSON lines so replays show exactly what was
+// printed and typed, in order.
+type transcript struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+// openTranscript creates or appends to the transcript file at path.
+func openTranscript(path string) (*transcript, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("creating transcript directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("opening transcript: %w", err)
+	}
+	return &transcript{file: f, enc: json.NewEncoder(f)}, nil
+}
+
+// record writes one entry. Write errors are ignored; the transcript is best effort.
+func (t *transcript) record(stream string, data []byte) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_ = t.enc.Encode(TranscriptEntry{Time: time.Now(), Stream: stream, Data: string(data)})
+}
+
+// recordFailure writes an entry noting that data could not be delivered.
+func (t *transcript) recordFailure(stream string, data []byte, err error) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_ = t.enc.Encode(TranscriptEntry{Time: time.Now(), Stream: stream, Data: string(data), Error: err.Error()})
+}
+
+// close closes the transcript file.
+func (t *transcript) close() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.file.Close()
+}
+
+// ReadTranscript loads all entries of a transcript file.
+func ReadTranscript(path string) ([]TranscriptEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []TranscriptEntry
+	dec := json.NewDecoder(f)
+	for {
+		var e TranscriptEntry
+		if err := dec.Decode(&e); err == io.EOF {
+			return entries, nil
+		} else if err != nil {
+			return entries, fmt.Errorf("parsing transcript: %w", err)
+		}
+		entries = append(entries, e)
+	}
+}
+
+// AcceptsInput reports whether the process was started with an input channel and is still running.
+func (p *Process) AcceptsInput() bool {
+	if p.stdin == nil {
+		return false
+	}
+	select {
+	case <-p.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// SendInput writes data to the process's stdin (or terminal) and records it in the transcript.
+func (p *Process) SendInput(data []byte) error {
+	if !p.AcceptsInput() {
+		return ErrInputClosed
+	}
+
+	p.inputMu.Lock()
+	defer p.inputMu.Unlock()
+	// Record before writing: the process may answer before Write returns, and
+	// its output must not precede the input that caused it.
+	p.transcript.record(StreamStdin, data)
+	if _, err := p.stdin.Write(data); err != nil {
+		p.transcript.recordFailure(StreamStdin, data, err)
+		return fmt.Errorf("writing input: %w", err)
+	}
+	if p.req.OnInput != nil {
+		p.req.OnInput(data)
+	}
+	return nil
+}
+
+// SendInput writes data to the running process of a journey step.
+func (e *Executor) SendInput(journeyID string, stepIndex int, data []byte) error {
+	p, ok := e.Get(journeyID, stepIndex)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrProcessNotFound, processKey(journeyID, stepIndex))
+	}
+	return p.SendInput(data)
+}
+
+// AcceptsInput reports whether the running process of a journey step accepts input.
+func (e *Executor) AcceptsInput(journeyID string, stepIndex int) bool {
+	p, ok := e.Get(journeyID, stepIndex)
+	return ok && p.AcceptsInput()
+}
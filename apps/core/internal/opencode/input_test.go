@@ -0,0 +1,164 @@
+package opencode
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitForInput waits until the executor reports a running process accepting input.
+func waitForInput(t *testing.T, e *Executor, journeyID string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !e.AcceptsInput(journeyID, 0) {
+		if time.Now().After(deadline) {
+			t.Fatal("process does not accept input")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestExecutorSendInput_Pipe(t *testing.T) {
+	e := newShellExecutor()
+	e.InputMode = InputPipe
+	transcriptPath := filepath.Join(t.TempDir(), "attempt-1.transcript.jsonl")
+	prompted := make(chan struct{}, 1)
+
+	p, err := e.Start(context.Background(), ExecRequest{
+		JourneyID:      "j-1",
+		Args:           []string{"echo 'Continue? [y/n]'; read answer; echo got:$answer"},
+		TranscriptPath: transcriptPath,
+		OnOutput: func(stream string, chunk []byte) {
+			select {
+			case prompted <- struct{}{}:
+			default:
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	waitForInput(t, e, "j-1")
+	select {
+	case <-prompted:
+	case <-time.After(2 * time.Second):
+		t.Fatal("no prompt received")
+	}
+
+	if err := e.SendInput("j-1", 0, []byte("y\n")); err != nil {
+		t.Fatalf("SendInput failed: %v", err)
+	}
+	result := p.Wait()
+
+	if !strings.Contains(result.Output, "got:y") {
+		t.Errorf("Output = %q, want got:y", result.Output)
+	}
+	if e.AcceptsInput("j-1", 0) {
+		t.Error("finished process still accepts input")
+	}
+	if err := p.SendInput([]byte("late\n")); !errors.Is(err, ErrInputClosed) {
+		t.Errorf("SendInput after exit err = %v, want ErrInputClosed", err)
+	}
+
+	entries, err := ReadTranscript(transcriptPath)
+	if err != nil {
+		t.Fatalf("ReadTranscript failed: %v", err)
+	}
+	var streams []string
+	for _, entry := range entries {
+		streams = append(streams, entry.Stream)
+	}
+	joined := strings.Join(streams, ",")
+	if !strings.Contains(joined, "stdout,stdin,stdout") {
+		t.Errorf("transcript streams = %v, want output, input, output", streams)
+	}
+}
+
+func TestExecutorSendInput_Errors(t *testing.T) {
+	e := newShellExecutor()
+	if err := e.SendInput("j-missing", 0, []byte("x")); !errors.Is(err, ErrProcessNotFound) {
+		t.Errorf("err = %v, want ErrProcessNotFound", err)
+	}
+
+	// Input is off by default
+	p, err := e.Start(context.Background(), ExecRequest{JourneyID: "j-2", Args: []string{"sleep 5"}})
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer p.Wait()
+	defer p.Stop()
+	if err := e.SendInput("j-2", 0, []byte("x")); !errors.Is(err, ErrInputClosed) {
+		t.Errorf("err = %v, want ErrInputClosed", err)
+	}
+}
+
+func TestProcessSendInput_WriteFailureRecorded(t *testing.T) {
+	transcriptPath := filepath.Join(t.TempDir(), "attempt-1.transcript.jsonl")
+	tr, err := openTranscript(transcriptPath)
+	if err != nil {
+		t.Fatalf("openTranscript failed: %v", err)
+	}
+	r, w := io.Pipe()
+	r.Close()
+	p := &Process{stdin: w, transcript: tr, done: make(chan struct{})}
+
+	if err := p.SendInput([]byte("y\n")); err == nil {
+		t.Fatal("SendInput on a closed pipe succeeded")
+	}
+	tr.close()
+
+	entries, err := ReadTranscript(transcriptPath)
+	if err != nil {
+		t.Fatalf("ReadTranscript failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("entries = %+v, want the input and its failure", entries)
+	}
+	if entries[0].Data != "y\n" || entries[0].Error != "" {
+		t.Errorf("first entry = %+v, want the input", entries[0])
+	}
+	if entries[1].Stream != StreamStdin || entries[1].Error == "" {
+		t.Errorf("second entry = %+v, want a stdin write error", entries[1])
+	}
+}
+
+func TestExecutorSendInput_PTY(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("pseudo-terminals are only supported on Linux")
+	}
+	master, tty, err := openPTY()
+	if err != nil {
+		t.Skipf("pseudo-terminal unavailable: %v", err)
+	}
+	master.Close()
+	tty.Close()
+
+	e := newShellExecutor()
+	e.InputMode = InputPTY
+
+	p, err := e.Start(context.Background(), ExecRequest{
+		JourneyID: "j-3",
+		Args:      []string{"[ -t 0 ] && echo is-tty; read answer; echo got:$answer"},
+	})
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	waitForInput(t, e, "j-3")
+
+	if err := e.SendInput("j-3", 0, []byte("2\n")); err != nil {
+		t.Fatalf("SendInput failed: %v", err)
+	}
+	result := p.Wait()
+
+	if result.ExitCode != 0 {
+		t.Errorf("ExitCode = %d, want 0", result.ExitCode)
+	}
+	if !strings.Contains(result.Output, "is-tty") || !strings.Contains(result.Output, "got:2") {
+		t.Errorf("Output = %q, want is-tty and got:2", result.Output)
+	}
+}
@@ -0,0 +1,140 @@
+package opencode
+
+import (
+	"context"
+	"time"
+)
+
+// StallAction is what happens automatically when a step is stalled.
+type StallAction string
+
+const (
+	// StallActionWarn only emits journey.stalled
+	StallActionWarn StallAction = "warn"
+	// StallActionKill stops the process; the step fails as stalled
+	StallActionKill StallAction = "kill"
+	// StallActionRetry stops the process; the step's retry policy runs it again
+	StallActionRetry StallAction = "retry"
+)
+
+// minCPUProgress is the CPU time a process tree must consume between two
+// heartbeats for the interval to count as progress without new output.
+const minCPUProgress = 50 * time.Millisecond
+
+// Heartbeat is a periodic liveness report for a running step.
+// It is emitted to the client as the opencode.heartbeat event.
+type Heartbeat struct {
+	JourneyID     string    `json:"journeyId"`
+	StepIndex     int       `json:"stepIndex"`
+	PID           int       `json:"pid"`
+	Alive         bool      `json:"alive"`
+	SinceOutputMs int64     `json:"sinceOutputMs"` // Time since the last output byte
+	OutputBytes   int64     `json:"outputBytes"`
+	CPUTimeMs     int64     `json:"cpuTimeMs"` // User+system CPU of the process tree
+	Children      int       `json:"children"`  // Live descendant processes
+	IdleIntervals int       `json:"idleIntervals"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// StallEvent is the payload of the journey.stalled event.
+type StallEvent struct {
+	Heartbeat
+	Action StallAction `json:"action"`
+}
+
+// ProcessMonitor watches a running process for signs of progress.
+// Progress is new output or CPU consumed by the process tree; when none is
+// seen for StallIntervals consecutive heartbeats the step is stalled.
+type ProcessMonitor struct {
+	proc           *Process
+	interval       time.Duration
+	stallIntervals int
+
+	// OnHeartbeat is called at every interval while the process runs.
+	OnHeartbeat func(Heartbeat)
+	// OnStall is called once when the stall threshold is reached.
+	// It is called again only after progress resumes and stalls anew.
+	OnStall func(Heartbeat)
+
+	lastOutputBytes int64
+	lastCPU         time.Duration
+	idle            int
+	stalled         bool
+}
+
+// NewProcessMonitor creates a monitor for p with the given heartbeat interval.
+func NewProcessMonitor(p *Process, interval time.Duration, stallIntervals int) *ProcessMonitor {
+	if stallIntervals < 1 {
+		stallIntervals = 1
+	}
+	return &ProcessMonitor{
+		proc:           p,
+		interval:       interval,
+		stallIntervals: stallIntervals,
+	}
+}
+
+// Run emits heartbeats until the process exits or ctx is cancelled.
+func (m *ProcessMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.tick(time.Now())
+		case <-m.proc.Done():
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// tick samples the process and evaluates progress since the previous tick.
+func (m *ProcessMonitor) tick(now time.Time) {
+	stats := readProcStats(m.proc.PID())
+	outputBytes := m.proc.OutputBytes()
+
+	progressed := outputBytes > m.lastOutputBytes || stats.CPUTime-m.lastCPU >= minCPUProgress
+	m.lastOutputBytes = outputBytes
+	m.lastCPU = stats.CPUTime
+
+	if progressed {
+		m.idle = 0
+		m.stalled = false
+	} else {
+		m.idle++
+	}
+
+	hb := Heartbeat{
+		JourneyID:     m.proc.req.JourneyID,
+		StepIndex:     m.proc.req.StepIndex,
+		PID:           m.proc.PID(),
+		Alive:         stats.Alive,
+		SinceOutputMs: now.Sub(m.proc.LastOutputAt()).Milliseconds(),
+		OutputBytes:   outputBytes,
+		CPUTimeMs:     stats.CPUTime.Milliseconds(),
+		Children:      stats.Children,
+		IdleIntervals: m.idle,
+		Timestamp:     now,
+	}
+
+	if m.OnHeartbeat != nil {
+		m.OnHeartbeat(hb)
+	}
+
+	if m.idle >= m.stallIntervals && !m.stalled {
+		m.stalled = true
+		if m.OnStall != nil {
+			m.OnStall(hb)
+		}
+	}
+}
+
+// procStats is a snapshot of a process tree's resource usage.
+type procStats struct {
+	Alive    bool
+	CPUTime  time.Duration // User+system time of the process and live descendants
+	Children int           // Number of live descendant processes
+}
@@ -0,0 +1,75 @@
+package opencode
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestReadProcStats_Self(t *testing.T) {
+	stats := readProcStats(os.Getpid())
+	if !stats.Alive {
+		t.Error("current process should be alive")
+	}
+}
+
+func TestReadProcStats_Children(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("child tracking requires /proc")
+	}
+
+	e := newShellExecutor()
+	p, err := e.Start(context.Background(), ExecRequest{Args: []string{"sleep 5 & sleep 5; wait"}})
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer p.Stop()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if stats := readProcStats(p.PID()); stats.Children >= 2 {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Errorf("expected at least 2 live children, got %d", readProcStats(p.PID()).Children)
+}
+
+func TestProcessMonitor_OutputResetsIdle(t *testing.T) {
+	e := newShellExecutor()
+	p, err := e.Start(context.Background(), ExecRequest{Args: []string{"sleep 5"}})
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer p.Stop()
+
+	stalls := 0
+	m := NewProcessMonitor(p, time.Second, 2)
+	m.OnStall = func(Heartbeat) { stalls++ }
+
+	m.tick(time.Now())
+	m.tick(time.Now())
+	if stalls != 1 {
+		t.Fatalf("stalls = %d after 2 idle ticks, want 1", stalls)
+	}
+
+	// A third idle tick must not raise the stall again
+	m.tick(time.Now())
+	if stalls != 1 {
+		t.Fatalf("stall raised again without progress: %d", stalls)
+	}
+
+	// Output counts as progress and re-arms stall detection
+	p.outputBytes.Add(10)
+	m.tick(time.Now())
+	if m.idle != 0 {
+		t.Errorf("idle = %d after output, want 0", m.idle)
+	}
+	m.tick(time.Now())
+	m.tick(time.Now())
+	if stalls != 2 {
+		t.Errorf("stalls = %d after renewed idleness, want 2", stalls)
+	}
+}
@@ -2,8 +2,3 @@
 // It handles spawning, monitoring, and terminating OpenCode processes
 // for each workflow step execution.
 package opencode
-
-// Executor manages OpenCode CLI process execution.
-type Executor struct {
-	// TODO: Story 3.1 will implement OpenCode process spawning
-}
@@ -0,0 +1,89 @@
+//go:build linux
+
+package opencode
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clockTicksPerSecond is USER_HZ, the unit of CPU times in /proc/<pid>/stat.
+// It is 100 on every mainstream Linux architecture.
+const clockTicksPerSecond = 100
+
+// procStat holds the fields of /proc/<pid>/stat used by the monitor.
+type procStat struct {
+	State string
+	PPID  int
+	Ticks uint64 // utime + stime
+}
+
+// readProcStat parses /proc/<pid>/stat.
+func readProcStat(pid int) (procStat, bool) {
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return procStat{}, false
+	}
+
+	// The command name (field 2) may contain spaces and parentheses,
+	// so parse the remaining fields after the last ')'.
+	s := string(data)
+	end := strings.LastIndexByte(s, ')')
+	if end < 0 {
+		return procStat{}, false
+	}
+	fields := strings.Fields(s[end+1:])
+	if len(fields) < 13 {
+		return procStat{}, false
+	}
+
+	// fields[0] is field 3 (state), so field N is fields[N-3]
+	ppid, _ := strconv.Atoi(fields[1])
+	utime, _ := strconv.ParseUint(fields[11], 10, 64)
+	stime, _ := strconv.ParseUint(fields[12], 10, 64)
+
+	return procStat{State: fields[0], PPID: ppid, Ticks: utime + stime}, true
+}
+
+// readProcStats reports liveness and CPU time of pid and its live descendants.
+func readProcStats(pid int) procStats {
+	root, ok := readProcStat(pid)
+	if !ok || root.State == "Z" || root.State == "X" {
+		return procStats{}
+	}
+
+	// Build the parent -> children map from all visible processes
+	children := make(map[int][]int)
+	stats := make(map[int]procStat)
+	entries, _ := os.ReadDir("/proc")
+	for _, entry := range entries {
+		child, err := strconv.Atoi(entry.Name())
+		if err != nil || child == pid {
+			continue
+		}
+		if st, ok := readProcStat(child); ok {
+			children[st.PPID] = append(children[st.PPID], child)
+			stats[child] = st
+		}
+	}
+
+	result := procStats{Alive: true}
+	ticks := root.Ticks
+	queue := append([]int{}, children[pid]...)
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		st := stats[next]
+		if st.State != "Z" && st.State != "X" {
+			result.Children++
+			ticks += st.Ticks
+		}
+		queue = append(queue, children[next]...)
+	}
+
+	result.CPUTime = time.Duration(ticks) * time.Second / clockTicksPerSecond
+	return result
+}
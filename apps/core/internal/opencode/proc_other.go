@@ -0,0 +1,18 @@
+//go:build !linux
+
+package opencode
+
+import (
+	"os"
+	"syscall"
+)
+
+// readProcStats reports only liveness; CPU time and children require /proc.
+// Without CPU samples, the monitor judges progress by output alone.
+func readProcStats(pid int) procStats {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return procStats{}
+	}
+	return procStats{Alive: proc.Signal(syscall.Signal(0)) == nil}
+}
@@ -25,6 +25,9 @@ type ProfilesResult struct {
 	Source       string    `json:"source"` // e.g., "~/.bash_aliases"
 }
 
+// aliasPattern matches alias opencode-{name}='...' or alias opencode-{name}="..."
+var aliasPattern = regexp.MustCompile(`^alias\s+opencode-(\w+)=['"](.+?)['"]`)
+
 // GetProfiles detects and returns all available OpenCode profiles.
 func GetProfiles() (*ProfilesResult, error) {
 	result := &ProfilesResult{
@@ -59,9 +62,6 @@ func GetProfiles() (*ProfilesResult, error) {
 	}
 	defer file.Close()
 
-	// Pattern: alias opencode-{name}='...' or alias opencode-{name}="..."
-	aliasPattern := regexp.MustCompile(`^alias\s+opencode-(\w+)=['"](.+?)['"]`)
-
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -0,0 +1,133 @@
+package opencode
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// knownProviders maps OpenCode provider IDs to the API endpoint they use
+// when no baseURL is configured.
+var knownProviders = map[string]string{
+	"anthropic":  "https://api.anthropic.com",
+	"openai":     "https://api.openai.com",
+	"google":     "https://generativelanguage.googleapis.com",
+	"openrouter": "https://openrouter.ai/api/v1",
+	"groq":       "https://api.groq.com",
+	"mistral":    "https://api.mistral.ai",
+	"deepseek":   "https://api.deepseek.com",
+	"xai":        "https://api.x.ai",
+}
+
+// configEnvPattern finds an OPENCODE_CONFIG=<path> assignment in an alias command.
+var configEnvPattern = regexp.MustCompile(`(?:^|\s)OPENCODE_CONFIG=("[^"]*"|'[^']*'|\S+)`)
+
+// openCodeConfig is the part of opencode.json that names providers.
+type openCodeConfig struct {
+	Model    string `json:"model"` // provider/model
+	Provider map[string]struct {
+		Options struct {
+			BaseURL string `json:"baseURL"`
+		} `json:"options"`
+	} `json:"provider"`
+}
+
+// ProviderEndpoints returns the API endpoints that OpenCode talks to under
+// a profile: the baseURL of each configured provider and the default
+// endpoint of the model's provider. The configuration is the file set with
+// OPENCODE_CONFIG in the profile's alias or the environment, or the global
+// opencode.json. Missing or unreadable configuration yields no endpoints.
+func ProviderEndpoints(profile string) []string {
+	path := profileConfigPath(profile)
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var cfg openCodeConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var endpoints []string
+	add := func(endpoint string) {
+		if endpoint != "" && !seen[endpoint] {
+			seen[endpoint] = true
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+
+	if id, _, ok := strings.Cut(cfg.Model, "/"); ok {
+		if p, configured := cfg.Provider[id]; configured && p.Options.BaseURL != "" {
+			add(p.Options.BaseURL)
+		} else {
+			add(knownProviders[id])
+		}
+	}
+	ids := make([]string, 0, len(cfg.Provider))
+	for id := range cfg.Provider {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		add(cfg.Provider[id].Options.BaseURL)
+	}
+	return endpoints
+}
+
+// profileConfigPath returns the OpenCode configuration file of a profile.
+func profileConfigPath(profile string) string {
+	homeDir, _ := os.UserHomeDir()
+	expand := func(path string) string {
+		path = strings.Trim(path, `"'`)
+		if rest, ok := strings.CutPrefix(path, "~/"); ok && homeDir != "" {
+			return filepath.Join(homeDir, rest)
+		}
+		return path
+	}
+
+	if profile != "" && profile != "default" {
+		if m := configEnvPattern.FindStringSubmatch(aliasCommand(homeDir, profile)); m != nil {
+			return expand(m[1])
+		}
+	}
+	if path := os.Getenv("OPENCODE_CONFIG"); path != "" {
+		return expand(path)
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "opencode", "opencode.json")
+	}
+	if homeDir == "" {
+		return ""
+	}
+	return filepath.Join(homeDir, ".config", "opencode", "opencode.json")
+}
+
+// aliasCommand returns the command of the opencode-{profile} alias in
+// ~/.bash_aliases, or "".
+func aliasCommand(homeDir, profile string) string {
+	if homeDir == "" {
+		return ""
+	}
+	file, err := os.Open(filepath.Join(homeDir, ".bash_aliases"))
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		matches := aliasPattern.FindStringSubmatch(strings.TrimSpace(scanner.Text()))
+		if len(matches) == 3 && matches[1] == profile {
+			return matches[2]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,56 @@
+package opencode
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestProviderEndpoints(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("OPENCODE_CONFIG", "")
+
+	writeFile := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(home, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// No configuration: no endpoints
+	if got := ProviderEndpoints("default"); len(got) != 0 {
+		t.Errorf("ProviderEndpoints without config = %v, want none", got)
+	}
+
+	writeFile(".config/opencode/opencode.json", `{"model": "anthropic/claude-sonnet-4"}`)
+	writeFile("work/opencode.json", `{
+		"model": "corp/llm",
+		"provider": {
+			"corp": {"options": {"baseURL": "https://llm.corp.example/v1"}},
+			"backup": {"options": {"baseURL": "https://backup.example/v1"}}
+		}
+	}`)
+	writeFile(".bash_aliases", "alias opencode-work='OPENCODE_CONFIG=~/work/opencode.json opencode'\n"+
+		"alias opencode-plain='opencode --verbose'\n")
+
+	tests := []struct {
+		profile string
+		want    []string
+	}{
+		{"default", []string{"https://api.anthropic.com"}},
+		{"plain", []string{"https://api.anthropic.com"}},
+		{"work", []string{"https://llm.corp.example/v1", "https://backup.example/v1"}},
+	}
+	for _, tt := range tests {
+		if got := ProviderEndpoints(tt.profile); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ProviderEndpoints(%q) = %v, want %v", tt.profile, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+
+package opencode
+
+import (
+	"os"
+	"os/exec"
+)
+
+// openPTY is not implemented outside Linux.
+func openPTY() (master, tty *os.File, err error) {
+	return nil, nil, ErrPTYUnsupported
+}
+
+// attachPTY is never called because openPTY fails.
+func attachPTY(cmd *exec.Cmd, tty *os.File) {}
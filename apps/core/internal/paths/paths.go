@@ -0,0 +1,129 @@
+// Package paths locates the per-user directories of AutoBMAD. They follow
+// the XDG base directory spec unless a single root is chosen with the
+// --config-dir flag or the AUTOBMAD_HOME environment variable.
+package paths
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// HomeEnv names the environment variable that puts all AutoBMAD
+// directories under one root. Tests set it to keep away from the real home.
+const HomeEnv = "AUTOBMAD_HOME"
+
+// appName is the directory created inside each XDG base directory.
+const appName = "auto-bmad"
+
+// Dirs are the per-user directories. A directory that cannot be located,
+// such as when there is no home directory, is empty.
+type Dirs struct {
+	Config  string `json:"config"`  // Settings, presets and recent projects
+	Cache   string `json:"cache"`   // Data that can be rebuilt
+	State   string `json:"state"`   // Data worth keeping that is not configuration
+	Runtime string `json:"runtime"` // Sockets and other files of running processes
+}
+
+var (
+	mu       sync.RWMutex
+	override string // Set by --config-dir
+)
+
+// SetOverride puts all directories under dir, taking precedence over
+// AUTOBMAD_HOME. An empty dir removes the override.
+func SetOverride(dir string) error {
+	dir, err := rootDir(dir)
+	if err != nil {
+		return fmt.Errorf("resolving config dir: %w", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	override = dir
+	return nil
+}
+
+// Get returns the directories for the current override and environment.
+func Get() Dirs {
+	mu.RLock()
+	root := override
+	mu.RUnlock()
+	return resolve(root, os.Getenv, os.UserHomeDir)
+}
+
+// ConfigFile returns the path of a file in the config directory, or "" if
+// there is no config directory.
+func ConfigFile(name string) string {
+	dir := Get().Config
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, name)
+}
+
+// rootDir makes a root chosen with --config-dir or AUTOBMAD_HOME absolute.
+// Unlike XDG variables, a relative root is relative to the working directory.
+func rootDir(dir string) (string, error) {
+	if dir == "" {
+		return "", nil
+	}
+	return filepath.Abs(dir)
+}
+
+// resolve computes the directories from an override root, the environment
+// and the home directory.
+func resolve(root string, getenv func(string) string, home func() (string, error)) Dirs {
+	if root == "" {
+		// Abs only fails without a working directory; ignore the variable then
+		root, _ = rootDir(getenv(HomeEnv))
+	}
+	if root != "" {
+		return Dirs{
+			Config:  root,
+			Cache:   filepath.Join(root, "cache"),
+			State:   filepath.Join(root, "state"),
+			Runtime: filepath.Join(root, "run"),
+		}
+	}
+
+	homeDir, err := home()
+	if err != nil {
+		homeDir = ""
+	}
+	homeDir = absolute(homeDir)
+
+	dirs := Dirs{
+		Config: baseDir(getenv("XDG_CONFIG_HOME"), homeDir, ".config"),
+		Cache:  baseDir(getenv("XDG_CACHE_HOME"), homeDir, ".cache"),
+		State:  baseDir(getenv("XDG_STATE_HOME"), homeDir, filepath.Join(".local", "state")),
+	}
+	if runtime := absolute(getenv("XDG_RUNTIME_DIR")); runtime != "" {
+		dirs.Runtime = filepath.Join(runtime, appName)
+	} else {
+		// The spec leaves the fallback to the application; keep it per user
+		dirs.Runtime = filepath.Join(os.TempDir(), fmt.Sprintf("%s-%d", appName, os.Getuid()))
+	}
+	return dirs
+}
+
+// baseDir returns the app directory inside an XDG base directory, falling
+// back to homeDir/fallback. It returns "" if neither is usable.
+func baseDir(xdg, homeDir, fallback string) string {
+	if base := absolute(xdg); base != "" {
+		return filepath.Join(base, appName)
+	}
+	if homeDir == "" {
+		return ""
+	}
+	return filepath.Join(homeDir, fallback, appName)
+}
+
+// absolute returns a cleaned path, or "" for empty and relative paths,
+// which the XDG spec says to ignore.
+func absolute(path string) string {
+	if path == "" || !filepath.IsAbs(path) {
+		return ""
+	}
+	return filepath.Clean(path)
+}
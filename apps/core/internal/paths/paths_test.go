@@ -0,0 +1,138 @@
+package paths
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	noHome := func() (string, error) { return "", errors.New("no home") }
+	home := func() (string, error) { return "/home/ada", nil }
+
+	tests := []struct {
+		name string
+		root string
+		env  map[string]string
+		home func() (string, error)
+		want Dirs
+	}{
+		{
+			name: "home fallbacks",
+			home: home,
+			env:  map[string]string{"XDG_RUNTIME_DIR": "/run/user/1000"},
+			want: Dirs{
+				Config:  "/home/ada/.config/auto-bmad",
+				Cache:   "/home/ada/.cache/auto-bmad",
+				State:   "/home/ada/.local/state/auto-bmad",
+				Runtime: "/run/user/1000/auto-bmad",
+			},
+		},
+		{
+			name: "XDG variables",
+			home: home,
+			env: map[string]string{
+				"XDG_CONFIG_HOME": "/xdg/config",
+				"XDG_CACHE_HOME":  "/xdg/cache",
+				"XDG_STATE_HOME":  "/xdg/state/",
+				"XDG_RUNTIME_DIR": "/run/user/1000",
+			},
+			want: Dirs{
+				Config:  "/xdg/config/auto-bmad",
+				Cache:   "/xdg/cache/auto-bmad",
+				State:   "/xdg/state/auto-bmad",
+				Runtime: "/run/user/1000/auto-bmad",
+			},
+		},
+		{
+			name: "relative XDG paths are ignored",
+			home: home,
+			env:  map[string]string{"XDG_CONFIG_HOME": "config", "XDG_RUNTIME_DIR": "/run/user/1000"},
+			want: Dirs{
+				Config:  "/home/ada/.config/auto-bmad",
+				Cache:   "/home/ada/.cache/auto-bmad",
+				State:   "/home/ada/.local/state/auto-bmad",
+				Runtime: "/run/user/1000/auto-bmad",
+			},
+		},
+		{
+			name: "no home directory",
+			home: noHome,
+			env:  map[string]string{"XDG_CACHE_HOME": "/xdg/cache", "XDG_RUNTIME_DIR": "/run/user/1000"},
+			want: Dirs{Cache: "/xdg/cache/auto-bmad", Runtime: "/run/user/1000/auto-bmad"},
+		},
+		{
+			name: "AUTOBMAD_HOME",
+			home: noHome,
+			env:  map[string]string{HomeEnv: "/opt/autobmad", "XDG_CONFIG_HOME": "/xdg/config"},
+			want: Dirs{
+				Config:  "/opt/autobmad",
+				Cache:   "/opt/autobmad/cache",
+				State:   "/opt/autobmad/state",
+				Runtime: "/opt/autobmad/run",
+			},
+		},
+		{
+			name: "override wins over AUTOBMAD_HOME",
+			root: "/srv/autobmad",
+			home: home,
+			env:  map[string]string{HomeEnv: "/opt/autobmad"},
+			want: Dirs{
+				Config:  "/srv/autobmad",
+				Cache:   "/srv/autobmad/cache",
+				State:   "/srv/autobmad/state",
+				Runtime: "/srv/autobmad/run",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resolve(tt.root, func(key string) string { return tt.env[key] }, tt.home)
+			if got != tt.want {
+				t.Errorf("resolve() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolve_RelativeHome(t *testing.T) {
+	want, err := filepath.Abs("autobmad-home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{HomeEnv: "autobmad-home", "XDG_CONFIG_HOME": "/xdg/config"}
+	got := resolve("", func(key string) string { return env[key] }, func() (string, error) { return "/home/ada", nil })
+	if got.Config != want || got.Cache != filepath.Join(want, "cache") {
+		t.Errorf("resolve() = %+v, want directories under %s", got, want)
+	}
+}
+
+func TestSetOverride(t *testing.T) {
+	t.Setenv(HomeEnv, "")
+	dir := t.TempDir()
+	if err := SetOverride(dir); err != nil {
+		t.Fatalf("SetOverride failed: %v", err)
+	}
+	t.Cleanup(func() { SetOverride("") })
+
+	if got := ConfigFile("config.json"); got != filepath.Join(dir, "config.json") {
+		t.Errorf("ConfigFile = %q", got)
+	}
+
+	SetOverride("")
+	t.Setenv(HomeEnv, dir)
+	if got := Get().Cache; got != filepath.Join(dir, "cache") {
+		t.Errorf("Cache = %q", got)
+	}
+}
+
+func TestConfigFile_NoConfigDir(t *testing.T) {
+	t.Setenv(HomeEnv, "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("HOME", "")
+
+	if got := ConfigFile("config.json"); got != "" {
+		t.Errorf("ConfigFile = %q, want empty without a home directory", got)
+	}
+}
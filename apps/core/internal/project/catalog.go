@@ -0,0 +1,247 @@
+package project
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Catalog lists every artifact under a project's _bmad-output/ folder.
+type Catalog struct {
+	ProjectPath string     `json:"projectPath"`
+	Artifacts   []Artifact `json:"artifacts"`
+	ScannedAt   time.Time  `json:"scannedAt"`
+}
+
+// Get returns the artifact with the given path relative to _bmad-output/.
+func (c *Catalog) Get(path string) (*Artifact, bool) {
+	for i := range c.Artifacts {
+		if c.Artifacts[i].Path == filepath.FromSlash(path) {
+			return &c.Artifacts[i], true
+		}
+	}
+	return nil, false
+}
+
+// storyFilePattern matches story files such as "1-2-user-login.md".
+var storyFilePattern = regexp.MustCompile(`^\d+-\d+-`)
+
+// frontmatterTypeKeys are the frontmatter fields that name an artifact's type, in priority order.
+var frontmatterTypeKeys = []string{"workflowType", "documentType", "type"}
+
+// frontmatterTypes normalizes frontmatter type values to catalog types.
+var frontmatterTypes = map[string]string{
+	"prd":               "prd",
+	"architecture":      "architecture",
+	"epics":             "epics",
+	"epics-and-stories": "epics",
+	"create-epics":      "epics",
+	"ux-design":         "ux-design",
+	"ux":                "ux-design",
+	"product-brief":     "product-brief",
+	"research":          "research",
+	"brainstorming":     "brainstorming",
+	"story":             "story",
+	"tech-spec":         "tech-spec",
+	"sprint-status":     "sprint-status",
+}
+
+// BuildCatalog walks _bmad-output/ and records metadata for every file.
+// Hidden files and directories (such as .autobmad/) are skipped. Paths are
+// relative to _bmad-output/.
+func BuildCatalog(projectPath string) (*Catalog, error) {
+	catalog := &Catalog{ProjectPath: projectPath, Artifacts: []Artifact{}, ScannedAt: time.Now()}
+	outputPath := filepath.Join(projectPath, "_bmad-output")
+
+	err := filepath.WalkDir(outputPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == outputPath {
+				return fs.SkipAll
+			}
+			return nil // Unreadable entries are left out
+		}
+		if path == outputPath {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(outputPath, path)
+		if err != nil {
+			return nil
+		}
+		if artifact, err := ReadArtifact(outputPath, rel); err == nil {
+			catalog.Artifacts = append(catalog.Artifacts, *artifact)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walking %s: %w", outputPath, err)
+	}
+
+	sort.Slice(catalog.Artifacts, func(i, j int) bool { return catalog.Artifacts[i].Path < catalog.Artifacts[j].Path })
+	markStale(projectPath, catalog.Artifacts)
+	return catalog, nil
+}
+
+// ReadArtifact reads the metadata of one file, given relative to outputPath.
+// Staleness is not computed; it needs the rest of the catalog.
+func ReadArtifact(outputPath, rel string) (*Artifact, error) {
+	path := filepath.Join(outputPath, rel)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(data)
+	artifact := &Artifact{
+		Name:     filepath.Base(rel),
+		Path:     rel,
+		Modified: info.ModTime().UTC().Format(time.RFC3339Nano),
+		Size:     info.Size(),
+		Hash:     hex.EncodeToString(sum[:]),
+	}
+
+	if filepath.Ext(rel) == ".md" {
+		if fm := ParseFrontmatter(data); fm != nil {
+			artifact.Frontmatter = fm
+			artifact.StepsCompleted = frontmatterList(fm["stepsCompleted"])
+			artifact.InputDocuments = frontmatterList(fm["inputDocuments"])
+		}
+	}
+	artifact.Type = artifactType(rel, artifact.Frontmatter)
+	return artifact, nil
+}
+
+// ParseFrontmatter returns the YAML frontmatter of a markdown document, or
+// nil if it has none or it is not valid YAML.
+func ParseFrontmatter(data []byte) map[string]interface{} {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !bytes.HasPrefix(data, []byte("---\n")) && !bytes.HasPrefix(data, []byte("---\r\n")) {
+		return nil
+	}
+	rest := data[bytes.IndexByte(data, '\n')+1:]
+
+	var block []byte
+	for len(rest) > 0 {
+		line, next, _ := bytes.Cut(rest, []byte("\n"))
+		if trimmed := bytes.TrimRight(line, "\r \t"); string(trimmed) == "---" || string(trimmed) == "..." {
+			var fm map[string]interface{}
+			if err := yaml.Unmarshal(block, &fm); err != nil || fm == nil {
+				return nil
+			}
+			return fm
+		}
+		block = append(block, line...)
+		block = append(block, '\n')
+		rest = next
+	}
+	return nil // Unterminated
+}
+
+// frontmatterList converts a scalar or list frontmatter value to strings.
+func frontmatterList(v interface{}) []string {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			if item != nil {
+				list = append(list, fmt.Sprint(item))
+			}
+		}
+		return list
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+// artifactType derives the type from frontmatter, falling back to the
+// folder and filename.
+func artifactType(rel string, fm map[string]interface{}) string {
+	for _, key := range frontmatterTypeKeys {
+		if v, ok := fm[key].(string); ok {
+			if t, ok := frontmatterTypes[strings.ToLower(strings.TrimSpace(v))]; ok {
+				return t
+			}
+		}
+	}
+
+	name := filepath.Base(rel)
+	lower := strings.ToLower(name)
+	dir := filepath.ToSlash(filepath.Dir(rel))
+	switch {
+	case strings.HasPrefix(lower, "sprint-status"):
+		return "sprint-status"
+	case strings.HasPrefix(dir, "implementation-artifacts") && storyFilePattern.MatchString(lower):
+		return "story"
+	case strings.Contains(lower, "research"):
+		return "research"
+	case strings.Contains(lower, "brainstorm"):
+		return "brainstorming"
+	}
+	return detectArtifactType(name)
+}
+
+// markStale flags artifacts whose input documents changed after they were generated.
+func markStale(projectPath string, artifacts []Artifact) {
+	outputPath := filepath.Join(projectPath, "_bmad-output")
+	for i := range artifacts {
+		a := &artifacts[i]
+		if len(a.InputDocuments) == 0 {
+			continue
+		}
+		generated, err := time.Parse(time.RFC3339Nano, a.Modified)
+		if err != nil {
+			continue
+		}
+		for _, input := range a.InputDocuments {
+			info, ok := statInput(projectPath, outputPath, input)
+			if ok && info.ModTime().After(generated) {
+				a.StaleInputs = append(a.StaleInputs, input)
+			}
+		}
+		a.Stale = len(a.StaleInputs) > 0
+	}
+}
+
+// statInput resolves an input document reference. BMAD records them
+// relative to the project root, relative to _bmad-output/, or absolute.
+func statInput(projectPath, outputPath, ref string) (os.FileInfo, bool) {
+	ref = filepath.FromSlash(strings.TrimSpace(ref))
+	if ref == "" {
+		return nil, false
+	}
+	candidates := []string{ref}
+	if !filepath.IsAbs(ref) {
+		candidates = []string{filepath.Join(projectPath, ref), filepath.Join(outputPath, ref)}
+	}
+	for _, path := range candidates {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return info, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,101 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeOutputFile writes a file under _bmad-output/ with the given modification time.
+func writeOutputFile(t *testing.T, project, rel, content string, modified time.Time) {
+	t.Helper()
+	path := filepath.Join(project, "_bmad-output", filepath.FromSlash(rel))
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	require.NoError(t, os.Chtimes(path, modified, modified))
+}
+
+func TestBuildCatalog(t *testing.T) {
+	project := t.TempDir()
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	writeOutputFile(t, project, "planning-artifacts/product-brief.md", "# Brief\n", base)
+	writeOutputFile(t, project, "planning-artifacts/requirements.md",
+		"---\nworkflowType: 'prd'\nstepsCompleted: [1, 2, 3]\ninputDocuments: ['_bmad-output/planning-artifacts/product-brief.md']\n---\n# PRD\n",
+		base.Add(time.Minute))
+	writeOutputFile(t, project, "planning-artifacts/ux-notes.md",
+		"---\ninputDocuments:\n  - planning-artifacts/requirements.md\n---\n# UX\n", base)
+	writeOutputFile(t, project, "implementation-artifacts/1-2-user-login.md", "# Story\n", base)
+	writeOutputFile(t, project, "implementation-artifacts/sprint-status.yaml", "development_status: {}\n", base)
+	writeOutputFile(t, project, "analysis/market-research.md", "# Research\n", base)
+	writeOutputFile(t, project, ".autobmad/config.json", "{}", base)
+
+	catalog, err := BuildCatalog(project)
+	require.NoError(t, err)
+
+	paths := []string{}
+	for _, a := range catalog.Artifacts {
+		paths = append(paths, filepath.ToSlash(a.Path))
+	}
+	assert.Equal(t, []string{
+		"analysis/market-research.md",
+		"implementation-artifacts/1-2-user-login.md",
+		"implementation-artifacts/sprint-status.yaml",
+		"planning-artifacts/product-brief.md",
+		"planning-artifacts/requirements.md",
+		"planning-artifacts/ux-notes.md",
+	}, paths, "hidden .autobmad/ must be skipped")
+
+	prd, ok := catalog.Get("planning-artifacts/requirements.md")
+	require.True(t, ok)
+	assert.Equal(t, "prd", prd.Type, "type comes from frontmatter, not the filename")
+	assert.Equal(t, []string{"1", "2", "3"}, prd.StepsCompleted)
+	assert.Equal(t, []string{"_bmad-output/planning-artifacts/product-brief.md"}, prd.InputDocuments)
+	assert.Len(t, prd.Hash, 64)
+	assert.NotZero(t, prd.Size)
+	modified, err := time.Parse(time.RFC3339Nano, prd.Modified)
+	require.NoError(t, err)
+	assert.True(t, modified.Equal(base.Add(time.Minute)))
+	assert.False(t, prd.Stale, "the brief is older than the PRD")
+
+	// The UX notes were generated before the PRD they are based on changed
+	ux, _ := catalog.Get("planning-artifacts/ux-notes.md")
+	assert.True(t, ux.Stale)
+	assert.Equal(t, []string{"planning-artifacts/requirements.md"}, ux.StaleInputs)
+
+	story, _ := catalog.Get("implementation-artifacts/1-2-user-login.md")
+	assert.Equal(t, "story", story.Type)
+	status, _ := catalog.Get("implementation-artifacts/sprint-status.yaml")
+	assert.Equal(t, "sprint-status", status.Type)
+	research, _ := catalog.Get("analysis/market-research.md")
+	assert.Equal(t, "research", research.Type)
+}
+
+func TestBuildCatalog_NoOutputFolder(t *testing.T) {
+	catalog, err := BuildCatalog(t.TempDir())
+	require.NoError(t, err)
+	assert.Empty(t, catalog.Artifacts)
+}
+
+func TestParseFrontmatter(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]interface{}
+	}{
+		{"valid", "---\ntitle: PRD\n---\n# Body", map[string]interface{}{"title": "PRD"}},
+		{"crlf", "---\r\ntitle: PRD\r\n---\r\n", map[string]interface{}{"title": "PRD"}},
+		{"none", "# Body\n---\ntitle: x\n---\n", nil},
+		{"unterminated", "---\ntitle: PRD\n# Body", nil},
+		{"invalid yaml", "---\ntitle: [unclosed\n---\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseFrontmatter([]byte(tt.content)))
+		})
+	}
+}
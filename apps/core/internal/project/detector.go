@@ -19,9 +19,20 @@ const (
 
 type Artifact struct {
 	Name     string `json:"name"`
-	Path     string `json:"path"`
+	Path     string `json:"path"` // Relative to _bmad-output/
 	Type     string `json:"type"` // prd, architecture, epics, etc.
 	Modified string `json:"modified"`
+	Size     int64  `json:"size"`
+	Hash     string `json:"hash"` // SHA-256 of the content
+
+	// Markdown frontmatter, if present
+	Frontmatter    map[string]interface{} `json:"frontmatter,omitempty"`
+	StepsCompleted []string               `json:"stepsCompleted,omitempty"`
+	InputDocuments []string               `json:"inputDocuments,omitempty"`
+
+	// Stale is set when an input document changed after the artifact was generated
+	Stale       bool     `json:"stale"`
+	StaleInputs []string `json:"staleInputs,omitempty"`
 }
 
 type ProjectScanResult struct {
@@ -142,7 +153,8 @@ func parseVersion(version string) [3]int {
 	return parts
 }
 
-// scanArtifacts scans _bmad-output for existing artifacts
+// scanArtifacts scans _bmad-output for existing planning artifacts, which
+// decide whether a project is brownfield. BuildCatalog lists everything.
 func scanArtifacts(outputPath string) []Artifact {
 	artifacts := []Artifact{}
 
@@ -151,16 +163,16 @@ func scanArtifacts(outputPath string) []Artifact {
 	if entries, err := os.ReadDir(planningPath); err == nil {
 		for _, entry := range entries {
 			if !entry.IsDir() && filepath.Ext(entry.Name()) == ".md" {
-				artifactType := detectArtifactType(entry.Name())
-				artifacts = append(artifacts, Artifact{
-					Name: entry.Name(),
-					Path: filepath.Join("planning-artifacts", entry.Name()),
-					Type: artifactType,
-				})
+				artifact, err := ReadArtifact(outputPath, filepath.Join("planning-artifacts", entry.Name()))
+				if err != nil {
+					continue
+				}
+				artifacts = append(artifacts, *artifact)
 			}
 		}
 	}
 
+	markStale(filepath.Dir(outputPath), artifacts)
 	return artifacts
 }
 
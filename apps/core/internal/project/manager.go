@@ -1,8 +1,9 @@
 package project
 
 import (
-	"os"
-	"path/filepath"
+	"fmt"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/paths"
 )
 
 var (
@@ -10,18 +11,17 @@ var (
 	recentManager *RecentManager
 )
 
-// InitRecentManager initializes the global recent manager
+// InitRecentManager initializes the global recent manager. Without a config
+// directory the list is kept in memory and an error is returned.
 func InitRecentManager() error {
-	// Use user config directory for storing recent projects
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return err
-	}
+	// Use the user config directory for storing recent projects
+	configPath := paths.ConfigFile("recent-projects.json")
 
-	configDir := filepath.Join(homeDir, ".config", "auto-bmad")
-	configPath := filepath.Join(configDir, "recent-projects.json")
-
-	recentManager = NewRecentManager(configPath, 10) // Keep last 10 projects
+	// The limit is applied from the recentProjectsMax setting once loaded
+	recentManager = NewRecentManager(configPath, DefaultMaxRecent)
+	if configPath == "" {
+		return fmt.Errorf("no config directory: recent projects are not saved")
+	}
 	return nil
 }
 
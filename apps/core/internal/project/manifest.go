@@ -0,0 +1,207 @@
+package project
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WorkflowEntry is a row of _bmad/_config/workflow-manifest.csv.
+type WorkflowEntry struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Module      string `json:"module"`
+	Path        string `json:"path"` // Relative to the project root
+}
+
+// AgentEntry is a row of _bmad/_config/agent-manifest.csv.
+type AgentEntry struct {
+	Name               string `json:"name"`
+	DisplayName        string `json:"displayName"`
+	Title              string `json:"title"`
+	Icon               string `json:"icon"`
+	Role               string `json:"role"`
+	Identity           string `json:"identity"`
+	CommunicationStyle string `json:"communicationStyle"`
+	Principles         string `json:"principles"`
+	Module             string `json:"module"`
+	Path               string `json:"path"`
+}
+
+// readCSV reads a BMAD manifest CSV and returns its rows keyed by header name.
+// Values are HTML-unescaped because the installer writes entities like &apos;.
+func readCSV(path string) ([]map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", filepath.Base(path), err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, name := range header {
+			if i < len(record) {
+				row[strings.TrimSpace(name)] = html.UnescapeString(record[i])
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// LoadWorkflowManifest reads all workflows declared in the project's manifest.
+func LoadWorkflowManifest(projectPath string) ([]WorkflowEntry, error) {
+	rows, err := readCSV(filepath.Join(projectPath, "_bmad", "_config", "workflow-manifest.csv"))
+	if err != nil {
+		return nil, err
+	}
+
+	workflows := make([]WorkflowEntry, 0, len(rows))
+	for _, row := range rows {
+		if row["name"] == "" {
+			continue
+		}
+		workflows = append(workflows, WorkflowEntry{
+			Name:        row["name"],
+			Description: row["description"],
+			Module:      row["module"],
+			Path:        row["path"],
+		})
+	}
+	return workflows, nil
+}
+
+// FindWorkflow returns the manifest entry for the named workflow.
+func FindWorkflow(projectPath, name string) (*WorkflowEntry, error) {
+	workflows, err := LoadWorkflowManifest(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	for i := range workflows {
+		if workflows[i].Name == name {
+			return &workflows[i], nil
+		}
+	}
+	return nil, fmt.Errorf("workflow not found in manifest: %s", name)
+}
+
+// LoadAgentManifest reads all agents declared in the project's manifest.
+func LoadAgentManifest(projectPath string) ([]AgentEntry, error) {
+	rows, err := readCSV(filepath.Join(projectPath, "_bmad", "_config", "agent-manifest.csv"))
+	if err != nil {
+		return nil, err
+	}
+
+	agents := make([]AgentEntry, 0, len(rows))
+	for _, row := range rows {
+		if row["name"] == "" {
+			continue
+		}
+		agents = append(agents, AgentEntry{
+			Name:               row["name"],
+			DisplayName:        row["displayName"],
+			Title:              row["title"],
+			Icon:               row["icon"],
+			Role:               row["role"],
+			Identity:           row["identity"],
+			CommunicationStyle: row["communicationStyle"],
+			Principles:         row["principles"],
+			Module:             row["module"],
+			Path:               row["path"],
+		})
+	}
+	return agents, nil
+}
+
+// AgentForWorkflow returns the agent persona that runs a workflow.
+// The mapping comes from _bmad/_config/bmad-help.csv (workflow-file -> agent-name).
+// Returns nil without error if the workflow has no associated agent.
+func AgentForWorkflow(projectPath string, workflow WorkflowEntry) (*AgentEntry, error) {
+	rows, err := readCSV(filepath.Join(projectPath, "_bmad", "_config", "bmad-help.csv"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	agentName := ""
+	for _, row := range rows {
+		if row["workflow-file"] == workflow.Path && row["agent-name"] != "" {
+			agentName = row["agent-name"]
+			break
+		}
+	}
+	if agentName == "" {
+		return nil, nil
+	}
+
+	agents, err := LoadAgentManifest(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	for i := range agents {
+		if agents[i].Name == agentName {
+			return &agents[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// ConfigValue is a single key of a module config.yaml.
+type ConfigValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// LoadModuleConfig reads _bmad/<module>/config.yaml and returns its scalar values
+// sorted by key, with the {project-root} placeholder resolved.
+func LoadModuleConfig(projectPath, module string) ([]ConfigValue, error) {
+	if module == "" || strings.ContainsAny(module, `/\`) || module == ".." {
+		return nil, fmt.Errorf("invalid module name: %q", module)
+	}
+
+	data, err := os.ReadFile(filepath.Join(projectPath, "_bmad", module, "config.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parsing %s config: %w", module, err)
+	}
+
+	values := make([]ConfigValue, 0, len(raw))
+	for key, value := range raw {
+		switch v := value.(type) {
+		case map[string]interface{}, []interface{}, nil:
+			continue
+		default:
+			s := strings.ReplaceAll(fmt.Sprint(v), "{project-root}", projectPath)
+			values = append(values, ConfigValue{Key: key, Value: s})
+		}
+	}
+
+	sort.Slice(values, func(i, j int) bool { return values[i].Key < values[j].Key })
+	return values, nil
+}
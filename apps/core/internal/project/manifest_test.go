@@ -0,0 +1,85 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createManifestProject writes a minimal BMAD manifest set into a temp project.
+func createManifestProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"_bmad/_config/workflow-manifest.csv": "name,description,module,path\n" +
+			`"prd","Create, Validate, or Edit PRDs","bmm","_bmad/bmm/workflows/prd/workflow.md"` + "\n" +
+			`"brainstorming","Ideas","core","_bmad/core/workflows/brainstorming/workflow.md"` + "\n",
+		"_bmad/_config/agent-manifest.csv": "name,displayName,title,icon,role,identity,communicationStyle,principles,module,path\n" +
+			`"pm","John","Product Manager","📋","PM","Asks &apos;WHY?&apos;","Direct","Ship value","bmm","_bmad/bmm/agents/pm.md"` + "\n",
+		"_bmad/_config/bmad-help.csv": "module,phase,name,code,sequence,workflow-file,command,required,agent-name\n" +
+			"bmm,2-planning,Create PRD,CP,10,_bmad/bmm/workflows/prd/workflow.md,bmad_bmm_create-prd,true,pm\n",
+		"_bmad/bmm/config.yaml": "project_name: demo\nplanning_artifacts: \"{project-root}/_bmad-output/planning-artifacts\"\nnested:\n  key: ignored\n",
+	}
+	for rel, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestLoadWorkflowManifest(t *testing.T) {
+	dir := createManifestProject(t)
+
+	workflows, err := LoadWorkflowManifest(dir)
+	require.NoError(t, err)
+	require.Len(t, workflows, 2)
+	assert.Equal(t, "prd", workflows[0].Name)
+	assert.Equal(t, "Create, Validate, or Edit PRDs", workflows[0].Description)
+	assert.Equal(t, "bmm", workflows[0].Module)
+}
+
+func TestFindWorkflow_NotFound(t *testing.T) {
+	dir := createManifestProject(t)
+
+	_, err := FindWorkflow(dir, "missing")
+	assert.Error(t, err)
+}
+
+func TestAgentForWorkflow(t *testing.T) {
+	dir := createManifestProject(t)
+
+	wf, err := FindWorkflow(dir, "prd")
+	require.NoError(t, err)
+
+	agent, err := AgentForWorkflow(dir, *wf)
+	require.NoError(t, err)
+	require.NotNil(t, agent)
+	assert.Equal(t, "John", agent.DisplayName)
+	assert.Equal(t, "Asks 'WHY?'", agent.Identity, "HTML entities should be unescaped")
+
+	// Workflow without a mapping has no agent
+	wf, err = FindWorkflow(dir, "brainstorming")
+	require.NoError(t, err)
+	agent, err = AgentForWorkflow(dir, *wf)
+	require.NoError(t, err)
+	assert.Nil(t, agent)
+}
+
+func TestLoadModuleConfig(t *testing.T) {
+	dir := createManifestProject(t)
+
+	values, err := LoadModuleConfig(dir, "bmm")
+	require.NoError(t, err)
+	require.Len(t, values, 2, "nested values should be skipped")
+	assert.Equal(t, "planning_artifacts", values[0].Key)
+	assert.Equal(t, dir+"/_bmad-output/planning-artifacts", values[0].Value)
+	assert.Equal(t, "project_name", values[1].Key)
+
+	_, err = LoadModuleConfig(dir, "../etc")
+	assert.Error(t, err)
+}
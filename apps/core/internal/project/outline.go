@@ -0,0 +1,106 @@
+package project
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Heading is a markdown heading with the headings nested under it.
+type Heading struct {
+	Level    int        `json:"level"` // 1-6
+	Text     string     `json:"text"`
+	Line     int        `json:"line"`   // 1-based line in the file
+	Anchor   string     `json:"anchor"` // GitHub-style slug, unique within the document
+	Children []*Heading `json:"children,omitempty"`
+}
+
+// atxHeadingPattern matches "## Title" headings, with optional closing hashes.
+var atxHeadingPattern = regexp.MustCompile(`^ {0,3}(#{1,6})[ \t]+(.*?)(?:[ \t]+#+)?[ \t]*$`)
+
+// Outline returns the heading tree of a markdown document. Headings inside
+// frontmatter and fenced code blocks are ignored.
+func Outline(data []byte) []*Heading {
+	roots := []*Heading{}
+	var stack []*Heading
+	anchors := make(map[string]int)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	fence := ""
+	inFrontmatter := false
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		trimmed := strings.TrimSpace(text)
+
+		if line == 1 && trimmed == "---" {
+			inFrontmatter = true
+			continue
+		}
+		if inFrontmatter {
+			if trimmed == "---" || trimmed == "..." {
+				inFrontmatter = false
+			}
+			continue
+		}
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+
+		m := atxHeadingPattern.FindStringSubmatch(text)
+		if m == nil {
+			continue
+		}
+		h := &Heading{Level: len(m[1]), Text: strings.TrimSpace(m[2]), Line: line}
+		h.Anchor = uniqueAnchor(anchors, slugify(h.Text))
+
+		for len(stack) > 0 && stack[len(stack)-1].Level >= h.Level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			roots = append(roots, h)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, h)
+		}
+		stack = append(stack, h)
+	}
+	return roots
+}
+
+// slugify builds a GitHub-style anchor: lowercase, punctuation removed,
+// spaces turned into hyphens.
+func slugify(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
+
+// uniqueAnchor appends -1, -2, ... to repeated anchors.
+func uniqueAnchor(seen map[string]int, anchor string) string {
+	n := seen[anchor]
+	seen[anchor] = n + 1
+	if n == 0 {
+		return anchor
+	}
+	return fmt.Sprintf("%s-%d", anchor, n)
+}
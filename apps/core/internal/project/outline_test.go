@@ -0,0 +1,38 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutline(t *testing.T) {
+	doc := "---\ntitle: '# not a heading'\n---\n" +
+		"# PRD\n" +
+		"## Goals\n" +
+		"### Success Criteria ##\n" +
+		"```markdown\n## Inside a fence\n```\n" +
+		"## Goals\n" +
+		"#NoSpace\n" +
+		"# Appendix\n"
+
+	headings := Outline([]byte(doc))
+	require.Len(t, headings, 2)
+
+	prd := headings[0]
+	assert.Equal(t, 1, prd.Level)
+	assert.Equal(t, "PRD", prd.Text)
+	assert.Equal(t, 4, prd.Line)
+	require.Len(t, prd.Children, 2)
+
+	goals := prd.Children[0]
+	assert.Equal(t, "goals", goals.Anchor)
+	require.Len(t, goals.Children, 1)
+	assert.Equal(t, "Success Criteria", goals.Children[0].Text)
+	assert.Equal(t, "success-criteria", goals.Children[0].Anchor)
+
+	assert.Equal(t, "goals-1", prd.Children[1].Anchor, "repeated headings get unique anchors")
+	assert.Equal(t, 10, prd.Children[1].Line)
+	assert.Equal(t, "Appendix", headings[1].Text)
+}
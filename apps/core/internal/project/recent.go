@@ -5,28 +5,91 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/filelock"
 )
 
+// DefaultMaxRecent is the number of unpinned projects kept when no limit
+// is configured.
+const DefaultMaxRecent = 10
+
 // RecentProject represents a recently opened BMAD project
 type RecentProject struct {
-	Path       string    `json:"path"`
-	Name       string    `json:"name"`
-	LastOpened time.Time `json:"lastOpened"`
-	Context    string    `json:"context,omitempty"`
+	Path       string         `json:"path"`
+	Name       string         `json:"name"`
+	LastOpened time.Time      `json:"lastOpened"`
+	Context    string         `json:"context,omitempty"`
+	Pinned     bool           `json:"pinned,omitempty"` // Never evicted by the limit
+	Health     *ProjectHealth `json:"health,omitempty"` // Cached scan summary; nil until checked
+}
+
+// Health statuses of a recent project.
+const (
+	HealthOK      = "ok"
+	HealthMissing = "missing" // Deleted or moved
+	HealthNotBMAD = "notBmad" // Exists but BMAD is no longer installed
+)
+
+// ProjectHealth is the cached scan summary of a recent project.
+type ProjectHealth struct {
+	Status      string          `json:"status"`
+	ProjectType ProjectType     `json:"projectType,omitempty"`
+	BmadVersion string          `json:"bmadVersion,omitempty"`
+	LastJourney *JourneyOutcome `json:"lastJourney,omitempty"`
+	CheckedAt   time.Time       `json:"checkedAt"`
+}
+
+// JourneyOutcome summarizes the last finished journey of a project.
+type JourneyOutcome struct {
+	ID          string    `json:"id"`
+	Destination string    `json:"destination"`
+	Status      string    `json:"status"` // completed, failed or aborted
+	CompletedAt time.Time `json:"completedAt"`
+}
+
+// CheckHealth scans a project directory for its health summary. The last
+// journey is left for the caller to fill in.
+func CheckHealth(path string) *ProjectHealth {
+	health := &ProjectHealth{Status: HealthMissing, CheckedAt: time.Now()}
+	info, err := os.Stat(path)
+	if err != nil || !info.IsDir() {
+		return health
+	}
+
+	result, err := Scan(path)
+	if err != nil || !result.IsBMAD {
+		health.Status = HealthNotBMAD
+		health.ProjectType = TypeNotBMAD
+		return health
+	}
+	health.Status = HealthOK
+	health.ProjectType = result.ProjectType
+	health.BmadVersion = result.BmadVersion
+	return health
 }
 
-// RecentManager manages the list of recently opened projects
+// RecentManager manages the list of recently opened projects.
+// The file is shared by all running instances: changes hold an advisory
+// lock on it and start from its current content, and reads pick up changes
+// made by other instances. Without a file path the list is kept in memory.
 type RecentManager struct {
 	configPath string
 	projects   []RecentProject
 	maxRecent  int
+	modTime    time.Time // Of the file as last read or written
+	size       int64
 	mu         sync.RWMutex
 }
 
-// NewRecentManager creates a new RecentManager instance
+// NewRecentManager creates a new RecentManager instance. An empty configPath
+// keeps the list in memory only.
 func NewRecentManager(configPath string, maxRecent int) *RecentManager {
+	if maxRecent < 1 {
+		maxRecent = DefaultMaxRecent
+	}
 	rm := &RecentManager{
 		configPath: configPath,
 		maxRecent:  maxRecent,
@@ -45,37 +108,116 @@ func (rm *RecentManager) Add(path string) error {
 		return fmt.Errorf("path cannot be empty")
 	}
 
-	rm.mu.Lock()
-	defer rm.mu.Unlock()
-
-	// Remove if already exists (to update timestamp and move to front)
-	rm.removeWithoutLock(path)
+	return rm.update(func() error {
+		rm.addWithoutLock(path)
+		return nil
+	})
+}
 
-	// Create new entry
+// addWithoutLock moves or adds a project to the front of the list (internal use)
+func (rm *RecentManager) addWithoutLock(path string) {
+	// Create new entry, keeping the pin and health of an existing one
 	project := RecentProject{
 		Path:       path,
 		Name:       SanitizeProjectName(filepath.Base(path)),
 		LastOpened: time.Now(),
 	}
+	if i := rm.indexWithoutLock(path); i >= 0 {
+		project.Pinned = rm.projects[i].Pinned
+		project.Health = rm.projects[i].Health
+	}
+
+	// Remove if already exists (to update timestamp and move to front)
+	rm.removeWithoutLock(path)
 
 	// Add to front of list
 	rm.projects = append([]RecentProject{project}, rm.projects...)
 
-	// Trim to max size
-	if len(rm.projects) > rm.maxRecent {
-		rm.projects = rm.projects[:rm.maxRecent]
+	rm.trimWithoutLock()
+}
+
+// trimWithoutLock drops the oldest unpinned projects beyond the limit (internal use)
+func (rm *RecentManager) trimWithoutLock() {
+	kept := rm.projects[:0]
+	unpinned := 0
+	for _, p := range rm.projects {
+		if !p.Pinned {
+			if unpinned >= rm.maxRecent {
+				continue
+			}
+			unpinned++
+		}
+		kept = append(kept, p)
 	}
+	rm.projects = kept
+}
 
-	return rm.save()
+// indexWithoutLock returns the position of a project, or -1 (internal use)
+func (rm *RecentManager) indexWithoutLock(path string) int {
+	for i, p := range rm.projects {
+		if p.Path == path {
+			return i
+		}
+	}
+	return -1
+}
+
+// SetMaxRecent changes how many unpinned projects are kept and drops the
+// oldest ones beyond the new limit. Values below 1 restore the default.
+func (rm *RecentManager) SetMaxRecent(maxRecent int) error {
+	if maxRecent < 1 {
+		maxRecent = DefaultMaxRecent
+	}
+	if rm.MaxRecent() == maxRecent {
+		return nil
+	}
+	return rm.update(func() error {
+		rm.maxRecent = maxRecent
+		rm.trimWithoutLock()
+		return nil
+	})
+}
+
+// MaxRecent returns how many unpinned projects are kept.
+func (rm *RecentManager) MaxRecent() int {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	return rm.maxRecent
+}
+
+// Pin pins or unpins a project. Pinned projects are listed first and never
+// dropped by the limit; unpinning may drop the oldest unpinned project.
+func (rm *RecentManager) Pin(path string, pinned bool) error {
+	return rm.update(func() error {
+		i := rm.indexWithoutLock(path)
+		if i < 0 {
+			return fmt.Errorf("project not found: %s", path)
+		}
+		rm.projects[i].Pinned = pinned
+		rm.trimWithoutLock()
+		return nil
+	})
+}
+
+// SetHealth stores health summaries by project path. Paths no longer in the
+// list are ignored.
+func (rm *RecentManager) SetHealth(health map[string]*ProjectHealth) error {
+	return rm.update(func() error {
+		for i := range rm.projects {
+			if h, ok := health[rm.projects[i].Path]; ok {
+				rm.projects[i].Health = h
+			}
+		}
+		return nil
+	})
 }
 
 // Remove removes a project from the recent list
 func (rm *RecentManager) Remove(path string) error {
-	rm.mu.Lock()
-	defer rm.mu.Unlock()
-
-	rm.removeWithoutLock(path)
-	return rm.save()
+	return rm.update(func() error {
+		rm.removeWithoutLock(path)
+		return nil
+	})
 }
 
 // removeWithoutLock removes a project without acquiring lock (internal use)
@@ -97,27 +239,113 @@ func (rm *RecentManager) SetContext(path string, context string) error {
 		return fmt.Errorf("invalid context: %w", err)
 	}
 
+	return rm.update(func() error {
+		for i := range rm.projects {
+			if rm.projects[i].Path == path {
+				rm.projects[i].Context = sanitizedContext
+				return nil
+			}
+		}
+		return fmt.Errorf("project not found: %s", path)
+	})
+}
+
+// update runs a read-modify-write cycle: it locks the file, reloads it if
+// another instance changed it, applies fn and saves. Nothing is saved if fn
+// fails.
+func (rm *RecentManager) update(fn func() error) error {
+	if rm.configPath != "" {
+		lock, err := filelock.Acquire(rm.configPath)
+		if err != nil {
+			return err
+		}
+		defer lock.Unlock()
+	}
+
 	rm.mu.Lock()
 	defer rm.mu.Unlock()
 
-	for i := range rm.projects {
-		if rm.projects[i].Path == path {
-			rm.projects[i].Context = sanitizedContext
-			return rm.save()
-		}
+	rm.refreshWithoutLock()
+	if err := fn(); err != nil {
+		return err
+	}
+	return rm.save()
+}
+
+// refresh reloads the file if another instance changed it since it was
+// last read or written.
+func (rm *RecentManager) refresh() {
+	rm.mu.RLock()
+	stale := rm.changedOnDisk()
+	rm.mu.RUnlock()
+	if !stale {
+		return
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.refreshWithoutLock()
+}
+
+// refreshWithoutLock reloads the file if it changed (internal use)
+func (rm *RecentManager) refreshWithoutLock() {
+	if rm.changedOnDisk() {
+		rm.loadWithoutLock()
 	}
+}
 
-	return fmt.Errorf("project not found: %s", path)
+// changedOnDisk reports whether the file's modification time or size
+// differs from when it was last read or written
+func (rm *RecentManager) changedOnDisk() bool {
+	if rm.configPath == "" {
+		return false
+	}
+	info, err := os.Stat(rm.configPath)
+	if err != nil {
+		return !rm.modTime.IsZero()
+	}
+	return !info.ModTime().Equal(rm.modTime) || info.Size() != rm.size
 }
 
-// GetAll returns all recent projects, sorted by most recent first
+// recordStat remembers the file's modification time and size
+func (rm *RecentManager) recordStat() {
+	rm.modTime, rm.size = time.Time{}, 0
+	if rm.configPath == "" {
+		return
+	}
+	if info, err := os.Stat(rm.configPath); err == nil {
+		rm.modTime, rm.size = info.ModTime(), info.Size()
+	}
+}
+
+// GetContext returns the context description stored for a project.
+// Returns an empty string if the project is not in the recent list.
+func (rm *RecentManager) GetContext(path string) string {
+	rm.refresh()
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	for _, p := range rm.projects {
+		if p.Path == path {
+			return p.Context
+		}
+	}
+	return ""
+}
+
+// GetAll returns all recent projects, pinned ones first, each group sorted
+// by most recent first
 func (rm *RecentManager) GetAll() ([]RecentProject, error) {
+	rm.refresh()
 	rm.mu.RLock()
 	defer rm.mu.RUnlock()
 
 	// Return a copy to prevent external modification
 	result := make([]RecentProject, len(rm.projects))
 	copy(result, rm.projects)
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Pinned && !result[j].Pinned
+	})
 
 	return result, nil
 }
@@ -126,8 +354,18 @@ func (rm *RecentManager) GetAll() ([]RecentProject, error) {
 func (rm *RecentManager) load() error {
 	rm.mu.Lock()
 	defer rm.mu.Unlock()
+	return rm.loadWithoutLock()
+}
+
+// loadWithoutLock loads projects without acquiring lock (internal use)
+func (rm *RecentManager) loadWithoutLock() error {
+	rm.recordStat()
 
 	// If file doesn't exist, start with empty list
+	if rm.configPath == "" {
+		rm.projects = []RecentProject{}
+		return nil
+	}
 	if _, err := os.Stat(rm.configPath); os.IsNotExist(err) {
 		rm.projects = []RecentProject{}
 		return nil
@@ -154,8 +392,12 @@ func (rm *RecentManager) load() error {
 	return nil
 }
 
-// save persists projects to the config file
+// save persists projects to the config file atomically
 func (rm *RecentManager) save() error {
+	if rm.configPath == "" {
+		return nil
+	}
+
 	// Ensure directory exists
 	dir := filepath.Dir(rm.configPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -167,9 +409,15 @@ func (rm *RecentManager) save() error {
 		return fmt.Errorf("marshaling recent projects: %w", err)
 	}
 
-	if err := os.WriteFile(rm.configPath, data, 0644); err != nil {
+	// Atomic write: write to temp file, then rename
+	tempPath := rm.configPath + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0644); err != nil {
 		return fmt.Errorf("writing recent projects file: %w", err)
 	}
+	if err := os.Rename(tempPath, rm.configPath); err != nil {
+		return fmt.Errorf("renaming recent projects file: %w", err)
+	}
 
+	rm.recordStat()
 	return nil
 }
@@ -1,10 +1,14 @@
 package project
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/paths"
 )
 
 func TestRecentManager_Add(t *testing.T) {
@@ -204,3 +208,205 @@ func TestRecentManager_CorruptedFile(t *testing.T) {
 		t.Error("Expected empty list or error for corrupted file")
 	}
 }
+
+func TestRecentManager_GetContext(t *testing.T) {
+	tmpDir := t.TempDir()
+	rm := NewRecentManager(filepath.Join(tmpDir, "recent.json"), 5)
+
+	if err := rm.Add("/home/user/project1"); err != nil {
+		t.Fatalf("Failed to add project: %v", err)
+	}
+	if err := rm.SetContext("/home/user/project1", "Inventory service"); err != nil {
+		t.Fatalf("Failed to set context: %v", err)
+	}
+
+	if got := rm.GetContext("/home/user/project1"); got != "Inventory service" {
+		t.Errorf("Expected context 'Inventory service', got %q", got)
+	}
+	if got := rm.GetContext("/home/user/unknown"); got != "" {
+		t.Errorf("Expected empty context for unknown project, got %q", got)
+	}
+}
+
+func TestRecentManager_SharedFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "recent.json")
+
+	// Two instances started before either wrote
+	rm1 := NewRecentManager(configPath, 5)
+	rm2 := NewRecentManager(configPath, 5)
+
+	if err := rm1.Add("/home/user/project1"); err != nil {
+		t.Fatalf("Failed to add project: %v", err)
+	}
+	if err := rm2.Add("/home/user/project2"); err != nil {
+		t.Fatalf("Failed to add project: %v", err)
+	}
+
+	// rm2 kept rm1's entry, and rm1 sees rm2's
+	projects, _ := rm1.GetAll()
+	if len(projects) != 2 || projects[0].Path != "/home/user/project2" {
+		t.Errorf("Expected both projects, newest first, got %+v", projects)
+	}
+
+	// Written atomically: no temp file left behind
+	if _, err := os.Stat(configPath + ".tmp"); !os.IsNotExist(err) {
+		t.Error("Temp file left after save")
+	}
+}
+
+func TestRecentManager_Pin(t *testing.T) {
+	tmpDir := t.TempDir()
+	rm := NewRecentManager(filepath.Join(tmpDir, "recent.json"), 2)
+
+	rm.Add("/home/user/project1")
+	if err := rm.Pin("/home/user/project1", true); err != nil {
+		t.Fatalf("Failed to pin project: %v", err)
+	}
+	rm.Add("/home/user/project2")
+	rm.Add("/home/user/project3")
+	rm.Add("/home/user/project4")
+
+	// The pinned project survives the limit and is listed first
+	projects, _ := rm.GetAll()
+	var paths []string
+	for _, p := range projects {
+		paths = append(paths, p.Path)
+	}
+	want := []string{"/home/user/project1", "/home/user/project4", "/home/user/project3"}
+	if strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected %v, got %v", want, paths)
+	}
+
+	// Re-adding keeps the pin
+	rm.Add("/home/user/project1")
+	projects, _ = rm.GetAll()
+	if !projects[0].Pinned {
+		t.Error("Expected project1 to stay pinned after re-adding")
+	}
+
+	// Unpinning makes it subject to the limit again
+	rm.Add("/home/user/project5")
+	if err := rm.Pin("/home/user/project1", false); err != nil {
+		t.Fatalf("Failed to unpin project: %v", err)
+	}
+	projects, _ = rm.GetAll()
+	if len(projects) != 2 || projects[0].Path != "/home/user/project5" {
+		t.Errorf("Expected the two newest projects after unpinning, got %+v", projects)
+	}
+
+	if err := rm.Pin("/home/user/unknown", true); err == nil {
+		t.Error("Expected error pinning an unknown project")
+	}
+}
+
+func TestRecentManager_SetMaxRecent(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "recent.json")
+	rm := NewRecentManager(configPath, 5)
+
+	for i := 1; i <= 5; i++ {
+		rm.Add(filepath.Join("/home/user", fmt.Sprintf("project%d", i)))
+	}
+	rm.Pin("/home/user/project1", true)
+
+	if err := rm.SetMaxRecent(2); err != nil {
+		t.Fatalf("SetMaxRecent failed: %v", err)
+	}
+	if rm.MaxRecent() != 2 {
+		t.Errorf("Expected limit 2, got %d", rm.MaxRecent())
+	}
+
+	// Saved: another instance sees the trimmed list
+	projects, _ := NewRecentManager(configPath, 5).GetAll()
+	if len(projects) != 3 || projects[0].Path != "/home/user/project1" {
+		t.Errorf("Expected pinned project plus the two newest, got %+v", projects)
+	}
+
+	// Out-of-range values restore the default
+	rm.SetMaxRecent(0)
+	if rm.MaxRecent() != DefaultMaxRecent {
+		t.Errorf("Expected default limit %d, got %d", DefaultMaxRecent, rm.MaxRecent())
+	}
+}
+
+func TestRecentManager_SetHealth(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "recent.json")
+	rm := NewRecentManager(configPath, 5)
+	rm.Add("/home/user/project1")
+
+	err := rm.SetHealth(map[string]*ProjectHealth{
+		"/home/user/project1": {Status: HealthMissing},
+		"/home/user/unknown":  {Status: HealthOK},
+	})
+	if err != nil {
+		t.Fatalf("SetHealth failed: %v", err)
+	}
+
+	projects, _ := NewRecentManager(configPath, 5).GetAll()
+	if len(projects) != 1 || projects[0].Health == nil || projects[0].Health.Status != HealthMissing {
+		t.Errorf("Expected stored missing status, got %+v", projects)
+	}
+}
+
+func TestCheckHealth(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	if h := CheckHealth(filepath.Join(tmpDir, "moved")); h.Status != HealthMissing {
+		t.Errorf("Expected missing for a moved project, got %q", h.Status)
+	}
+	if h := CheckHealth(tmpDir); h.Status != HealthNotBMAD || h.ProjectType != TypeNotBMAD {
+		t.Errorf("Expected notBmad for a plain directory, got %+v", h)
+	}
+
+	manifest := filepath.Join(tmpDir, "_bmad", "_config", "manifest.yaml")
+	os.MkdirAll(filepath.Dir(manifest), 0755)
+	os.WriteFile(manifest, []byte("version: 6.0.0\n"), 0644)
+
+	h := CheckHealth(tmpDir)
+	if h.Status != HealthOK || h.ProjectType != TypeGreenfield || h.BmadVersion != "6.0.0" {
+		t.Errorf("Expected ok greenfield project, got %+v", h)
+	}
+	if h.CheckedAt.IsZero() {
+		t.Error("Expected CheckedAt to be set")
+	}
+}
+
+func TestInitRecentManager_NoConfigDir(t *testing.T) {
+	t.Setenv(paths.HomeEnv, "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("HOME", "")
+	t.Cleanup(func() { recentManager = nil })
+
+	if err := InitRecentManager(); err == nil {
+		t.Error("Expected error without a config directory")
+	}
+
+	// Kept in memory instead of failing
+	rm := GetRecentManager()
+	if rm == nil {
+		t.Fatal("Expected a recent manager without a config directory")
+	}
+	if err := rm.Add("/home/user/project1"); err != nil {
+		t.Fatalf("Failed to add project: %v", err)
+	}
+	if projects, _ := rm.GetAll(); len(projects) != 1 {
+		t.Errorf("Expected 1 project, got %d", len(projects))
+	}
+}
+
+func TestInitRecentManager_ConfigDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv(paths.HomeEnv, home)
+	t.Cleanup(func() { recentManager = nil })
+
+	if err := InitRecentManager(); err != nil {
+		t.Fatalf("InitRecentManager failed: %v", err)
+	}
+	GetRecentManager().Add("/home/user/project1")
+
+	if _, err := os.Stat(filepath.Join(home, "recent-projects.json")); err != nil {
+		t.Errorf("Expected recent projects saved in the config dir: %v", err)
+	}
+}
@@ -0,0 +1,344 @@
+// Package server provides artifact catalog and content handlers.
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/checkpoint"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/project"
+)
+
+// MaxArtifactChunkSize bounds the content returned by one artifact call.
+// Escaping can grow content up to six times in JSON, so chunks stay well
+// below MaxMessageSize.
+const MaxArtifactChunkSize = MaxMessageSize / 8
+
+// maxOutlineFileSize bounds the files artifact.outline parses.
+const maxOutlineFileSize = 10 * 1024 * 1024
+
+// Content encodings of artifact chunks.
+const (
+	EncodingUTF8   = "utf-8"
+	EncodingBase64 = "base64" // Binary content
+)
+
+// RegisterArtifactHandlers registers artifact-related JSON-RPC handlers. They
+// act on the project selected by projectPath, or the active project, which
+// is projectPath until another project is opened.
+func RegisterArtifactHandlers(s *Server, projectPath string) {
+	s.workspace.attach(projectPath, nil)
+
+	s.RegisterHandler("artifact.list", s.projectHandler(func(p *ProjectSession) Handler { return handleArtifactList(p.Path) }))
+	s.RegisterHandler("artifact.read", s.projectHandler(func(p *ProjectSession) Handler { return handleArtifactRead(p.Path) }))
+	s.RegisterHandler("artifact.outline", s.projectHandler(func(p *ProjectSession) Handler { return handleArtifactOutline(p.Path) }))
+	s.RegisterHandler("artifact.diff", s.projectHandler(func(p *ProjectSession) Handler { return handleArtifactDiff(p.Path) }))
+}
+
+// handleArtifactList returns the catalog of every artifact in _bmad-output/.
+// Method: artifact.list
+// Params: none
+// Result: Catalog
+func handleArtifactList(projectPath string) Handler {
+	return func(params json.RawMessage) (interface{}, error) {
+		catalog, err := project.BuildCatalog(projectPath)
+		if err != nil {
+			return nil, NewErrorWithData(ErrCodeInternalError, "Failed to list artifacts", err.Error())
+		}
+		return catalog, nil
+	}
+}
+
+// resolveArtifactPath confines an artifact path to _bmad-output/. Relative
+// paths are taken relative to _bmad-output/. It returns the resolved
+// absolute path and the path relative to _bmad-output/. Unless mustExist,
+// a path missing from the working tree, such as a deleted artifact that is
+// still in a checkpoint, is confined without resolving symlinks.
+func resolveArtifactPath(projectPath, path string, mustExist bool) (string, string, error) {
+	if path == "" {
+		return "", "", fmt.Errorf("path is required")
+	}
+	outputPath := filepath.Join(projectPath, "_bmad-output")
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(outputPath, path)
+	}
+
+	validator := &PathValidator{RequireDirectory: false, Root: outputPath}
+	abs, err := validator.Validate(path)
+	if err != nil {
+		if mustExist || !errors.Is(err, fs.ErrNotExist) {
+			return "", "", err
+		}
+		return missingArtifactPath(outputPath, filepath.Clean(path))
+	}
+	if info, err := os.Stat(abs); err != nil || info.IsDir() {
+		return "", "", fmt.Errorf("not a file: %s", path)
+	}
+
+	root, _ := filepath.EvalSymlinks(outputPath)
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		return "", "", err
+	}
+	return abs, rel, nil
+}
+
+// missingArtifactPath confines a path that does not exist to outputPath by
+// its name alone. The output directory may be given through a symlink.
+func missingArtifactPath(outputPath, path string) (string, string, error) {
+	roots := []string{outputPath}
+	if real, err := filepath.EvalSymlinks(outputPath); err == nil {
+		roots = append(roots, real)
+	}
+	for _, root := range roots {
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return filepath.Join(root, rel), rel, nil
+	}
+	return "", "", fmt.Errorf("%w: %s", ErrPathOutsideRoot, path)
+}
+
+// ChunkParams selects a byte range of content.
+type ChunkParams struct {
+	Offset int64 `json:"offset,omitempty"`
+	Length int   `json:"length,omitempty"` // Default and maximum MaxArtifactChunkSize
+}
+
+// validate checks the range and applies the default length.
+func (p *ChunkParams) validate() error {
+	if p.Offset < 0 {
+		return fmt.Errorf("offset must not be negative")
+	}
+	if p.Length < 0 || p.Length > MaxArtifactChunkSize {
+		return fmt.Errorf("length must be between 1 and %d", MaxArtifactChunkSize)
+	}
+	if p.Length == 0 {
+		p.Length = MaxArtifactChunkSize
+	}
+	return nil
+}
+
+// Chunk is a byte range of content. Clients read the rest by requesting
+// Offset+Length until EOF is true.
+type Chunk struct {
+	Offset   int64  `json:"offset"`
+	Length   int    `json:"length"` // Bytes of the original content in this chunk
+	Size     int64  `json:"size"`   // Total bytes
+	EOF      bool   `json:"eof"`
+	Encoding string `json:"encoding"` // utf-8 or base64
+	Content  string `json:"content"`
+}
+
+// chunkSlack is how many bytes past the requested length callers pass to
+// newChunk, enough to complete a character cut off at the end.
+const chunkSlack = utf8.UTFMax - 1
+
+// newChunk encodes up to length bytes of data read at offset from content
+// of the given size. A text chunk ends on a character boundary: a character
+// cut off at the end is dropped, or completed from the slack bytes of data
+// past length if it is the only one, so every chunk has a Length of at least 1.
+func newChunk(data []byte, offset, size int64, length int) Chunk {
+	c := Chunk{Offset: offset, Size: size, Encoding: EncodingUTF8}
+
+	raw := data[:min(length, len(data))]
+	text := raw
+	if offset+int64(len(raw)) < size {
+		for i := len(text) - 1; i >= 0 && i >= len(text)-utf8.UTFMax; i-- {
+			if utf8.RuneStart(text[i]) {
+				if utf8.FullRune(text[i:]) {
+					break
+				}
+				if i > 0 {
+					text = text[:i]
+				} else if utf8.FullRune(data) {
+					_, n := utf8.DecodeRune(data)
+					text = data[:n]
+				}
+				break
+			}
+		}
+	}
+	if len(text) > 0 && utf8.Valid(text) && !containsNUL(text) {
+		c.Content = string(text)
+		c.Length = len(text)
+	} else {
+		c.Encoding = EncodingBase64
+		c.Content = base64.StdEncoding.EncodeToString(raw)
+		c.Length = len(raw)
+	}
+	c.EOF = offset+int64(c.Length) >= size
+	return c
+}
+
+// containsNUL reports whether data has a NUL byte, a sign of binary content.
+func containsNUL(data []byte) bool {
+	for _, b := range data {
+		if b == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ArtifactReadParams are the parameters for artifact.read.
+type ArtifactReadParams struct {
+	Path string `json:"path"` // Relative to _bmad-output/, or absolute inside it
+	ChunkParams
+}
+
+// ArtifactContent is a chunk of an artifact's content.
+type ArtifactContent struct {
+	Path string `json:"path"`
+	Chunk
+}
+
+// handleArtifactRead returns a chunk of an artifact's content.
+// Method: artifact.read
+// Params: { "path": string, "offset"?: number, "length"?: number }
+// Result: { "path", "offset", "length", "size", "eof", "encoding", "content" }
+func handleArtifactRead(projectPath string) Handler {
+	return func(params json.RawMessage) (interface{}, error) {
+		var p ArtifactReadParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", err.Error())
+		}
+		if err := p.validate(); err != nil {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", err.Error())
+		}
+		abs, rel, err := resolveArtifactPath(projectPath, p.Path, true)
+		if err != nil {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid path", err.Error())
+		}
+
+		f, err := os.Open(abs)
+		if err != nil {
+			return nil, NewErrorWithData(ErrCodeInternalError, "Failed to read artifact", err.Error())
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			return nil, NewErrorWithData(ErrCodeInternalError, "Failed to read artifact", err.Error())
+		}
+
+		buf := make([]byte, p.Length+chunkSlack)
+		n, err := f.ReadAt(buf, p.Offset)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, NewErrorWithData(ErrCodeInternalError, "Failed to read artifact", err.Error())
+		}
+		return ArtifactContent{Path: rel, Chunk: newChunk(buf[:n], p.Offset, info.Size(), p.Length)}, nil
+	}
+}
+
+// ArtifactPathParams identifies an artifact.
+type ArtifactPathParams struct {
+	Path string `json:"path"`
+}
+
+// ArtifactOutline is the heading tree of a markdown artifact.
+type ArtifactOutline struct {
+	Path     string             `json:"path"`
+	Headings []*project.Heading `json:"headings"`
+}
+
+// handleArtifactOutline returns the heading tree of a markdown artifact.
+// Method: artifact.outline
+// Params: { "path": string }
+// Result: { "path": string, "headings": Heading[] }
+func handleArtifactOutline(projectPath string) Handler {
+	return func(params json.RawMessage) (interface{}, error) {
+		var p ArtifactPathParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", err.Error())
+		}
+		abs, rel, err := resolveArtifactPath(projectPath, p.Path, true)
+		if err != nil {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid path", err.Error())
+		}
+		if ext := filepath.Ext(rel); ext != ".md" && ext != ".markdown" {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid path", "outline is only available for markdown artifacts")
+		}
+		if info, err := os.Stat(abs); err == nil && info.Size() > maxOutlineFileSize {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid path", "artifact is too large to outline")
+		}
+
+		data, err := os.ReadFile(abs)
+		if err != nil {
+			return nil, NewErrorWithData(ErrCodeInternalError, "Failed to read artifact", err.Error())
+		}
+		return ArtifactOutline{Path: rel, Headings: project.Outline(data)}, nil
+	}
+}
+
+// ArtifactDiffParams are the parameters for artifact.diff.
+type ArtifactDiffParams struct {
+	Path string `json:"path"`
+	From string `json:"from,omitempty"` // Checkpoint SHA; default HEAD
+	To   string `json:"to,omitempty"`   // Checkpoint SHA; default the working tree
+	ChunkParams
+}
+
+// ArtifactDiff is a chunk of the unified diff of an artifact.
+type ArtifactDiff struct {
+	Path string `json:"path"`
+	From string `json:"from"`
+	To   string `json:"to"` // Empty for the working tree
+	Chunk
+}
+
+// handleArtifactDiff returns the diff of an artifact between two checkpoints,
+// or between a checkpoint and the working tree.
+// Method: artifact.diff
+// Params: { "path": string, "from"?: string, "to"?: string, "offset"?: number, "length"?: number }
+// Result: { "path", "from", "to", "offset", "length", "size", "eof", "encoding", "content" }
+func handleArtifactDiff(projectPath string) Handler {
+	return func(params json.RawMessage) (interface{}, error) {
+		var p ArtifactDiffParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", err.Error())
+		}
+		if err := p.validate(); err != nil {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", err.Error())
+		}
+		if p.From == "" {
+			p.From = "HEAD"
+		}
+		for _, rev := range []string{p.From, p.To} {
+			if rev == "" {
+				continue
+			}
+			if err := checkpoint.ValidateRevision(rev); err != nil {
+				return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", err.Error())
+			}
+		}
+		// Deleted artifacts can still be compared with their checkpoints
+		_, rel, err := resolveArtifactPath(projectPath, p.Path, false)
+		if err != nil {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid path", err.Error())
+		}
+
+		repoPath, err := filepath.EvalSymlinks(projectPath)
+		if err != nil {
+			return nil, NewErrorWithData(ErrCodeInternalError, "Failed to diff artifact", err.Error())
+		}
+		diff, err := checkpoint.Diff(repoPath, p.From, p.To, filepath.ToSlash(filepath.Join("_bmad-output", rel)))
+		if err != nil {
+			return nil, NewErrorWithData(ErrCodeInternalError, "Failed to diff artifact", err.Error())
+		}
+
+		data := []byte(diff)
+		size := int64(len(data))
+		start := min(p.Offset, size)
+		end := min(start+int64(p.Length+chunkSlack), size)
+		return ArtifactDiff{Path: rel, From: p.From, To: p.To, Chunk: newChunk(data[start:end], start, size, p.Length)}, nil
+	}
+}
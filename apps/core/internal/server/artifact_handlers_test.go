@@ -0,0 +1,188 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/project"
+)
+
+func TestHandleArtifactList(t *testing.T) {
+	dir := t.TempDir()
+	planning := filepath.Join(dir, "_bmad-output", "planning-artifacts")
+	if err := os.MkdirAll(planning, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(planning, "prd.md"), []byte("# PRD\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := New(nil, io.Discard, log.New(io.Discard, "", 0), dir)
+	RegisterArtifactHandlers(srv, dir)
+
+	result, err := srv.handlers["artifact.list"](nil)
+	if err != nil {
+		t.Fatalf("artifact.list failed: %v", err)
+	}
+	catalog := result.(*project.Catalog)
+	if len(catalog.Artifacts) != 1 || catalog.Artifacts[0].Type != "prd" || catalog.Artifacts[0].Modified == "" {
+		t.Errorf("catalog = %+v", catalog.Artifacts)
+	}
+}
+
+func newArtifactTestServer(t *testing.T, files map[string]string) (*Server, string) {
+	t.Helper()
+	dir := t.TempDir()
+	for rel, content := range files {
+		path := filepath.Join(dir, "_bmad-output", filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srv := New(nil, io.Discard, log.New(io.Discard, "", 0), dir)
+	RegisterArtifactHandlers(srv, dir)
+	return srv, dir
+}
+
+func TestHandleArtifactRead_Chunks(t *testing.T) {
+	// "é" is two bytes; a 3-byte chunk must not split it
+	srv, _ := newArtifactTestServer(t, map[string]string{"planning-artifacts/prd.md": "abé cd"})
+
+	// A 1-byte chunk at "é" returns the whole character so reading progresses
+	for _, length := range []int{3, 1} {
+		var content strings.Builder
+		offset := int64(0)
+		for i := 0; i < 10; i++ {
+			params, _ := json.Marshal(ArtifactReadParams{Path: "planning-artifacts/prd.md", ChunkParams: ChunkParams{Offset: offset, Length: length}})
+			result, err := srv.handlers["artifact.read"](params)
+			if err != nil {
+				t.Fatalf("artifact.read failed: %v", err)
+			}
+			chunk := result.(ArtifactContent)
+			if chunk.Encoding != EncodingUTF8 || chunk.Size != 7 || chunk.Length == 0 {
+				t.Fatalf("length %d: chunk = %+v", length, chunk)
+			}
+			content.WriteString(chunk.Content)
+			offset += int64(chunk.Length)
+			if chunk.EOF {
+				break
+			}
+		}
+		if content.String() != "abé cd" {
+			t.Errorf("length %d: reassembled content = %q", length, content.String())
+		}
+	}
+}
+
+func TestHandleArtifactRead_Errors(t *testing.T) {
+	srv, dir := newArtifactTestServer(t, map[string]string{"prd.md": "# PRD", "image.png": "\x89PNG\x00\x01"})
+	if err := os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []ArtifactReadParams{
+		{Path: "../secret.txt"},
+		{Path: filepath.Join(dir, "secret.txt")},
+		{Path: "missing.md"},
+		{Path: ""},
+		{Path: "prd.md", ChunkParams: ChunkParams{Length: MaxArtifactChunkSize + 1}},
+	} {
+		params, _ := json.Marshal(p)
+		_, err := srv.handlers["artifact.read"](params)
+		if rpcErr, ok := err.(*Error); !ok || rpcErr.Code != ErrCodeInvalidParams {
+			t.Errorf("artifact.read(%+v) err = %v, want ErrCodeInvalidParams", p, err)
+		}
+	}
+
+	// Binary content is base64 encoded
+	params, _ := json.Marshal(ArtifactReadParams{Path: "image.png"})
+	result, err := srv.handlers["artifact.read"](params)
+	if err != nil {
+		t.Fatalf("artifact.read failed: %v", err)
+	}
+	if chunk := result.(ArtifactContent); chunk.Encoding != EncodingBase64 || !chunk.EOF {
+		t.Errorf("chunk = %+v", chunk)
+	}
+}
+
+func TestHandleArtifactOutline(t *testing.T) {
+	srv, _ := newArtifactTestServer(t, map[string]string{
+		"prd.md":             "# PRD\n## Goals\n",
+		"sprint-status.yaml": "development_status: {}\n",
+	})
+
+	params, _ := json.Marshal(ArtifactPathParams{Path: "prd.md"})
+	result, err := srv.handlers["artifact.outline"](params)
+	if err != nil {
+		t.Fatalf("artifact.outline failed: %v", err)
+	}
+	outline := result.(ArtifactOutline)
+	if len(outline.Headings) != 1 || outline.Headings[0].Children[0].Text != "Goals" {
+		t.Errorf("outline = %+v", outline)
+	}
+
+	params, _ = json.Marshal(ArtifactPathParams{Path: "sprint-status.yaml"})
+	if _, err := srv.handlers["artifact.outline"](params); err == nil {
+		t.Error("expected error for non-markdown artifact")
+	}
+}
+
+func TestHandleArtifactDiff(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	srv, dir := newArtifactTestServer(t, map[string]string{"prd.md": "# PRD\n", "old.md": "# Old\n"})
+	for _, args := range [][]string{{"init", "-q"}, {"add", "."}, {"commit", "-qm", "checkpoint"}} {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "_bmad-output", "prd.md"), []byte("# PRD\n## Goals\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	params, _ := json.Marshal(ArtifactDiffParams{Path: "prd.md"})
+	result, err := srv.handlers["artifact.diff"](params)
+	if err != nil {
+		t.Fatalf("artifact.diff failed: %v", err)
+	}
+	diff := result.(ArtifactDiff)
+	if diff.From != "HEAD" || !diff.EOF || !strings.Contains(diff.Content, "+## Goals") {
+		t.Errorf("diff = %+v", diff)
+	}
+
+	// A deleted artifact is compared with its checkpoint
+	if err := os.Remove(filepath.Join(dir, "_bmad-output", "old.md")); err != nil {
+		t.Fatal(err)
+	}
+	params, _ = json.Marshal(ArtifactDiffParams{Path: "old.md"})
+	result, err = srv.handlers["artifact.diff"](params)
+	if err != nil {
+		t.Fatalf("artifact.diff of deleted artifact failed: %v", err)
+	}
+	if diff := result.(ArtifactDiff); !strings.Contains(diff.Content, "-# Old") {
+		t.Errorf("diff = %+v", diff)
+	}
+
+	for _, p := range []ArtifactDiffParams{
+		{Path: "prd.md", From: "--output=x"},
+		{Path: "../missing.md"},
+		{Path: filepath.Join(dir, "missing.md")},
+	} {
+		params, _ = json.Marshal(p)
+		_, err = srv.handlers["artifact.diff"](params)
+		if rpcErr, ok := err.(*Error); !ok || rpcErr.Code != ErrCodeInvalidParams {
+			t.Errorf("artifact.diff(%+v) err = %v, want ErrCodeInvalidParams", p, err)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Package server provides journey history handlers.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/journey"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/state"
+)
+
+// registerHistoryHandlers registers the history.* handlers backed by the
+// selected project's engine index. It is called from RegisterJourneyHandlers.
+func registerHistoryHandlers(s *Server) {
+	s.RegisterHandler("history.list", s.projectHandler(func(p *ProjectSession) Handler { return handleHistoryList(p.Engine.History()) }))
+	s.RegisterHandler("history.search", s.projectHandler(func(p *ProjectSession) Handler { return handleHistorySearch(p.Engine.History()) }))
+	s.RegisterHandler("history.get", s.projectHandler(func(p *ProjectSession) Handler { return handleHistoryGet(p.Engine) }))
+}
+
+// HistoryListParams are the parameters for history.list.
+type HistoryListParams struct {
+	Offset int `json:"offset,omitempty"`
+	Limit  int `json:"limit,omitempty"` // Default 20, max 100
+}
+
+// handleHistoryList returns a page of finished journeys, newest first.
+// Method: history.list
+// Params: { "offset"?: number, "limit"?: number }
+// Result: HistoryPage
+func handleHistoryList(history *state.History) Handler {
+	return func(params json.RawMessage) (interface{}, error) {
+		var p HistoryListParams
+		if len(params) > 0 {
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", err.Error())
+			}
+		}
+		if p.Offset < 0 || p.Limit < 0 || p.Limit > state.MaxHistoryLimit {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", "offset must not be negative and limit must be between 1 and 100")
+		}
+
+		page, err := history.List(p.Offset, p.Limit)
+		if err != nil {
+			return nil, NewErrorWithData(ErrCodeInternalError, "Failed to list history", err.Error())
+		}
+		return page, nil
+	}
+}
+
+// handleHistorySearch returns a page of finished journeys matching the filters.
+// Method: history.search
+// Params: HistoryQuery { "text"?, "statuses"?, "destination"?, "from"?, "to"?, "offset"?, "limit"? }
+// Result: HistoryPage
+func handleHistorySearch(history *state.History) Handler {
+	return func(params json.RawMessage) (interface{}, error) {
+		var q state.HistoryQuery
+		if len(params) > 0 {
+			if err := json.Unmarshal(params, &q); err != nil {
+				return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", err.Error())
+			}
+		}
+		if q.From != nil && q.To != nil && q.From.After(*q.To) {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", "from must not be after to")
+		}
+		if q.Offset < 0 || q.Limit < 0 || q.Limit > state.MaxHistoryLimit {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", "offset must not be negative and limit must be between 1 and 100")
+		}
+
+		page, err := history.Search(q)
+		if err != nil {
+			return nil, NewErrorWithData(ErrCodeInternalError, "Failed to search history", err.Error())
+		}
+		return page, nil
+	}
+}
+
+// HistoryDetail is the result of history.get.
+type HistoryDetail struct {
+	Record  *state.HistoryRecord `json:"record"`
+	Journey *journey.Journey     `json:"journey,omitempty"` // Full state; nil if the state file was removed
+}
+
+// handleHistoryGet returns the history record and full state of a finished journey.
+// Method: history.get
+// Params: { "journeyId": string }
+// Result: { "record": HistoryRecord, "journey"?: Journey }
+func handleHistoryGet(engine *journey.Engine) Handler {
+	return func(params json.RawMessage) (interface{}, error) {
+		id, err := parseJourneyID(params)
+		if err != nil {
+			return nil, err
+		}
+
+		rec, err := engine.History().Get(id)
+		if err != nil {
+			if errors.Is(err, state.ErrJourneyNotFound) {
+				return nil, NewErrorWithData(ErrCodeJourneyNotFound, "Journey not found", err.Error())
+			}
+			return nil, NewErrorWithData(ErrCodeInternalError, "Failed to get history", err.Error())
+		}
+
+		detail := HistoryDetail{Record: rec}
+		if j, err := engine.Get(id); err == nil {
+			detail.Journey = j
+		}
+		return detail, nil
+	}
+}
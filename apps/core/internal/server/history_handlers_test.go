@@ -0,0 +1,60 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/journey"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/state"
+)
+
+func TestHistoryHandlers(t *testing.T) {
+	srv, dir := newJourneyTestServer(t)
+
+	j := journey.NewJourney(dir, []string{"prd"}, "")
+	j.Status = journey.StatusCompleted
+	now := time.Now()
+	j.StartedAt = &now
+	j.CompletedAt = &now
+	if err := journeyEngine.Store().SaveJourney(j.ID, j); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := srv.handlers["history.list"](nil)
+	if err != nil {
+		t.Fatalf("history.list failed: %v", err)
+	}
+	if page := result.(*state.HistoryPage); page.Total != 1 || page.Records[0].ID != j.ID {
+		t.Errorf("history.list = %+v", page)
+	}
+
+	params, _ := json.Marshal(state.HistoryQuery{Statuses: []string{"failed"}})
+	result, err = srv.handlers["history.search"](params)
+	if err != nil {
+		t.Fatalf("history.search failed: %v", err)
+	}
+	if page := result.(*state.HistoryPage); page.Total != 0 {
+		t.Errorf("history.search = %+v, want no failed journeys", page)
+	}
+
+	params, _ = json.Marshal(JourneyIDParams{JourneyID: j.ID})
+	result, err = srv.handlers["history.get"](params)
+	if err != nil {
+		t.Fatalf("history.get failed: %v", err)
+	}
+	if detail := result.(HistoryDetail); detail.Record.Destination != "prd" || detail.Journey == nil {
+		t.Errorf("history.get = %+v", detail)
+	}
+
+	params, _ = json.Marshal(JourneyIDParams{JourneyID: "missing"})
+	_, err = srv.handlers["history.get"](params)
+	if rpcErr, ok := err.(*Error); !ok || rpcErr.Code != ErrCodeJourneyNotFound {
+		t.Errorf("err = %v, want ErrCodeJourneyNotFound", err)
+	}
+
+	params, _ = json.Marshal(HistoryListParams{Limit: 500})
+	if _, err := srv.handlers["history.list"](params); err == nil {
+		t.Error("expected error for limit above maximum")
+	}
+}
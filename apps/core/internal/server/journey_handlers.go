@@ -0,0 +1,428 @@
+// Package server provides journey orchestration handlers.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/journey"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/network"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/project"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/state"
+)
+
+// Global journey engine of the startup project
+var journeyEngine *journey.Engine
+
+// RegisterJourneyHandlers creates the journey engine for the project and
+// registers journey-related JSON-RPC handlers. The handlers act on the
+// engine of the project selected by projectPath, or the active project.
+// This should be called after RegisterSettingsHandlers and InitOpenCodeExecutor.
+func RegisterJourneyHandlers(s *Server, projectPath string) error {
+	var sm *state.StateManager
+	s.workspace.attach(projectPath, func(p *ProjectSession) { sm = p.Settings })
+	engine, err := newJourneyEngine(s, projectPath, sm)
+	if err != nil {
+		return fmt.Errorf("creating journey engine: %w", err)
+	}
+
+	journeyEngine = engine
+	s.workspace.attach(projectPath, func(p *ProjectSession) { p.Engine = engine })
+
+	s.RegisterHandler("journey.start", s.projectHandler(func(p *ProjectSession) Handler { return handleJourneyStart(p.Engine, p.Path) }))
+	s.RegisterHandler("journey.getState", s.projectHandler(func(p *ProjectSession) Handler { return handleJourneyGetState(p.Engine) }))
+	s.RegisterHandler("journey.abort", s.projectHandler(func(p *ProjectSession) Handler { return handleJourneyAbort(p.Engine) }))
+	s.RegisterHandler("journey.resume", s.projectHandler(func(p *ProjectSession) Handler { return handleJourneyResume(p.Engine) }))
+	s.RegisterHandler("journey.replan", s.projectHandler(func(p *ProjectSession) Handler { return handleJourneyReplan(p.Engine, p.Path) }))
+	s.RegisterHandler("journey.submitFeedback", s.projectHandler(func(p *ProjectSession) Handler { return handleJourneySubmitFeedback(p.Engine) }))
+	s.RegisterHandler("journey.getFailureReport", s.projectHandler(func(p *ProjectSession) Handler { return handleJourneyGetFailureReport(p.Engine) }))
+	s.RegisterHandler("journey.getSummary", s.projectHandler(func(p *ProjectSession) Handler { return handleJourneyGetSummary(p.Engine) }))
+	s.RegisterHandler("journey.exportReport", s.projectHandler(func(p *ProjectSession) Handler { return handleJourneyExportReport(p.Engine) }))
+	s.RegisterHandler("journey.previewContext", s.projectHandler(func(p *ProjectSession) Handler { return handleJourneyPreviewContext(p.Engine, p.Path) }))
+	registerHistoryHandlers(s)
+
+	return nil
+}
+
+// newJourneyEngine creates a journey engine for a project, wired to the
+// server's events, the shared OpenCode executor and the project's settings.
+func newJourneyEngine(s *Server, projectPath string, sm *state.StateManager) (*journey.Engine, error) {
+	if opencodeExecutor == nil {
+		InitOpenCodeExecutor(s)
+	}
+
+	engine, err := journey.NewEngine(projectPath, opencodeExecutor)
+	if err != nil {
+		return nil, err
+	}
+	engine.Emit = func(event string, data interface{}) {
+		if err := s.EmitEvent(event, data); err != nil {
+			s.logger.Printf("Failed to emit %s event: %v", event, err)
+		}
+	}
+	if sm != nil {
+		engine.Settings = sm.Get
+	}
+	engine.NetworkStatus = func() network.Status {
+		if networkMonitor == nil {
+			return network.StatusChecking
+		}
+		return networkMonitor.GetStatus().Status
+	}
+	engine.ProjectContext = func() string {
+		if rm := project.GetRecentManager(); rm != nil {
+			return rm.GetContext(projectPath)
+		}
+		return ""
+	}
+	if status := engine.NetworkStatus(); status == network.StatusOnline {
+		// Journeys still held for the network from an earlier run resume
+		// without waiting for the next status change
+		engine.NetworkChanged(status)
+	}
+	return engine, nil
+}
+
+// journeyError maps journey engine errors to JSON-RPC errors.
+func journeyError(message string, err error) *Error {
+	switch {
+	case errors.Is(err, journey.ErrNotFound):
+		return NewErrorWithData(ErrCodeJourneyNotFound, "Journey not found", err.Error())
+	case errors.Is(err, journey.ErrInvalidState):
+		return NewErrorWithData(ErrCodeInvalidParams, message, err.Error())
+	default:
+		return NewErrorWithData(ErrCodeInternalError, message, err.Error())
+	}
+}
+
+// validateWorkflowName checks a workflow name is safe and declared in the manifest.
+// Source: architecture.md - "Workflow name must exist in manifest, no path separators"
+func validateWorkflowName(projectPath, name string) error {
+	if name == "" {
+		return fmt.Errorf("workflow is required")
+	}
+	if strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") {
+		return fmt.Errorf("workflow name must not contain path separators")
+	}
+	if _, err := project.FindWorkflow(projectPath, name); err != nil {
+		return err
+	}
+	return nil
+}
+
+// JourneyStartParams are the parameters for journey.start.
+type JourneyStartParams struct {
+	Workflow string   `json:"workflow"`        // Destination workflow
+	Route    []string `json:"route,omitempty"` // Optional full route; defaults to [workflow]
+	Profile  string   `json:"profile,omitempty"`
+}
+
+// handleJourneyStart starts a new journey.
+// Method: journey.start
+// Params: { "workflow": string, "route"?: string[], "profile"?: string }
+// Result: Journey
+func handleJourneyStart(engine *journey.Engine, projectPath string) Handler {
+	return func(params json.RawMessage) (interface{}, error) {
+		var p JourneyStartParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", err.Error())
+		}
+
+		route := p.Route
+		if len(route) == 0 {
+			route = []string{p.Workflow}
+		}
+		for _, workflow := range route {
+			if err := validateWorkflowName(projectPath, workflow); err != nil {
+				return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid workflow", err.Error())
+			}
+		}
+
+		j, err := engine.Start(route, p.Profile)
+		if err != nil {
+			return nil, journeyError("Failed to start journey", err)
+		}
+		return j, nil
+	}
+}
+
+// JourneyIDParams identifies a journey.
+type JourneyIDParams struct {
+	JourneyID string `json:"journeyId"`
+}
+
+// parseJourneyID extracts and validates the journeyId parameter.
+func parseJourneyID(params json.RawMessage) (string, error) {
+	var p JourneyIDParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return "", NewErrorWithData(ErrCodeInvalidParams, "Invalid params", err.Error())
+	}
+	if p.JourneyID == "" {
+		return "", NewErrorWithData(ErrCodeInvalidParams, "Invalid params", "journeyId is required")
+	}
+	return p.JourneyID, nil
+}
+
+// handleJourneyGetState returns the state of a journey.
+// Method: journey.getState
+// Params: { "journeyId": string }
+// Result: Journey
+func handleJourneyGetState(engine *journey.Engine) Handler {
+	return func(params json.RawMessage) (interface{}, error) {
+		id, err := parseJourneyID(params)
+		if err != nil {
+			return nil, err
+		}
+		j, err := engine.Get(id)
+		if err != nil {
+			return nil, journeyError("Failed to get journey", err)
+		}
+		return j, nil
+	}
+}
+
+// handleJourneyAbort aborts a running journey.
+// Method: journey.abort
+// Params: { "journeyId": string }
+// Result: Journey
+func handleJourneyAbort(engine *journey.Engine) Handler {
+	return func(params json.RawMessage) (interface{}, error) {
+		id, err := parseJourneyID(params)
+		if err != nil {
+			return nil, err
+		}
+		j, err := engine.Abort(id)
+		if err != nil {
+			return nil, journeyError("Failed to abort journey", err)
+		}
+		return j, nil
+	}
+}
+
+// handleJourneyGetSummary returns the completion summary of a journey.
+// Method: journey.getSummary
+// Params: { "journeyId": string }
+// Result: Summary
+func handleJourneyGetSummary(engine *journey.Engine) Handler {
+	return func(params json.RawMessage) (interface{}, error) {
+		id, err := parseJourneyID(params)
+		if err != nil {
+			return nil, err
+		}
+		summary, err := engine.Summary(id)
+		if err != nil {
+			return nil, journeyError("Failed to get journey summary", err)
+		}
+		return summary, nil
+	}
+}
+
+// ExportReportParams are the parameters for journey.exportReport.
+type ExportReportParams struct {
+	JourneyID string `json:"journeyId"`
+	Format    string `json:"format"` // md, html or json
+}
+
+// ExportReportResult is the result of journey.exportReport.
+type ExportReportResult struct {
+	Path   string               `json:"path"`
+	Format journey.ReportFormat `json:"format"`
+}
+
+// handleJourneyExportReport writes a journey report to _bmad-output/.autobmad/reports/.
+// Method: journey.exportReport
+// Params: { "journeyId": string, "format": "md" | "html" | "json" }
+// Result: { "path": string, "format": string }
+func handleJourneyExportReport(engine *journey.Engine) Handler {
+	return func(params json.RawMessage) (interface{}, error) {
+		var p ExportReportParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", err.Error())
+		}
+		if p.JourneyID == "" {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", "journeyId is required")
+		}
+		format, err := journey.ParseReportFormat(p.Format)
+		if err != nil {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", err.Error())
+		}
+
+		path, err := engine.ExportReport(p.JourneyID, format)
+		if err != nil {
+			return nil, journeyError("Failed to export report", err)
+		}
+		return ExportReportResult{Path: path, Format: format}, nil
+	}
+}
+
+// JourneyReplanParams are the parameters for journey.replan.
+type JourneyReplanParams struct {
+	JourneyID string              `json:"journeyId"`
+	Feedback  string              `json:"feedback,omitempty"` // Why the direction changes
+	Route     []string            `json:"route,omitempty"`    // New remaining route
+	Edits     []journey.RouteEdit `json:"edits,omitempty"`    // Or edits to the remaining route
+}
+
+// JourneyReplanResult is the result of journey.replan.
+type JourneyReplanResult struct {
+	Journey  *journey.Journey       `json:"journey"`
+	Revision *journey.RouteRevision `json:"revision"`
+}
+
+// handleJourneyReplan changes the remaining route of a journey.
+// Method: journey.replan
+// Params: { "journeyId": string, "feedback"?: string, "route"?: string[], "edits"?: RouteEdit[] }
+// Result: { "journey": Journey, "revision": RouteRevision }
+func handleJourneyReplan(engine *journey.Engine, projectPath string) Handler {
+	return func(params json.RawMessage) (interface{}, error) {
+		var p JourneyReplanParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", err.Error())
+		}
+		if p.JourneyID == "" {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", "journeyId is required")
+		}
+		if p.Route == nil && len(p.Edits) == 0 {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", "route or edits is required")
+		}
+
+		workflows := p.Route
+		for _, edit := range p.Edits {
+			if edit.Op != journey.RouteRemove {
+				workflows = append(workflows, edit.Workflow)
+			}
+		}
+		for _, workflow := range workflows {
+			if err := validateWorkflowName(projectPath, workflow); err != nil {
+				return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid workflow", err.Error())
+			}
+		}
+
+		j, rev, err := engine.Replan(p.JourneyID, journey.ReplanRequest{
+			Feedback: p.Feedback,
+			Route:    p.Route,
+			Edits:    p.Edits,
+		})
+		if err != nil {
+			return nil, journeyError("Failed to replan journey", err)
+		}
+		return JourneyReplanResult{Journey: j, Revision: rev}, nil
+	}
+}
+
+// SubmitFeedbackParams are the parameters for journey.submitFeedback.
+type SubmitFeedbackParams struct {
+	JourneyID string `json:"journeyId"`
+	Feedback  string `json:"feedback"` // Answer to the open yellow flag, or general feedback
+}
+
+// handleJourneySubmitFeedback answers a yellow flag or adds feedback for the current step.
+// Method: journey.submitFeedback
+// Params: { "journeyId": string, "feedback": string }
+// Result: Journey
+func handleJourneySubmitFeedback(engine *journey.Engine) Handler {
+	return func(params json.RawMessage) (interface{}, error) {
+		var p SubmitFeedbackParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", err.Error())
+		}
+		if p.JourneyID == "" {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", "journeyId is required")
+		}
+		if strings.TrimSpace(p.Feedback) == "" {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", "feedback is required")
+		}
+		j, err := engine.SubmitFeedback(p.JourneyID, p.Feedback)
+		if err != nil {
+			return nil, journeyError("Failed to submit feedback", err)
+		}
+		return j, nil
+	}
+}
+
+// handleJourneyGetFailureReport explains the latest failure of a journey.
+// Method: journey.getFailureReport
+// Params: { "journeyId": string }
+// Result: FailureReport
+func handleJourneyGetFailureReport(engine *journey.Engine) Handler {
+	return func(params json.RawMessage) (interface{}, error) {
+		id, err := parseJourneyID(params)
+		if err != nil {
+			return nil, err
+		}
+		report, err := engine.FailureReport(id)
+		if err != nil {
+			return nil, journeyError("Failed to build failure report", err)
+		}
+		return report, nil
+	}
+}
+
+// JourneyResumeParams are the parameters for journey.resume.
+type JourneyResumeParams struct {
+	JourneyID string `json:"journeyId"`
+	Feedback  string `json:"feedback,omitempty"` // Added to the next attempt's context
+}
+
+// handleJourneyResume resumes a paused journey, e.g. after escalation.
+// Method: journey.resume
+// Params: { "journeyId": string, "feedback"?: string }
+// Result: Journey
+func handleJourneyResume(engine *journey.Engine) Handler {
+	return func(params json.RawMessage) (interface{}, error) {
+		var p JourneyResumeParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", err.Error())
+		}
+		if p.JourneyID == "" {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", "journeyId is required")
+		}
+		j, err := engine.Resume(p.JourneyID, p.Feedback)
+		if err != nil {
+			return nil, journeyError("Failed to resume journey", err)
+		}
+		return j, nil
+	}
+}
+
+// PreviewContextParams are the parameters for journey.previewContext.
+type PreviewContextParams struct {
+	Workflow  string `json:"workflow,omitempty"`
+	JourneyID string `json:"journeyId,omitempty"`
+	StepIndex int    `json:"stepIndex,omitempty"`
+}
+
+// PreviewContextResult is the result of journey.previewContext.
+type PreviewContextResult struct {
+	Context *journey.StepContext `json:"context"`
+	Content string               `json:"content"` // Rendered context.md
+}
+
+// handleJourneyPreviewContext renders the context that would be sent to OpenCode.
+// Method: journey.previewContext
+// Params: { "workflow"?: string, "journeyId"?: string, "stepIndex"?: number }
+// Result: { "context": StepContext, "content": string }
+func handleJourneyPreviewContext(engine *journey.Engine, projectPath string) Handler {
+	return func(params json.RawMessage) (interface{}, error) {
+		var p PreviewContextParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", err.Error())
+		}
+		if p.Workflow == "" && p.JourneyID == "" {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", "workflow or journeyId is required")
+		}
+		if p.Workflow != "" {
+			if err := validateWorkflowName(projectPath, p.Workflow); err != nil {
+				return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid workflow", err.Error())
+			}
+		}
+
+		sc, content, err := engine.PreviewContext(p.Workflow, p.JourneyID, p.StepIndex)
+		if err != nil {
+			return nil, journeyError("Failed to build context", err)
+		}
+		return PreviewContextResult{Context: sc, Content: content}, nil
+	}
+}
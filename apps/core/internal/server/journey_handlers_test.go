@@ -0,0 +1,230 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/journey"
+)
+
+// newJourneyTestProject creates a BMAD project with a one-workflow manifest.
+func newJourneyTestProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, "_bmad", "_config", "workflow-manifest.csv")
+	if err := os.MkdirAll(filepath.Dir(manifest), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "name,description,module,path\n\"prd\",\"Create PRDs\",\"bmm\",\"_bmad/bmm/workflows/prd/workflow.md\"\n"
+	if err := os.WriteFile(manifest, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// newJourneyTestServer registers settings and journey handlers for a test project.
+func newJourneyTestServer(t *testing.T) (*Server, string) {
+	t.Helper()
+	dir := newJourneyTestProject(t)
+	srv := New(nil, io.Discard, log.New(io.Discard, "", 0), dir)
+	if err := RegisterSettingsHandlers(srv, dir); err != nil {
+		t.Fatalf("RegisterSettingsHandlers failed: %v", err)
+	}
+	InitOpenCodeExecutor(srv)
+	if err := RegisterJourneyHandlers(srv, dir); err != nil {
+		t.Fatalf("RegisterJourneyHandlers failed: %v", err)
+	}
+	return srv, dir
+}
+
+func TestRegisterJourneyHandlers(t *testing.T) {
+	srv, _ := newJourneyTestServer(t)
+
+	for _, method := range []string{"journey.start", "journey.getState", "journey.abort", "journey.resume", "journey.replan", "journey.submitFeedback", "journey.getFailureReport", "journey.getSummary", "journey.exportReport", "journey.previewContext"} {
+		if _, ok := srv.handlers[method]; !ok {
+			t.Errorf("%s handler not registered", method)
+		}
+	}
+}
+
+func TestHandleJourneyPreviewContext(t *testing.T) {
+	srv, _ := newJourneyTestServer(t)
+
+	params, _ := json.Marshal(PreviewContextParams{Workflow: "prd"})
+	result, err := srv.handlers["journey.previewContext"](params)
+	if err != nil {
+		t.Fatalf("journey.previewContext failed: %v", err)
+	}
+
+	preview, ok := result.(PreviewContextResult)
+	if !ok {
+		t.Fatalf("result type = %T", result)
+	}
+	if !strings.Contains(preview.Content, "_bmad/bmm/workflows/prd/workflow.md") {
+		t.Errorf("content missing workflow path:\n%s", preview.Content)
+	}
+}
+
+func TestHandleJourneyStart_InvalidWorkflow(t *testing.T) {
+	srv, _ := newJourneyTestServer(t)
+
+	tests := []string{
+		`{"workflow": ""}`,
+		`{"workflow": "../prd"}`,
+		`{"workflow": "unknown"}`,
+		`{"workflow": "prd", "route": ["prd", "a/b"]}`,
+	}
+	for _, params := range tests {
+		_, err := srv.handlers["journey.start"](json.RawMessage(params))
+		rpcErr, ok := err.(*Error)
+		if !ok || rpcErr.Code != ErrCodeInvalidParams {
+			t.Errorf("journey.start(%s) err = %v, want invalid params", params, err)
+		}
+	}
+}
+
+func TestHandleJourneyGetState_NotFound(t *testing.T) {
+	srv, _ := newJourneyTestServer(t)
+
+	_, err := srv.handlers["journey.getState"](json.RawMessage(`{"journeyId": "j-missing"}`))
+	rpcErr, ok := err.(*Error)
+	if !ok || rpcErr.Code != ErrCodeJourneyNotFound {
+		t.Errorf("err = %v, want ErrCodeJourneyNotFound", err)
+	}
+
+	_, err = srv.handlers["journey.getState"](json.RawMessage(`{}`))
+	rpcErr, ok = err.(*Error)
+	if !ok || rpcErr.Code != ErrCodeInvalidParams {
+		t.Errorf("err = %v, want ErrCodeInvalidParams", err)
+	}
+}
+
+func TestHandleJourneyResume_NotFound(t *testing.T) {
+	srv, _ := newJourneyTestServer(t)
+
+	_, err := srv.handlers["journey.resume"](json.RawMessage(`{"journeyId": "j-missing", "feedback": "try again"}`))
+	rpcErr, ok := err.(*Error)
+	if !ok || rpcErr.Code != ErrCodeJourneyNotFound {
+		t.Errorf("err = %v, want ErrCodeJourneyNotFound", err)
+	}
+}
+
+func TestHandleJourneyGetFailureReport(t *testing.T) {
+	srv, dir := newJourneyTestServer(t)
+
+	j := journey.NewJourney(dir, []string{"prd"}, "")
+	j.Status = journey.StatusPaused
+	j.Steps[0].Status = journey.StepFailed
+	j.Steps[0].Attempts = []*journey.Attempt{{
+		Number:  1,
+		Outcome: journey.AttemptFailed,
+		Error:   "step timed out",
+		Failure: &journey.Classification{Category: journey.FailureTimeout},
+	}}
+	if err := journeyEngine.Store().SaveJourney(j.ID, j); err != nil {
+		t.Fatal(err)
+	}
+
+	params, _ := json.Marshal(JourneyIDParams{JourneyID: j.ID})
+	result, err := srv.handlers["journey.getFailureReport"](params)
+	if err != nil {
+		t.Fatalf("journey.getFailureReport failed: %v", err)
+	}
+	report := result.(*journey.FailureReport)
+	if report.Category != journey.FailureTimeout || report.Actions[0].ID != "increase-timeout" {
+		t.Errorf("report = %+v", report)
+	}
+
+	// A journey without failures has no report
+	ok := journey.NewJourney(dir, []string{"prd"}, "")
+	if err := journeyEngine.Store().SaveJourney(ok.ID, ok); err != nil {
+		t.Fatal(err)
+	}
+	params, _ = json.Marshal(JourneyIDParams{JourneyID: ok.ID})
+	_, err = srv.handlers["journey.getFailureReport"](params)
+	if rpcErr, isRPC := err.(*Error); !isRPC || rpcErr.Code != ErrCodeInvalidParams {
+		t.Errorf("err = %v, want ErrCodeInvalidParams", err)
+	}
+}
+
+func TestHandleJourneySubmitFeedback_InvalidParams(t *testing.T) {
+	srv, _ := newJourneyTestServer(t)
+
+	for _, params := range []string{`{"feedback": "yes"}`, `{"journeyId": "j-1", "feedback": "  "}`} {
+		_, err := srv.handlers["journey.submitFeedback"](json.RawMessage(params))
+		if rpcErr, ok := err.(*Error); !ok || rpcErr.Code != ErrCodeInvalidParams {
+			t.Errorf("journey.submitFeedback(%s) err = %v, want invalid params", params, err)
+		}
+	}
+}
+
+func TestHandleJourneyReplan(t *testing.T) {
+	srv, dir := newJourneyTestServer(t)
+
+	j := journey.NewJourney(dir, []string{"prd", "prd"}, "")
+	j.Status = journey.StatusPaused
+	if err := journeyEngine.Store().SaveJourney(j.ID, j); err != nil {
+		t.Fatal(err)
+	}
+
+	params, _ := json.Marshal(JourneyReplanParams{
+		JourneyID: j.ID,
+		Feedback:  "One PRD pass is enough",
+		Edits:     []journey.RouteEdit{{Op: journey.RouteRemove, StepIndex: 1}},
+	})
+	result, err := srv.handlers["journey.replan"](params)
+	if err != nil {
+		t.Fatalf("journey.replan failed: %v", err)
+	}
+	res := result.(JourneyReplanResult)
+	if len(res.Journey.Steps) != 1 || res.Revision.Version != 2 || len(res.Revision.Removed) != 1 {
+		t.Errorf("result = %+v, revision = %+v", res.Journey, res.Revision)
+	}
+
+	// Workflows must exist in the manifest
+	params, _ = json.Marshal(JourneyReplanParams{JourneyID: j.ID, Route: []string{"unknown-workflow"}})
+	_, err = srv.handlers["journey.replan"](params)
+	if rpcErr, ok := err.(*Error); !ok || rpcErr.Code != ErrCodeInvalidParams {
+		t.Errorf("err = %v, want ErrCodeInvalidParams", err)
+	}
+}
+
+func TestHandleJourneyExportReport(t *testing.T) {
+	srv, dir := newJourneyTestServer(t)
+
+	j := journey.NewJourney(dir, []string{"prd"}, "")
+	if err := journeyEngine.Store().SaveJourney(j.ID, j); err != nil {
+		t.Fatal(err)
+	}
+
+	params, _ := json.Marshal(ExportReportParams{JourneyID: j.ID, Format: "markdown"})
+	result, err := srv.handlers["journey.exportReport"](params)
+	if err != nil {
+		t.Fatalf("journey.exportReport failed: %v", err)
+	}
+	res := result.(ExportReportResult)
+	want := filepath.Join(dir, "_bmad-output", ".autobmad", "reports", j.ID+".md")
+	if res.Path != want || res.Format != journey.ReportMarkdown {
+		t.Errorf("result = %+v, want path %s", res, want)
+	}
+	if _, err := os.Stat(want); err != nil {
+		t.Errorf("report not written: %v", err)
+	}
+
+	params, _ = json.Marshal(ExportReportParams{JourneyID: j.ID, Format: "pdf"})
+	_, err = srv.handlers["journey.exportReport"](params)
+	if rpcErr, ok := err.(*Error); !ok || rpcErr.Code != ErrCodeInvalidParams {
+		t.Errorf("err = %v, want ErrCodeInvalidParams", err)
+	}
+
+	params, _ = json.Marshal(JourneyIDParams{JourneyID: "missing"})
+	_, err = srv.handlers["journey.getSummary"](params)
+	if rpcErr, ok := err.(*Error); !ok || rpcErr.Code != ErrCodeJourneyNotFound {
+		t.Errorf("err = %v, want ErrCodeJourneyNotFound", err)
+	}
+}
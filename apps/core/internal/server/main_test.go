@@ -0,0 +1,22 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/paths"
+)
+
+// TestMain keeps the tests away from the real user config directory.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "autobmad-home-*")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Setenv(paths.HomeEnv, dir)
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
@@ -4,48 +4,127 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"net/url"
+	"sort"
 	"time"
 
 	"github.com/fairyhunter13/auto-bmad/apps/core/internal/network"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/opencode"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/state"
 )
 
 // Global network monitor instance
 var networkMonitor *network.DebouncedMonitor
 var networkServer *Server
 
-// InitNetworkMonitor initializes the global network monitor.
-// This should be called once during server startup.
+// InitNetworkMonitor initializes the global network monitor with the check
+// interval and debounce window from the process-wide settings. Status changes are emitted to
+// the client and passed to the journey engines of all opened projects.
+// This should be called once during server startup, after RegisterSettingsHandlers.
 func InitNetworkMonitor(ctx context.Context, s *Server) {
 	networkServer = s
 
+	settings := state.DefaultSettings()
+	if settingsManager != nil {
+		settings = settingsManager.Get()
+	}
 	networkMonitor = network.NewDebouncedMonitor(
-		30*time.Second, // Check interval
-		5*time.Second,  // Debounce window
+		time.Duration(settings.NetworkCheckInterval)*time.Millisecond,
+		time.Duration(settings.NetworkDebounce)*time.Millisecond,
 		func(old, new network.Status) {
 			// Emit status change event to frontend
 			if networkServer != nil {
 				event := map[string]interface{}{
 					"previous": old,
 					"current":  new,
+					"probes":   networkMonitor.GetStatus().Probes,
 				}
 				if err := networkServer.EmitEvent("network.statusChanged", event); err != nil {
 					// Log error but don't fail
 					networkServer.logger.Printf("Failed to emit network.statusChanged event: %v", err)
 				}
+				// Pause or resume journeys that need the network (FR52)
+				for _, engine := range networkServer.workspace.Engines() {
+					engine.NetworkChanged(new)
+				}
 			}
 		},
 	)
 
+	// Probes follow the active project's settings and OpenCode profile
+	networkMonitor.SetProbes(func() []network.Probe { return networkProbes(s) })
+
 	// Start monitoring in background
 	go networkMonitor.Start(ctx)
 }
 
+// applyNetworkTiming applies the networkCheckInterval and networkDebounce
+// settings to the running network monitor.
+func applyNetworkTiming() {
+	if networkMonitor == nil || settingsManager == nil {
+		return
+	}
+	settings := settingsManager.Get()
+	networkMonitor.SetInterval(time.Duration(settings.NetworkCheckInterval) * time.Millisecond)
+	networkMonitor.SetDebounce(time.Duration(settings.NetworkDebounce) * time.Millisecond)
+}
+
+// networkProbes builds the probes of the next network check: the
+// networkProbes setting of the active project, or the built-in probes, plus
+// the AI provider endpoints of the project's OpenCode profile.
+func networkProbes(s *Server) []network.Probe {
+	var settings *state.Settings
+	projectPath := s.ProjectPath()
+	if sess, err := s.workspace.Resolve(""); err == nil && sess.Settings != nil {
+		settings, projectPath = sess.Settings.Get(), sess.Path
+	}
+
+	var probes []network.Probe
+	if settings != nil {
+		names := make([]string, 0, len(settings.NetworkProbes))
+		for name := range settings.NetworkProbes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			cfg := settings.NetworkProbes[name]
+			probe, err := network.NewProbe(name, network.ProbeConfig{
+				Kind:         network.ProbeKind(cfg.Kind),
+				Target:       cfg.Target,
+				ExpectStatus: cfg.ExpectStatus,
+			})
+			if err != nil {
+				s.logger.Printf("Skipping network probe: %v", err)
+				continue
+			}
+			probes = append(probes, probe)
+		}
+	}
+	if len(probes) == 0 {
+		probes = network.DefaultProbes()
+	}
+
+	profile := ""
+	if settingsManager != nil {
+		profile = settingsManager.Get().ProjectProfiles[projectPath]
+	}
+	for _, endpoint := range opencode.ProviderEndpoints(profile) {
+		name := endpoint
+		if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
+			name = u.Host
+		}
+		probes = append(probes, &network.HTTPProbe{ProbeName: "provider:" + name, URL: endpoint, AnyStatus: true})
+	}
+	return probes
+}
+
 // RegisterNetworkHandlers registers network-related JSON-RPC handlers.
 func RegisterNetworkHandlers(s *Server) {
 	s.RegisterHandler("network.getStatus", handleNetworkGetStatus)
 }
 
-// handleNetworkGetStatus returns the current network connectivity status.
+// handleNetworkGetStatus returns the current network connectivity status
+// with the result of each probe.
 func handleNetworkGetStatus(params json.RawMessage) (interface{}, error) {
 	if networkMonitor == nil {
 		// Return checking status if monitor not initialized
@@ -1,11 +1,16 @@
 package server
 
 import (
+	"context"
+	"encoding/json"
 	"io"
 	"log"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/fairyhunter13/auto-bmad/apps/core/internal/network"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/paths"
 )
 
 // TestHandleNetworkGetStatus verifies network.getStatus handler
@@ -26,6 +31,7 @@ func TestHandleNetworkGetStatus(t *testing.T) {
 	validStatuses := map[network.Status]bool{
 		network.StatusOnline:   true,
 		network.StatusOffline:  true,
+		network.StatusDegraded: true,
 		network.StatusChecking: true,
 	}
 	if !validStatuses[status.Status] {
@@ -50,3 +56,47 @@ func TestHandleNetworkGetStatus_WithServer(t *testing.T) {
 		t.Error("network.getStatus handler not registered")
 	}
 }
+
+// TestNetworkProbes verifies probes come from the active project's settings
+func TestNetworkProbes(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv(paths.HomeEnv, home)
+	t.Setenv("HOME", home) // No OpenCode configuration
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("OPENCODE_CONFIG", "")
+	dir := t.TempDir()
+
+	srv := New(nil, nil, log.New(io.Discard, "", 0), dir)
+	if err := RegisterSettingsHandlers(srv, dir); err != nil {
+		t.Fatalf("RegisterSettingsHandlers failed: %v", err)
+	}
+
+	// Nothing configured: built-in probes
+	if got, want := len(networkProbes(srv)), len(network.DefaultProbes()); got != want {
+		t.Errorf("len(networkProbes) = %d, want %d built-in probes", got, want)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer ln.Close()
+	params, _ := json.Marshal(map[string]interface{}{
+		"networkProbes": map[string]interface{}{
+			"local":  map[string]interface{}{"kind": "tcp", "target": ln.Addr().String()},
+			"broken": map[string]interface{}{"kind": "tcp", "target": "no-port"},
+		},
+	})
+	if _, err := srv.handlers["settings.set"](params); err != nil {
+		t.Fatalf("settings.set failed: %v", err)
+	}
+
+	probes := networkProbes(srv)
+	if len(probes) != 1 || probes[0].Name() != "local" {
+		t.Fatalf("networkProbes = %v, want the valid local probe only", probes)
+	}
+	results := network.RunProbes(context.Background(), probes, time.Second)
+	if network.Evaluate(results) != network.StatusOnline {
+		t.Errorf("results = %+v, want online", results)
+	}
+}
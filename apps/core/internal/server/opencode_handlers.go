@@ -2,14 +2,81 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
+	"time"
 
 	"github.com/fairyhunter13/auto-bmad/apps/core/internal/opencode"
 )
 
+// Global OpenCode executor instance used to run journey steps
+var opencodeExecutor *opencode.Executor
+
+// InitOpenCodeExecutor creates the global executor. Heartbeat and stall
+// events are emitted to the client. Heartbeat, stall and input settings are
+// taken per request from the settings of the project running the step.
+func InitOpenCodeExecutor(s *Server) *opencode.Executor {
+	executor := opencode.NewExecutor()
+	executor.Emit = func(event string, data interface{}) {
+		if err := s.EmitEvent(event, data); err != nil {
+			s.logger.Printf("Failed to emit %s event: %v", event, err)
+		}
+	}
+
+	opencodeExecutor = executor
+	return executor
+}
+
 // RegisterOpenCodeHandlers registers all OpenCode-related JSON-RPC handlers.
 func RegisterOpenCodeHandlers(s *Server) {
 	s.RegisterHandler("opencode.getProfiles", handleGetProfiles)
 	s.RegisterHandler("opencode.detect", handleDetect)
+	s.RegisterHandler("opencode.sendInput", handleSendInput)
+}
+
+// SendInputParams are the parameters for opencode.sendInput.
+type SendInputParams struct {
+	JourneyID string `json:"journeyId"`
+	StepIndex int    `json:"stepIndex"`
+	Input     string `json:"input"`
+	Newline   bool   `json:"newline,omitempty"` // Append "\n", like pressing Enter
+}
+
+// SendInputResult is the result of opencode.sendInput.
+type SendInputResult struct {
+	Bytes  int       `json:"bytes"`
+	SentAt time.Time `json:"sentAt"`
+}
+
+// handleSendInput writes input to the running OpenCode process of a journey step.
+// Method: opencode.sendInput
+// Params: { "journeyId": string, "stepIndex": number, "input": string, "newline"?: boolean }
+// Result: { "bytes": number, "sentAt": string }
+func handleSendInput(params json.RawMessage) (interface{}, error) {
+	var p SendInputParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", err.Error())
+	}
+	if p.JourneyID == "" {
+		return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", "journeyId is required")
+	}
+	if p.Input == "" && !p.Newline {
+		return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", "input is required")
+	}
+	if opencodeExecutor == nil {
+		return nil, NewError(ErrCodeInternalError, "OpenCode executor not initialized")
+	}
+
+	data := []byte(p.Input)
+	if p.Newline {
+		data = append(data, '\n')
+	}
+	if err := opencodeExecutor.SendInput(p.JourneyID, p.StepIndex, data); err != nil {
+		if errors.Is(err, opencode.ErrProcessNotFound) || errors.Is(err, opencode.ErrInputClosed) {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Process does not accept input", err.Error())
+		}
+		return nil, NewErrorWithData(ErrCodeInternalError, "Failed to send input", err.Error())
+	}
+	return SendInputResult{Bytes: len(data), SentAt: time.Now()}, nil
 }
 
 // handleGetProfiles returns the list of available OpenCode profiles.
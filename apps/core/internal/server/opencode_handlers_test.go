@@ -2,7 +2,11 @@ package server
 
 import (
 	"encoding/json"
+	"io"
+	"log"
 	"testing"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/opencode"
 )
 
 func TestHandleGetProfiles(t *testing.T) {
@@ -35,3 +39,35 @@ func TestRegisterOpenCodeHandlers(t *testing.T) {
 		t.Error("Expected opencode.getProfiles handler to be registered")
 	}
 }
+
+func TestInitOpenCodeExecutor(t *testing.T) {
+	srv := New(nil, nil, log.New(io.Discard, "", 0), t.TempDir())
+
+	executor := InitOpenCodeExecutor(srv)
+
+	if opencodeExecutor != executor {
+		t.Error("global executor not set")
+	}
+	if executor.StallAction != opencode.StallActionWarn || executor.Emit == nil {
+		t.Errorf("executor = %+v, want the defaults emitting to the client", executor)
+	}
+}
+
+func TestHandleSendInput_Errors(t *testing.T) {
+	tmpDir := t.TempDir()
+	srv := New(nil, nil, log.New(io.Discard, "", 0), tmpDir)
+	InitOpenCodeExecutor(srv)
+	RegisterOpenCodeHandlers(srv)
+
+	tests := []string{
+		`{"stepIndex": 0, "input": "y"}`,
+		`{"journeyId": "j-1", "stepIndex": 0}`,
+		`{"journeyId": "j-1", "stepIndex": 0, "input": "y", "newline": true}`, // No running process
+	}
+	for _, params := range tests {
+		_, err := srv.handlers["opencode.sendInput"](json.RawMessage(params))
+		if rpcErr, ok := err.(*Error); !ok || rpcErr.Code != ErrCodeInvalidParams {
+			t.Errorf("opencode.sendInput(%s) err = %v, want invalid params", params, err)
+		}
+	}
+}
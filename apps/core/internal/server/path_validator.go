@@ -21,6 +21,9 @@ var (
 
 	// ErrForbiddenPath indicates access to this path is forbidden
 	ErrForbiddenPath = errors.New("access to this path is forbidden")
+
+	// ErrPathOutsideRoot indicates the path escapes the validator's root directory
+	ErrPathOutsideRoot = errors.New("path is outside the allowed directory")
 )
 
 // PathValidator validates and sanitizes file system paths to prevent path traversal attacks
@@ -30,6 +33,9 @@ type PathValidator struct {
 
 	// RequireDirectory requires the path to be a directory
 	RequireDirectory bool
+
+	// Root, if set, confines paths to this directory after resolving symlinks
+	Root string
 }
 
 // NewPathValidator creates a new path validator with default settings
@@ -86,9 +92,29 @@ func (v *PathValidator) Validate(inputPath string) (string, error) {
 		return "", err
 	}
 
+	// Check confinement to the root directory
+	if v.Root != "" {
+		if err := v.checkRoot(realPath); err != nil {
+			return "", err
+		}
+	}
+
 	return realPath, nil
 }
 
+// checkRoot ensures path is the root directory or inside it
+func (v *PathValidator) checkRoot(path string) error {
+	root, err := filepath.EvalSymlinks(filepath.Clean(v.Root))
+	if err != nil {
+		return fmt.Errorf("failed to resolve root: %w", err)
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.IsAbs(rel) {
+		return fmt.Errorf("%w: %s", ErrPathOutsideRoot, path)
+	}
+	return nil
+}
+
 // checkForbiddenPaths blocks access to sensitive system directories
 func (v *PathValidator) checkForbiddenPaths(path string) error {
 	// Normalize path separators for comparison
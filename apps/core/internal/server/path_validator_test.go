@@ -294,3 +294,32 @@ func TestValidateProjectPath(t *testing.T) {
 		t.Errorf("Expected error for empty path")
 	}
 }
+
+func TestPathValidator_Root(t *testing.T) {
+	root := t.TempDir()
+	inside := filepath.Join(root, "docs", "prd.md")
+	if err := os.MkdirAll(filepath.Dir(inside), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(inside, []byte("# PRD"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(t.TempDir(), "secret.txt")
+	if err := os.WriteFile(outside, []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(root, "escape.txt")
+	if err := os.Symlink(outside, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	v := &PathValidator{RequireDirectory: false, Root: root}
+	if _, err := v.Validate(inside); err != nil {
+		t.Errorf("path inside root rejected: %v", err)
+	}
+	for _, path := range []string{outside, filepath.Join(root, "..", filepath.Base(filepath.Dir(outside)), "secret.txt"), link} {
+		if _, err := v.Validate(path); err == nil || !strings.Contains(err.Error(), ErrPathOutsideRoot.Error()) {
+			t.Errorf("Validate(%s) err = %v, want ErrPathOutsideRoot", path, err)
+		}
+	}
+}
@@ -2,22 +2,32 @@ package server
 
 import (
 	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/fairyhunter13/auto-bmad/apps/core/internal/checkpoint"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/journey"
 	"github.com/fairyhunter13/auto-bmad/apps/core/internal/opencode"
 	"github.com/fairyhunter13/auto-bmad/apps/core/internal/project"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/state"
 )
 
 // RegisterProjectHandlers registers project-related JSON-RPC methods.
 func RegisterProjectHandlers(s *Server) {
 	s.RegisterHandler("project.detectDependencies", handleDetectDependencies)
 	s.RegisterHandler("project.scan", handleProjectScan)
-	s.RegisterHandler("project.getRecent", handleGetRecent)
+	s.RegisterHandler("project.getRecent", withRecentRefresh(s, handleGetRecent))
 	s.RegisterHandler("project.addRecent", handleAddRecent)
 	s.RegisterHandler("project.removeRecent", handleRemoveRecent)
+	s.RegisterHandler("project.pinRecent", handlePinRecent)
 	s.RegisterHandler("project.setContext", handleSetContext)
 	s.RegisterHandler("project.getLastProfile", handleGetLastProfile)
 	s.RegisterHandler("project.setLastProfile", handleSetLastProfile)
+	s.RegisterHandler("project.open", handleProjectOpen(s.workspace))
+	s.RegisterHandler("project.close", handleProjectClose(s.workspace))
+	s.RegisterHandler("project.listOpen", handleProjectListOpen(s.workspace))
 }
 
 // handleDetectDependencies detects and validates system dependencies.
@@ -62,7 +72,8 @@ func handleProjectScan(params json.RawMessage) (interface{}, error) {
 	return result, nil
 }
 
-// handleGetRecent returns the list of recent projects
+// handleGetRecent returns the list of recent projects with their cached
+// health summaries
 func handleGetRecent(params json.RawMessage) (interface{}, error) {
 	rm := project.GetRecentManager()
 	projects, err := rm.GetAll()
@@ -73,6 +84,117 @@ func handleGetRecent(params json.RawMessage) (interface{}, error) {
 	return projects, nil
 }
 
+// recentRefresh makes sure only one background health check of the recent
+// projects runs at a time.
+var recentRefresh struct {
+	mu      sync.Mutex
+	running bool
+}
+
+// withRecentRefresh wraps project.getRecent so that each call also starts a
+// background recheck of the recent projects, which emits
+// project.recentUpdated when any of them changed.
+func withRecentRefresh(s *Server, next Handler) Handler {
+	return func(params json.RawMessage) (interface{}, error) {
+		result, err := next(params)
+		if err == nil {
+			startRecentRefresh(s, project.GetRecentManager())
+		}
+		return result, err
+	}
+}
+
+// startRecentRefresh rechecks the recent projects in the background unless
+// a check is already running.
+func startRecentRefresh(s *Server, rm *project.RecentManager) {
+	recentRefresh.mu.Lock()
+	defer recentRefresh.mu.Unlock()
+	if recentRefresh.running {
+		return
+	}
+	recentRefresh.running = true
+
+	go func() {
+		defer func() {
+			recentRefresh.mu.Lock()
+			recentRefresh.running = false
+			recentRefresh.mu.Unlock()
+		}()
+		if err := refreshRecentHealth(s, rm); err != nil {
+			s.logger.Printf("Failed to refresh recent projects: %v", err)
+		}
+	}()
+}
+
+// refreshRecentHealth scans every recent project, stores the summaries and,
+// if any changed, emits project.recentUpdated with the updated list.
+// Missing and moved projects are marked rather than removed.
+func refreshRecentHealth(s *Server, rm *project.RecentManager) error {
+	projects, err := rm.GetAll()
+	if err != nil {
+		return err
+	}
+
+	health := make(map[string]*project.ProjectHealth, len(projects))
+	changed := false
+	for _, p := range projects {
+		h := project.CheckHealth(p.Path)
+		if h.Status == project.HealthOK {
+			h.LastJourney = lastJourneyOutcome(s, p.Path)
+		}
+		health[p.Path] = h
+		if !sameHealth(p.Health, h) {
+			changed = true
+		}
+	}
+	if err := rm.SetHealth(health); err != nil {
+		return err
+	}
+
+	if changed {
+		updated, err := rm.GetAll()
+		if err != nil {
+			return err
+		}
+		emitProjectEvent(s, "project.recentUpdated", updated)
+	}
+	return nil
+}
+
+// lastJourneyOutcome returns the newest finished journey of a project, from
+// its engine if the project is open. Projects that never ran a journey
+// return nil and are left untouched.
+func lastJourneyOutcome(s *Server, path string) *project.JourneyOutcome {
+	var history *state.History
+	if sess := s.workspace.lookup(path); sess != nil && sess.Engine != nil {
+		history = sess.Engine.History()
+	} else {
+		history = journey.OpenHistory(path)
+		if _, err := os.Stat(filepath.Dir(history.Path())); err != nil {
+			return nil
+		}
+	}
+
+	page, err := history.List(0, 1)
+	if err != nil || len(page.Records) == 0 {
+		return nil
+	}
+	rec := page.Records[0]
+	return &project.JourneyOutcome{ID: rec.ID, Destination: rec.Destination, Status: rec.Status, CompletedAt: rec.CompletedAt}
+}
+
+// sameHealth reports whether two summaries match, ignoring when they were taken.
+func sameHealth(a, b *project.ProjectHealth) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	ac, bc := *a, *b
+	ac.CheckedAt, bc.CheckedAt = time.Time{}, time.Time{}
+	aj, _ := json.Marshal(ac)
+	bj, _ := json.Marshal(bc)
+	return string(aj) == string(bj)
+}
+
 // AddRecentParams represents the parameters for project.addRecent
 type AddRecentParams struct {
 	Path string `json:"path"`
@@ -137,6 +259,45 @@ func handleRemoveRecent(params json.RawMessage) (interface{}, error) {
 	return nil, nil
 }
 
+// PinRecentParams represents the parameters for project.pinRecent
+type PinRecentParams struct {
+	Path   string `json:"path"`
+	Pinned bool   `json:"pinned"`
+}
+
+// handlePinRecent pins or unpins a recent project. Pinned projects are
+// listed first and never dropped by the recentProjectsMax limit.
+// Method: project.pinRecent
+// Params: { "path": string, "pinned": boolean }
+// Result: null
+func handlePinRecent(params json.RawMessage) (interface{}, error) {
+	var p PinRecentParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", err.Error())
+	}
+
+	if p.Path == "" {
+		return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", "path is required")
+	}
+
+	// Validate path (allow non-existent so moved projects can be unpinned)
+	validator := &PathValidator{
+		AllowNonExistent: true,
+		RequireDirectory: false,
+	}
+	validatedPath, err := validator.Validate(p.Path)
+	if err != nil {
+		return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid path", err.Error())
+	}
+
+	rm := project.GetRecentManager()
+	if err := rm.Pin(validatedPath, p.Pinned); err != nil {
+		return nil, NewErrorWithData(ErrCodeInvalidParams, "Failed to pin recent project", err.Error())
+	}
+
+	return nil, nil
+}
+
 // SetContextParams represents the parameters for project.setContext
 type SetContextParams struct {
 	Path    string `json:"path"`
@@ -223,7 +384,7 @@ func handleSetLastProfile(params json.RawMessage) (interface{}, error) {
 		return nil, NewErrorWithData(ErrCodeInternalError, "Settings manager not initialized", "")
 	}
 
-	err := settingsManager.Set(map[string]interface{}{
+	err := settingsManager.SetScope(state.ScopeUser, map[string]interface{}{
 		"projectProfiles": map[string]interface{}{p.Path: p.Profile},
 	})
 	if err != nil {
@@ -232,3 +393,81 @@ func handleSetLastProfile(params json.RawMessage) (interface{}, error) {
 
 	return map[string]string{"status": "ok"}, nil
 }
+
+// ProjectOpenParams represents the parameters for project.open
+type ProjectOpenParams struct {
+	Path string `json:"path"`
+}
+
+// ProjectOpenResult is the result of project.open.
+type ProjectOpenResult struct {
+	ProjectInfo
+	AlreadyOpen bool `json:"alreadyOpen"`
+}
+
+// handleProjectOpen opens a project in the workspace and makes it active.
+// The project gets its own settings, journey engine and artifact watcher.
+// Method: project.open
+// Params: { "path": string }
+// Result: ProjectOpenResult
+func handleProjectOpen(ws *Workspace) Handler {
+	return func(params json.RawMessage) (interface{}, error) {
+		var p ProjectOpenParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", err.Error())
+		}
+		if p.Path == "" {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", "path is required")
+		}
+
+		sess, alreadyOpen, err := ws.Open(p.Path)
+		if err != nil {
+			return nil, err
+		}
+		return ProjectOpenResult{ProjectInfo: ws.Describe(sess), AlreadyOpen: alreadyOpen}, nil
+	}
+}
+
+// ProjectCloseParams represents the parameters for project.close
+type ProjectCloseParams struct {
+	Path  string `json:"path"`
+	Force bool   `json:"force,omitempty"` // Abort running journeys
+}
+
+// handleProjectClose closes an opened project and stops its watcher.
+// Method: project.close
+// Params: { "path": string, "force"?: boolean }
+// Result: { "status": "ok" }
+func handleProjectClose(ws *Workspace) Handler {
+	return func(params json.RawMessage) (interface{}, error) {
+		var p ProjectCloseParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", err.Error())
+		}
+		if p.Path == "" {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", "path is required")
+		}
+
+		// The directory may have been removed since it was opened
+		validator := &PathValidator{AllowNonExistent: true, RequireDirectory: false}
+		validatedPath, err := validator.Validate(p.Path)
+		if err != nil {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid path", err.Error())
+		}
+
+		if err := ws.Close(validatedPath, p.Force); err != nil {
+			return nil, err
+		}
+		return map[string]string{"status": "ok"}, nil
+	}
+}
+
+// handleProjectListOpen returns the projects opened in the workspace.
+// Method: project.listOpen
+// Params: none
+// Result: ProjectInfo[]
+func handleProjectListOpen(ws *Workspace) Handler {
+	return func(params json.RawMessage) (interface{}, error) {
+		return ws.Projects(), nil
+	}
+}
@@ -2,11 +2,18 @@ package server
 
 import (
 	"encoding/json"
+	"io"
+	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/journey"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/paths"
 	"github.com/fairyhunter13/auto-bmad/apps/core/internal/project"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/state"
 )
 
 func TestHandleGetRecent(t *testing.T) {
@@ -182,3 +189,79 @@ func TestHandleSetContext_NonexistentProject(t *testing.T) {
 		t.Error("Expected error for nonexistent project")
 	}
 }
+
+func TestHandlePinRecent(t *testing.T) {
+	t.Setenv(paths.HomeEnv, t.TempDir())
+	project.InitRecentManager()
+	rm := project.GetRecentManager()
+	rm.Add("/home/user/project1")
+	rm.Add("/home/user/project2")
+
+	paramsJSON, _ := json.Marshal(PinRecentParams{Path: "/home/user/project1", Pinned: true})
+	if _, err := handlePinRecent(paramsJSON); err != nil {
+		t.Fatalf("handlePinRecent failed: %v", err)
+	}
+
+	projects, _ := rm.GetAll()
+	if len(projects) != 2 || projects[0].Path != "/home/user/project1" || !projects[0].Pinned {
+		t.Errorf("Expected project1 pinned and listed first, got %+v", projects)
+	}
+
+	paramsJSON, _ = json.Marshal(PinRecentParams{Path: "/home/user/unknown", Pinned: true})
+	if _, err := handlePinRecent(paramsJSON); err == nil {
+		t.Error("Expected error pinning an unknown project")
+	}
+	if _, err := handlePinRecent(json.RawMessage(`{"pinned": true}`)); err == nil {
+		t.Error("Expected error for missing path")
+	}
+}
+
+func TestRefreshRecentHealth(t *testing.T) {
+	tmpDir := t.TempDir()
+	rm := project.NewRecentManager(filepath.Join(tmpDir, "recent.json"), 5)
+
+	bmadDir := filepath.Join(tmpDir, "bmad")
+	os.MkdirAll(filepath.Join(bmadDir, "_bmad"), 0755)
+	finished := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	rec := state.HistoryRecord{ID: "j1", Destination: "prd", Status: "completed", CompletedAt: finished}
+	if err := journey.OpenHistory(bmadDir).Record(rec); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	movedDir := filepath.Join(tmpDir, "moved")
+	rm.Add(movedDir)
+	rm.Add(bmadDir)
+
+	out := &lockedBuffer{}
+	srv := New(nil, out, log.New(io.Discard, "", 0), tmpDir)
+	if err := refreshRecentHealth(srv, rm); err != nil {
+		t.Fatalf("refreshRecentHealth failed: %v", err)
+	}
+
+	projects, _ := rm.GetAll()
+	health := map[string]*project.ProjectHealth{}
+	for _, p := range projects {
+		health[p.Path] = p.Health
+	}
+	if h := health[movedDir]; h == nil || h.Status != project.HealthMissing {
+		t.Errorf("Expected moved project marked missing, got %+v", h)
+	}
+	h := health[bmadDir]
+	if h == nil || h.Status != project.HealthOK || h.LastJourney == nil {
+		t.Fatalf("Expected ok project with last journey, got %+v", h)
+	}
+	if h.LastJourney.ID != "j1" || h.LastJourney.Status != "completed" || !h.LastJourney.CompletedAt.Equal(finished) {
+		t.Errorf("Unexpected last journey %+v", h.LastJourney)
+	}
+	if !strings.Contains(out.String(), "project.recentUpdated") {
+		t.Errorf("Expected project.recentUpdated event, got %q", out.String())
+	}
+
+	// Nothing changed: no second event
+	before := strings.Count(out.String(), "project.recentUpdated")
+	if err := refreshRecentHealth(srv, rm); err != nil {
+		t.Fatalf("refreshRecentHealth failed: %v", err)
+	}
+	if strings.Count(out.String(), "project.recentUpdated") != before {
+		t.Error("Expected no event when nothing changed")
+	}
+}
@@ -0,0 +1,110 @@
+// Package server provides the project filesystem watcher.
+package server
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/project"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/watcher"
+)
+
+// ArtifactEvent is the payload of artifact.created, artifact.modified and artifact.deleted.
+type ArtifactEvent struct {
+	ProjectPath string            `json:"projectPath"`
+	Path        string            `json:"path"` // Relative to _bmad-output/
+	Artifact    *project.Artifact `json:"artifact,omitempty"`
+}
+
+// ConfigChangedEvent is the payload of project.configChanged.
+type ConfigChangedEvent struct {
+	ProjectPath string     `json:"projectPath"`
+	Path        string     `json:"path"` // Relative to the project root
+	Change      watcher.Op `json:"change"`
+}
+
+// StartProjectWatcher watches the project's _bmad/ and _bmad-output/ trees
+// and emits artifact.* and project.configChanged events. A previous watcher
+// of the same project is stopped first; Workspace.Close and
+// Workspace.Shutdown stop it.
+func StartProjectWatcher(s *Server, projectPath string) error {
+	w, err := newProjectWatcher(s, projectPath)
+	if err != nil {
+		return err
+	}
+
+	var previous *watcher.Watcher
+	s.workspace.attach(projectPath, func(p *ProjectSession) {
+		previous = p.watcher
+		p.watcher = w
+	})
+	if previous != nil {
+		previous.Stop()
+	}
+	return nil
+}
+
+// newProjectWatcher creates and starts the watcher of a project.
+func newProjectWatcher(s *Server, projectPath string) (*watcher.Watcher, error) {
+	bmadPath := filepath.Join(projectPath, "_bmad")
+	outputPath := filepath.Join(projectPath, "_bmad-output")
+	autobmadPath := filepath.Join(outputPath, ".autobmad")
+	configPath := filepath.Join(autobmadPath, "config.json")
+
+	w := watcher.New([]string{bmadPath, outputPath}, watcher.Options{
+		Ignore: func(path string, isDir bool) bool {
+			return ignoreProjectPath(path, isDir, autobmadPath, configPath)
+		},
+	}, func(e watcher.Event) {
+		if e.Root == bmadPath || e.Path == configPath {
+			rel, _ := filepath.Rel(projectPath, e.Path)
+			emitProjectEvent(s, "project.configChanged", ConfigChangedEvent{ProjectPath: projectPath, Path: filepath.ToSlash(rel), Change: e.Op})
+			if e.Path == configPath {
+				// Our own writes are recognized and not reported again
+				reloadSettings(s, s.workspace.lookup(projectPath))
+			}
+			return
+		}
+
+		rel, err := filepath.Rel(outputPath, e.Path)
+		if err != nil {
+			return
+		}
+		event := ArtifactEvent{ProjectPath: projectPath, Path: filepath.ToSlash(rel)}
+		if e.Op != watcher.Deleted {
+			if artifact, err := project.ReadArtifact(outputPath, rel); err == nil {
+				event.Artifact = artifact
+			}
+		}
+		emitProjectEvent(s, "artifact."+string(e.Op), event)
+	})
+	if err := w.Start(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// ignoreProjectPath skips editor and atomic-write temp files, hidden
+// directories, and Auto-BMAD's own state except config.json.
+func ignoreProjectPath(path string, isDir bool, autobmadPath, configPath string) bool {
+	name := filepath.Base(path)
+	if isDir {
+		if path == autobmadPath {
+			return false
+		}
+		return strings.HasPrefix(name, ".") || strings.HasPrefix(path, autobmadPath+string(filepath.Separator))
+	}
+	if strings.HasPrefix(path, autobmadPath+string(filepath.Separator)) {
+		return path != configPath
+	}
+	return strings.HasSuffix(name, ".tmp") || strings.HasSuffix(name, "~") ||
+		strings.HasSuffix(name, ".swp") || strings.HasSuffix(name, ".swx") ||
+		strings.HasPrefix(name, ".#") || name == ".DS_Store"
+}
+
+// emitProjectEvent sends a watcher event, logging failures.
+func emitProjectEvent(s *Server, event string, data interface{}) {
+	if err := s.EmitEvent(event, data); err != nil {
+		s.logger.Printf("Failed to emit %s event: %v", event, err)
+	}
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/paths"
+)
+
+// lockedBuffer is a bytes.Buffer safe for the watcher goroutine to write to.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// waitForOutput polls out until it contains all substrings.
+func waitForOutput(t *testing.T, out *lockedBuffer, substrings ...string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		done := true
+		for _, s := range substrings {
+			done = done && strings.Contains(out.String(), s)
+		}
+		if done {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("output missing %q:\n%s", substrings, out.String())
+}
+
+func TestProjectWatcher(t *testing.T) {
+	dir := t.TempDir()
+	for _, d := range []string{"_bmad/_config", "_bmad-output/.autobmad", "_bmad-output/planning-artifacts"} {
+		if err := os.MkdirAll(filepath.Join(dir, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := &lockedBuffer{}
+	srv := New(nil, out, log.New(io.Discard, "", 0), dir)
+	if err := StartProjectWatcher(srv, dir); err != nil {
+		t.Fatalf("StartProjectWatcher failed: %v", err)
+	}
+	defer srv.Workspace().Shutdown()
+
+	write := func(rel, content string) {
+		if err := os.WriteFile(filepath.Join(dir, rel), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("_bmad-output/planning-artifacts/prd.md", "# PRD\n")
+	write("_bmad-output/.autobmad/config.json.tmp", "{}")
+	write("_bmad-output/.autobmad/history.json", "{}")
+	write("_bmad/_config/manifest.yaml", "version: 6.0.0\n")
+
+	waitForOutput(t, out,
+		`"method":"artifact.created"`, `"path":"planning-artifacts/prd.md"`, `"type":"prd"`,
+		`"method":"project.configChanged"`, `"path":"_bmad/_config/manifest.yaml"`)
+
+	if err := os.Rename(filepath.Join(dir, "_bmad-output/.autobmad/config.json.tmp"), filepath.Join(dir, "_bmad-output/.autobmad/config.json")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "_bmad-output/planning-artifacts/prd.md")); err != nil {
+		t.Fatal(err)
+	}
+	waitForOutput(t, out, `"path":"_bmad-output/.autobmad/config.json"`, `"method":"artifact.deleted"`)
+
+	for _, ignored := range []string{"config.json.tmp", "history.json"} {
+		if strings.Contains(out.String(), ignored) {
+			t.Errorf("event emitted for ignored file %s:\n%s", ignored, out.String())
+		}
+	}
+}
+
+func TestProjectWatcher_ReloadsSettings(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv(paths.HomeEnv, home)
+	dir := t.TempDir()
+
+	out := &lockedBuffer{}
+	srv := New(nil, out, log.New(io.Discard, "", 0), dir)
+	if err := RegisterSettingsHandlers(srv, dir); err != nil {
+		t.Fatalf("RegisterSettingsHandlers failed: %v", err)
+	}
+	if err := StartProjectWatcher(srv, dir); err != nil {
+		t.Fatalf("StartProjectWatcher failed: %v", err)
+	}
+	defer srv.Workspace().Shutdown()
+
+	// A change through the API is reported once, as not external
+	if _, err := srv.handlers["settings.set"](json.RawMessage(`{"maxRetries": 4}`)); err != nil {
+		t.Fatalf("settings.set failed: %v", err)
+	}
+	waitForOutput(t, out, `"method":"settings.changed"`, `"keys":["maxRetries"],"external":false`)
+
+	// Another process edits the file
+	configPath := filepath.Join(dir, "_bmad-output", ".autobmad", "config.json")
+	if err := os.WriteFile(configPath, []byte(`{"schemaVersion": 2, "maxRetries": 4, "theme": "dark"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitForOutput(t, out, `"keys":["theme"],"external":true`)
+	sess, err := srv.Workspace().Resolve("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sess.Settings.Get().Theme; got != "dark" {
+		t.Errorf("theme = %q after external edit", got)
+	}
+	if n := strings.Count(out.String(), `"method":"settings.changed"`); n != 2 {
+		t.Errorf("settings.changed emitted %d times:\n%s", n, out.String())
+	}
+
+	// The user-global file is watched too, for the projects and the process
+	userPath := filepath.Join(home, "config.json")
+	os.MkdirAll(filepath.Dir(userPath), 0755)
+	if err := os.WriteFile(userPath, []byte(`{"schemaVersion": 2, "soundEnabled": true, "recentProjectsMax": 7}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitForOutput(t, out, `"keys":["recentProjectsMax","soundEnabled"],"external":true`)
+	if got := settingsManager.Get().RecentProjectsMax; got != 7 {
+		t.Errorf("process-wide recentProjectsMax = %d, want 7", got)
+	}
+}
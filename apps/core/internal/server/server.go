@@ -22,6 +22,7 @@ type Server struct {
 	handlers    map[string]Handler
 	logger      *log.Logger
 	projectPath string       // Path to BMAD project root
+	workspace   *Workspace   // Opened projects
 	mu          sync.RWMutex // protects handlers map
 }
 
@@ -29,14 +30,20 @@ type Server struct {
 // stdin and stdout are the I/O streams for JSON-RPC communication.
 // logger should write to stderr (stdout is reserved for JSON-RPC).
 // projectPath is the path to the BMAD project root (for project-local settings).
+// A nil stdout discards events, for servers driven without a client.
 func New(stdin io.Reader, stdout io.Writer, logger *log.Logger, projectPath string) *Server {
-	return &Server{
+	if stdout == nil {
+		stdout = io.Discard
+	}
+	s := &Server{
 		reader:      NewMessageReader(stdin),
 		writer:      NewMessageWriter(stdout),
 		handlers:    make(map[string]Handler),
 		logger:      logger,
 		projectPath: projectPath,
 	}
+	s.workspace = newWorkspace(s)
+	return s
 }
 
 // RegisterHandler registers a handler for the given method name.
@@ -53,6 +60,11 @@ func (s *Server) ProjectPath() string {
 	return s.projectPath
 }
 
+// Workspace returns the projects opened in this server.
+func (s *Server) Workspace() *Workspace {
+	return s.workspace
+}
+
 // EmitEvent sends a server-initiated notification to the client.
 // Event names should follow the "resource.event" convention.
 // This sends a JSON-RPC notification (no ID, no response expected).
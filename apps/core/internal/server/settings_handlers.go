@@ -2,19 +2,41 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/project"
 	"github.com/fairyhunter13/auto-bmad/apps/core/internal/state"
 )
 
-// settingsManager is the global settings manager instance
+// settingsManager owns the process-wide settings (see processSettings):
+// the user-global layer and AUTOBMAD_* overrides. Project-scoped settings
+// come from the ProjectSession handling a request.
 var settingsManager *state.StateManager
 
+// processSettings apply to the whole process rather than to one project, so
+// they are read from settingsManager only and set in the user layer.
+var processSettings = state.ProcessSettings()
+
 // RegisterSettingsHandlers registers all settings-related JSON-RPC handlers.
-// These handlers manage user settings persistence and retrieval.
-// Settings are stored per-project in <project>/_bmad-output/.autobmad/ to support
-// multi-project workflows where each project can have different settings.
+// Settings resolve from defaults, the user-global file, the project file in
+// <project>/_bmad-output/.autobmad/ and AUTOBMAD_* variables, so each project
+// can have different settings. Process-wide settings (see processSettings)
+// are owned by settingsManager and can only be set with scope "user".
 func RegisterSettingsHandlers(s *Server, projectPath string) error {
+	// Process-wide settings; the user layer is loaded first so a corrupt
+	// file is quarantined once
+	us, err := state.NewUserStateManager(state.UserConfigPath())
+	if err != nil {
+		return err
+	}
+	logQuarantined(s, us)
+	us.OnChange = func(c state.Change) { applyProcessSettings(s, c.Keys) }
+	settingsManager = us
+	applyRecentProjectsMax(s)
+
 	// Store settings in project-local directory per architecture.md specification
 	// Path: <project>/_bmad-output/.autobmad/config.json
 	// NewStateManager will append _bmad-output/.autobmad automatically
@@ -22,32 +44,149 @@ func RegisterSettingsHandlers(s *Server, projectPath string) error {
 	if err != nil {
 		return fmt.Errorf("creating state manager: %w", err)
 	}
+	logQuarantined(s, sm)
+	logFileErrors(s, sm)
+	notifySettingsChanges(s, projectPath, sm)
+	s.workspace.attach(projectPath, func(p *ProjectSession) { p.Settings = sm })
 
-	// Store globally for access by handlers
-	settingsManager = sm
+	// Register handlers; each acts on the selected project's settings
+	s.RegisterHandler("settings.get", s.projectHandler(func(p *ProjectSession) Handler { return handleSettingsGet(p.Settings) }))
+	s.RegisterHandler("settings.set", s.projectHandler(func(p *ProjectSession) Handler { return handleSettingsSet(p.Settings) }))
+	s.RegisterHandler("settings.reset", s.projectHandler(func(p *ProjectSession) Handler { return handleSettingsReset(p.Settings) }))
+	s.RegisterHandler("settings.schema", handleSettingsSchema)
+	registerSettingsTransferHandlers(s)
 
-	// Register handlers
-	s.RegisterHandler("settings.get", handleSettingsGet(sm))
-	s.RegisterHandler("settings.set", handleSettingsSet(sm))
-	s.RegisterHandler("settings.reset", handleSettingsReset(sm))
+	// Pick up edits of the user-global settings made outside this process
+	if err := s.workspace.watchUserSettings(state.UserConfigPath()); err != nil {
+		s.logger.Printf("Failed to watch user settings: %v", err)
+	}
 
 	return nil
 }
 
+// SettingsChangedEvent is the payload of settings.changed.
+type SettingsChangedEvent struct {
+	ProjectPath string   `json:"projectPath"`
+	Keys        []string `json:"keys"`     // Settings whose effective value changed
+	External    bool     `json:"external"` // Changed by another process or an editor
+}
+
+// notifySettingsChanges emits settings.changed whenever the effective
+// settings of a project change. A change of a process-wide setting may have
+// been made in the user layer, so settingsManager re-reads it.
+func notifySettingsChanges(s *Server, projectPath string, sm *state.StateManager) {
+	sm.OnChange = func(c state.Change) {
+		emitProjectEvent(s, "settings.changed", SettingsChangedEvent{ProjectPath: projectPath, Keys: c.Keys, External: c.External})
+		if slices.ContainsFunc(c.Keys, func(key string) bool { return slices.Contains(processSettings, key) }) {
+			reloadUserSettings(s)
+		}
+	}
+}
+
+// applyProcessSettings applies changed process-wide settings.
+func applyProcessSettings(s *Server, keys []string) {
+	if slices.Contains(keys, "recentProjectsMax") {
+		applyRecentProjectsMax(s)
+	}
+	if slices.Contains(keys, "networkCheckInterval") || slices.Contains(keys, "networkDebounce") {
+		applyNetworkTiming()
+	}
+}
+
+// applyRecentProjectsMax limits the recent projects list to the
+// recentProjectsMax setting.
+func applyRecentProjectsMax(s *Server) {
+	if settingsManager == nil {
+		return
+	}
+	if err := project.GetRecentManager().SetMaxRecent(settingsManager.Get().RecentProjectsMax); err != nil {
+		s.logger.Printf("Failed to apply recentProjectsMax: %v", err)
+	}
+}
+
+// reloadUserSettings applies changes of the user-global settings file to
+// settingsManager; its OnChange applies them.
+func reloadUserSettings(s *Server) {
+	if settingsManager == nil {
+		return
+	}
+	if _, err := settingsManager.Reload(); err != nil {
+		s.logger.Printf("Failed to reload user settings: %v", err)
+	}
+}
+
+// reloadSettings applies external edits of a project's settings files;
+// OnChange reports what changed. A file that no longer parses keeps its
+// last good values until it is fixed.
+func reloadSettings(s *Server, sess *ProjectSession) {
+	if sess == nil || sess.Settings == nil {
+		return
+	}
+	if _, err := sess.Settings.Reload(); err != nil {
+		s.logger.Printf("Failed to reload settings of %s: %v", sess.Path, err)
+	}
+	logFileErrors(s, sess.Settings)
+}
+
+// logQuarantined reports settings files that were corrupt and moved aside,
+// so the user can recover them by hand.
+func logQuarantined(s *Server, sm *state.StateManager) {
+	for _, q := range sm.Quarantined() {
+		s.logger.Printf("Corrupt %s settings file %s moved to %s, using defaults: %s", q.Scope, q.Path, q.MovedTo, q.Error)
+	}
+}
+
+// logFileErrors reports invalid values in the settings files, which are
+// ignored until they are fixed.
+func logFileErrors(s *Server, sm *state.StateManager) {
+	for _, e := range sm.FileErrors() {
+		s.logger.Printf("Ignoring invalid setting in %s", e)
+	}
+}
+
+// SettingsGetParams are the optional parameters of settings.get.
+type SettingsGetParams struct {
+	Scope       string `json:"scope,omitempty"`       // Return only this layer's values
+	WithSources bool   `json:"withSources,omitempty"` // Return EffectiveSettings
+}
+
 // handleSettingsGet returns the current settings.
 // Method: settings.get
-// Params: none
-// Result: Settings object
+// Params: none, or { "scope"?: "default"|"user"|"project"|"env", "withSources"?: boolean }
+// Result: Settings object; EffectiveSettings with withSources; the layer's
+// values with scope
 func handleSettingsGet(sm *state.StateManager) Handler {
 	return func(params json.RawMessage) (interface{}, error) {
+		var p SettingsGetParams
+		if len(params) > 0 && string(params) != "null" {
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", err.Error())
+			}
+		}
+
+		if p.Scope != "" {
+			scope, err := state.ParseScope(p.Scope)
+			if err != nil {
+				return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid scope", err.Error())
+			}
+			values, err := sm.Layer(scope)
+			if err != nil {
+				return nil, NewErrorWithData(ErrCodeInternalError, "Failed to read settings", err.Error())
+			}
+			return values, nil
+		}
+		if p.WithSources {
+			return sm.Effective(), nil
+		}
 		return sm.Get(), nil
 	}
 }
 
 // handleSettingsSet updates settings with provided values.
 // Method: settings.set
-// Params: map of setting keys to values
-// Result: Updated Settings object
+// Params: map of setting keys to values, with an optional "scope" key
+// ("project", the default, or "user"); process-wide settings need "user"
+// Result: Updated Settings object (effective values)
 func handleSettingsSet(sm *state.StateManager) Handler {
 	return func(params json.RawMessage) (interface{}, error) {
 		// Parse update map
@@ -56,8 +195,24 @@ func handleSettingsSet(sm *state.StateManager) Handler {
 			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", err.Error())
 		}
 
+		scope, err := scopeParam(updates)
+		if err != nil {
+			return nil, err
+		}
+		delete(updates, "scope")
+		delete(updates, "projectPath")
+		if scope == state.ScopeProject {
+			if errs := projectScopeErrors(updates); len(errs) > 0 {
+				return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid settings", errs)
+			}
+		}
+
 		// Apply updates
-		if err := sm.Set(updates); err != nil {
+		if err := sm.SetScope(scope, updates); err != nil {
+			var verr *state.ValidationError
+			if errors.As(err, &verr) {
+				return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid settings", verr.Errors)
+			}
 			return nil, NewErrorWithData(ErrCodeInternalError, "Failed to save settings", err.Error())
 		}
 
@@ -66,13 +221,24 @@ func handleSettingsSet(sm *state.StateManager) Handler {
 	}
 }
 
-// handleSettingsReset resets all settings to defaults.
+// handleSettingsReset clears the settings of one layer.
 // Method: settings.reset
-// Params: none
-// Result: Default Settings object
+// Params: none, or { "scope"?: "project"|"user" } (default "project")
+// Result: Settings object after the reset
 func handleSettingsReset(sm *state.StateManager) Handler {
 	return func(params json.RawMessage) (interface{}, error) {
-		if err := sm.Reset(); err != nil {
+		var p map[string]interface{}
+		if len(params) > 0 && string(params) != "null" {
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", err.Error())
+			}
+		}
+		scope, err := scopeParam(p)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := sm.ResetScope(scope); err != nil {
 			return nil, NewErrorWithData(ErrCodeInternalError, "Failed to reset settings", err.Error())
 		}
 
@@ -80,3 +246,42 @@ func handleSettingsReset(sm *state.StateManager) Handler {
 		return sm.Get(), nil
 	}
 }
+
+// handleSettingsSchema describes every setting: type, constraints, default
+// and description, so the UI can render the settings page.
+// Method: settings.schema
+// Params: none
+// Result: SchemaField[]
+func handleSettingsSchema(params json.RawMessage) (interface{}, error) {
+	return state.Schema(), nil
+}
+
+// projectScopeErrors rejects process-wide settings in a project-scope
+// update; saved in the project file, they would never take effect.
+func projectScopeErrors(updates map[string]interface{}) []state.FieldError {
+	var errs []state.FieldError
+	for key := range updates {
+		if slices.Contains(processSettings, key) {
+			errs = append(errs, state.FieldError{Key: key, Message: `applies to all projects; set it with scope "user"`})
+		}
+	}
+	slices.SortFunc(errs, func(a, b state.FieldError) int { return strings.Compare(a.Key, b.Key) })
+	return errs
+}
+
+// scopeParam reads the writable scope named by the "scope" parameter,
+// defaulting to the project scope.
+func scopeParam(params map[string]interface{}) (state.Scope, error) {
+	name, ok := params["scope"].(string)
+	if _, present := params["scope"]; present && !ok {
+		return "", NewErrorWithData(ErrCodeInvalidParams, "Invalid scope", "scope must be a string")
+	}
+	scope, err := state.ParseScope(name)
+	if err != nil {
+		return "", NewErrorWithData(ErrCodeInvalidParams, "Invalid scope", err.Error())
+	}
+	if !scope.Writable() {
+		return "", NewErrorWithData(ErrCodeInvalidParams, "Invalid scope", fmt.Sprintf("scope %q is read-only", scope))
+	}
+	return scope, nil
+}
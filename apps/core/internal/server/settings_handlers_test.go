@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/paths"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/project"
 	"github.com/fairyhunter13/auto-bmad/apps/core/internal/state"
 )
 
@@ -243,7 +245,6 @@ func TestSettingsHandlersPersistenceAcrossRestart(t *testing.T) {
 		"retryDelay":           8000,
 		"theme":                "dark",
 		"desktopNotifications": false,
-		"lastProjectPath":      "/custom/project/path",
 	}
 	params, _ := json.Marshal(customSettings)
 
@@ -251,6 +252,10 @@ func TestSettingsHandlersPersistenceAcrossRestart(t *testing.T) {
 	if err != nil {
 		t.Fatalf("settings.set failed on server 1: %v", err)
 	}
+	// Process-wide settings are saved in the user layer
+	if _, err := setHandler1(json.RawMessage(`{"scope": "user", "lastProjectPath": "/custom/project/path"}`)); err != nil {
+		t.Fatalf("settings.set (user) failed on server 1: %v", err)
+	}
 
 	// Verify settings were set on server 1
 	settings1, ok := result1.(*state.Settings)
@@ -371,3 +376,139 @@ func TestSettingsHandlersPersistenceAcrossRestart(t *testing.T) {
 	t.Log("PHASE 4 complete: Multiple restarts with updates work correctly")
 	t.Log("✅ Integration test PASSED: Settings persistence across restarts verified")
 }
+
+// TestSettingsHandlersScopes verifies scoped settings.set and settings.get with sources
+func TestSettingsHandlersScopes(t *testing.T) {
+	t.Setenv(paths.HomeEnv, t.TempDir())
+	tmpDir := t.TempDir()
+
+	srv := New(nil, nil, log.New(io.Discard, "", 0), tmpDir)
+	if err := RegisterSettingsHandlers(srv, tmpDir); err != nil {
+		t.Fatalf("RegisterSettingsHandlers failed: %v", err)
+	}
+
+	if _, err := srv.handlers["settings.set"](json.RawMessage(`{"scope": "user", "theme": "dark"}`)); err != nil {
+		t.Fatalf("settings.set (user) failed: %v", err)
+	}
+	if _, err := srv.handlers["settings.set"](json.RawMessage(`{"maxRetries": 4}`)); err != nil {
+		t.Fatalf("settings.set (project) failed: %v", err)
+	}
+	_, err := srv.handlers["settings.set"](json.RawMessage(`{"scope": "env", "maxRetries": 4}`))
+	if rpcErr, ok := err.(*Error); !ok || rpcErr.Code != ErrCodeInvalidParams {
+		t.Errorf("settings.set (env) err = %v, want ErrCodeInvalidParams", err)
+	}
+
+	result, err := srv.handlers["settings.get"](json.RawMessage(`{"withSources": true}`))
+	if err != nil {
+		t.Fatalf("settings.get failed: %v", err)
+	}
+	eff := result.(*state.EffectiveSettings)
+	if eff.Settings.Theme != "dark" || eff.Sources["theme"] != state.ScopeUser || eff.Sources["maxRetries"] != state.ScopeProject {
+		t.Errorf("effective = %+v, sources = %v", eff.Settings, eff.Sources)
+	}
+
+	result, err = srv.handlers["settings.get"](json.RawMessage(`{"scope": "user"}`))
+	if err != nil {
+		t.Fatalf("settings.get (user) failed: %v", err)
+	}
+	if values := result.(map[string]interface{}); len(values) != 1 || values["theme"] != "dark" {
+		t.Errorf("user layer = %v", values)
+	}
+
+	result, err = srv.handlers["settings.reset"](json.RawMessage(`{"scope": "user"}`))
+	if err != nil {
+		t.Fatalf("settings.reset (user) failed: %v", err)
+	}
+	if settings := result.(*state.Settings); settings.Theme != "system" || settings.MaxRetries != 4 {
+		t.Errorf("after user reset = %+v", settings)
+	}
+}
+
+// TestSettingsSchemaAndFieldErrors verifies settings.schema and field-level errors from settings.set
+func TestSettingsSchemaAndFieldErrors(t *testing.T) {
+	t.Setenv(paths.HomeEnv, t.TempDir())
+	tmpDir := t.TempDir()
+
+	srv := New(nil, nil, log.New(io.Discard, "", 0), tmpDir)
+	if err := RegisterSettingsHandlers(srv, tmpDir); err != nil {
+		t.Fatalf("RegisterSettingsHandlers failed: %v", err)
+	}
+
+	result, err := srv.handlers["settings.schema"](nil)
+	if err != nil {
+		t.Fatalf("settings.schema failed: %v", err)
+	}
+	if fields := result.([]state.SchemaField); len(fields) == 0 || fields[0].Key != "maxRetries" || fields[0].Type != state.TypeInteger {
+		t.Errorf("schema = %+v", fields)
+	}
+
+	_, err = srv.handlers["settings.set"](json.RawMessage(`{"maxRetries": "many", "colour": "red"}`))
+	rpcErr, ok := err.(*Error)
+	if !ok || rpcErr.Code != ErrCodeInvalidParams {
+		t.Fatalf("err = %v, want ErrCodeInvalidParams", err)
+	}
+	if fieldErrors, ok := rpcErr.Data.([]state.FieldError); !ok || len(fieldErrors) != 2 {
+		t.Errorf("error data = %#v", rpcErr.Data)
+	}
+}
+
+// TestSettingsRecentProjectsMax verifies recentProjectsMax limits the recent projects list
+func TestSettingsRecentProjectsMax(t *testing.T) {
+	t.Setenv(paths.HomeEnv, t.TempDir())
+	tmpDir := t.TempDir()
+	project.InitRecentManager()
+
+	srv := New(nil, nil, log.New(io.Discard, "", 0), tmpDir)
+	if err := RegisterSettingsHandlers(srv, tmpDir); err != nil {
+		t.Fatalf("RegisterSettingsHandlers failed: %v", err)
+	}
+	if got := project.GetRecentManager().MaxRecent(); got != 10 {
+		t.Errorf("MaxRecent = %d, want 10", got)
+	}
+
+	// A process-wide setting is taken from the user layer only
+	if _, err := srv.handlers["settings.set"](json.RawMessage(`{"scope": "user", "recentProjectsMax": 3}`)); err != nil {
+		t.Fatalf("settings.set failed: %v", err)
+	}
+	if got := project.GetRecentManager().MaxRecent(); got != 3 {
+		t.Errorf("MaxRecent = %d, want 3", got)
+	}
+	_, err := srv.handlers["settings.set"](json.RawMessage(`{"scope": "project", "recentProjectsMax": 5}`))
+	if rpcErr, ok := err.(*Error); !ok || rpcErr.Code != ErrCodeInvalidParams {
+		t.Errorf("settings.set at project scope err = %v, want ErrCodeInvalidParams", err)
+	}
+	if got := project.GetRecentManager().MaxRecent(); got != 3 {
+		t.Errorf("MaxRecent = %d after a rejected project value, want 3", got)
+	}
+}
+
+// TestSettingsSetProcessSettingDefaultScope verifies that a process-wide
+// setting is not saved where it would never take effect
+func TestSettingsSetProcessSettingDefaultScope(t *testing.T) {
+	tmpDir := t.TempDir()
+	srv := New(nil, nil, log.New(io.Discard, "", 0), tmpDir)
+	if err := RegisterSettingsHandlers(srv, tmpDir); err != nil {
+		t.Fatalf("RegisterSettingsHandlers failed: %v", err)
+	}
+
+	_, err := srv.handlers["settings.set"](json.RawMessage(`{"networkDebounce": 5000, "maxRetries": 4}`))
+	rpcErr, ok := err.(*Error)
+	if !ok || rpcErr.Code != ErrCodeInvalidParams {
+		t.Fatalf("settings.set err = %v, want ErrCodeInvalidParams", err)
+	}
+	if errs, _ := rpcErr.Data.([]state.FieldError); len(errs) != 1 || errs[0].Key != "networkDebounce" {
+		t.Errorf("error data = %+v, want a networkDebounce field error", rpcErr.Data)
+	}
+	sess, _ := srv.Workspace().Resolve("")
+	if values, _ := sess.Settings.Layer(state.ScopeProject); len(values) != 0 {
+		t.Errorf("project layer = %v, want nothing saved", values)
+	}
+
+	// With the user scope it reaches the process-wide settings
+	if _, err := srv.handlers["settings.set"](json.RawMessage(`{"scope": "user", "networkDebounce": 5000}`)); err != nil {
+		t.Fatalf("settings.set (user) failed: %v", err)
+	}
+	if got := settingsManager.Get().NetworkDebounce; got != 5000 {
+		t.Errorf("NetworkDebounce = %d, want 5000", got)
+	}
+}
@@ -0,0 +1,268 @@
+// Package server provides settings import, export and preset handlers.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"slices"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/state"
+)
+
+// presetStore holds the user-global settings presets.
+var presetStore *state.PresetStore
+
+// registerSettingsTransferHandlers registers settings.export, settings.import
+// and the preset handlers. Import, export and applyPreset act on the
+// selected project.
+func registerSettingsTransferHandlers(s *Server) {
+	presetStore = state.NewPresetStore(state.UserPresetsPath())
+
+	s.RegisterHandler("settings.export", s.projectHandler(func(p *ProjectSession) Handler { return handleSettingsExport(p.Settings) }))
+	s.RegisterHandler("settings.import", s.projectHandler(func(p *ProjectSession) Handler { return handleSettingsImport(p.Settings) }))
+	s.RegisterHandler("settings.listPresets", handleSettingsListPresets(presetStore))
+	s.RegisterHandler("settings.savePreset", s.projectHandler(func(p *ProjectSession) Handler { return handleSettingsSavePreset(presetStore, p.Settings) }))
+	s.RegisterHandler("settings.deletePreset", handleSettingsDeletePreset(presetStore))
+	s.RegisterHandler("settings.applyPreset", s.projectHandler(func(p *ProjectSession) Handler { return handleSettingsApplyPreset(presetStore, p.Settings) }))
+}
+
+// SettingsExportParams are the parameters of settings.export.
+type SettingsExportParams struct {
+	Scope  string `json:"scope,omitempty"`  // Export one layer instead of the effective settings
+	Format string `json:"format,omitempty"` // "json" (default) or "yaml"
+}
+
+// SettingsExportResult is the result of settings.export.
+type SettingsExportResult struct {
+	Format state.DocumentFormat `json:"format"`
+	Data   string               `json:"data"`
+}
+
+// handleSettingsExport returns the shareable settings as a document.
+// Machine-specific settings (lastProjectPath, projectProfiles) are left out.
+// Method: settings.export
+// Params: none, or { "scope"?: "default"|"user"|"project"|"env", "format"?: "json"|"yaml" }
+// Result: SettingsExportResult
+func handleSettingsExport(sm *state.StateManager) Handler {
+	return func(params json.RawMessage) (interface{}, error) {
+		var p SettingsExportParams
+		if len(params) > 0 && string(params) != "null" {
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", err.Error())
+			}
+		}
+		format, err := state.ParseDocumentFormat(p.Format)
+		if err != nil {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid format", err.Error())
+		}
+		var scope state.Scope
+		if p.Scope != "" {
+			if scope, err = state.ParseScope(p.Scope); err != nil {
+				return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid scope", err.Error())
+			}
+		}
+
+		values, err := sm.Export(scope)
+		if err != nil {
+			return nil, NewErrorWithData(ErrCodeInternalError, "Failed to export settings", err.Error())
+		}
+		data, err := state.MarshalDocument(values, format)
+		if err != nil {
+			return nil, NewErrorWithData(ErrCodeInternalError, "Failed to export settings", err.Error())
+		}
+		return &SettingsExportResult{Format: format, Data: string(data)}, nil
+	}
+}
+
+// SettingsImportParams are the parameters of settings.import.
+type SettingsImportParams struct {
+	Data   string `json:"data"`             // Document from settings.export
+	Format string `json:"format,omitempty"` // "json" or "yaml"; detected if empty
+	Scope  string `json:"scope,omitempty"`  // "project" (default) or "user"
+	DryRun bool   `json:"dryRun,omitempty"` // Only report the changes
+}
+
+// handleSettingsImport applies a settings document to a layer.
+// Method: settings.import
+// Params: SettingsImportParams
+// Result: ApplyResult listing the effective settings that change
+func handleSettingsImport(sm *state.StateManager) Handler {
+	return func(params json.RawMessage) (interface{}, error) {
+		var p SettingsImportParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", err.Error())
+		}
+		if p.Data == "" {
+			return nil, NewError(ErrCodeInvalidParams, "data is required")
+		}
+		var format state.DocumentFormat
+		if p.Format != "" {
+			var err error
+			if format, err = state.ParseDocumentFormat(p.Format); err != nil {
+				return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid format", err.Error())
+			}
+		}
+		scope, err := state.ParseScope(p.Scope)
+		if err != nil {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid scope", err.Error())
+		}
+
+		values, err := state.ParseDocument([]byte(p.Data), format)
+		if err != nil {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid settings document", err.Error())
+		}
+		return applySettings(sm, scope, values, p.DryRun)
+	}
+}
+
+// handleSettingsListPresets lists the built-in and user presets.
+// Method: settings.listPresets
+// Params: none
+// Result: Preset[]
+func handleSettingsListPresets(ps *state.PresetStore) Handler {
+	return func(params json.RawMessage) (interface{}, error) {
+		presets, err := ps.List()
+		if err != nil {
+			return nil, NewErrorWithData(ErrCodeInternalError, "Failed to read presets", err.Error())
+		}
+		return presets, nil
+	}
+}
+
+// SettingsPresetParams are the parameters of the preset methods.
+type SettingsPresetParams struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"` // savePreset
+	Settings    map[string]interface{} `json:"settings,omitempty"`    // savePreset; defaults to the project layer's shareable values
+	Scope       string                 `json:"scope,omitempty"`       // applyPreset: "project" (default) or "user"
+	DryRun      bool                   `json:"dryRun,omitempty"`      // applyPreset: only report the changes
+}
+
+// handleSettingsSavePreset stores a user preset. Without settings, the
+// values set in the project are saved.
+// Method: settings.savePreset
+// Params: { "name": string, "description"?: string, "settings"?: object }
+// Result: Preset
+func handleSettingsSavePreset(ps *state.PresetStore, sm *state.StateManager) Handler {
+	return func(params json.RawMessage) (interface{}, error) {
+		p, err := presetParams(params)
+		if err != nil {
+			return nil, err
+		}
+		settings := p.Settings
+		if settings == nil {
+			if settings, err = sm.Export(state.ScopeProject); err != nil {
+				return nil, NewErrorWithData(ErrCodeInternalError, "Failed to read settings", err.Error())
+			}
+		}
+
+		if err := ps.Save(p.Name, p.Description, settings); err != nil {
+			return nil, presetError(err)
+		}
+		preset, err := ps.Get(p.Name)
+		if err != nil {
+			return nil, presetError(err)
+		}
+		return preset, nil
+	}
+}
+
+// handleSettingsDeletePreset removes a user preset.
+// Method: settings.deletePreset
+// Params: { "name": string }
+// Result: { "deleted": true }
+func handleSettingsDeletePreset(ps *state.PresetStore) Handler {
+	return func(params json.RawMessage) (interface{}, error) {
+		p, err := presetParams(params)
+		if err != nil {
+			return nil, err
+		}
+		if err := ps.Delete(p.Name); err != nil {
+			return nil, presetError(err)
+		}
+		return map[string]bool{"deleted": true}, nil
+	}
+}
+
+// handleSettingsApplyPreset sets a preset's settings in a layer.
+// Method: settings.applyPreset
+// Params: { "name": string, "scope"?: "project"|"user", "dryRun"?: boolean }
+// Result: ApplyResult listing the effective settings that change
+func handleSettingsApplyPreset(ps *state.PresetStore, sm *state.StateManager) Handler {
+	return func(params json.RawMessage) (interface{}, error) {
+		p, err := presetParams(params)
+		if err != nil {
+			return nil, err
+		}
+		scope, err := state.ParseScope(p.Scope)
+		if err != nil {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid scope", err.Error())
+		}
+		preset, err := ps.Get(p.Name)
+		if err != nil {
+			return nil, presetError(err)
+		}
+		return applySettings(sm, scope, preset.Settings, p.DryRun)
+	}
+}
+
+// presetParams parses preset parameters; name is required.
+func presetParams(params json.RawMessage) (*SettingsPresetParams, error) {
+	var p SettingsPresetParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", err.Error())
+	}
+	if p.Name == "" {
+		return nil, NewError(ErrCodeInvalidParams, "name is required")
+	}
+	return &p, nil
+}
+
+// applySettings applies imported or preset values and maps the errors.
+// Process-wide settings are skipped at project scope, where they would not
+// take effect.
+func applySettings(sm *state.StateManager, scope state.Scope, values map[string]interface{}, dryRun bool) (interface{}, error) {
+	if !scope.Writable() {
+		return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid scope", "scope "+string(scope)+" is read-only")
+	}
+	var skipped []string
+	if scope == state.ScopeProject {
+		values = maps.Clone(values)
+		for key := range values {
+			if slices.Contains(processSettings, key) {
+				delete(values, key)
+				skipped = append(skipped, key)
+			}
+		}
+	}
+	result, err := sm.Apply(scope, values, dryRun)
+	if err != nil {
+		var verr *state.ValidationError
+		if errors.As(err, &verr) {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid settings", verr.Errors)
+		}
+		return nil, NewErrorWithData(ErrCodeInternalError, "Failed to save settings", err.Error())
+	}
+	if len(skipped) > 0 {
+		result.Skipped = append(result.Skipped, skipped...)
+		slices.Sort(result.Skipped)
+		result.Skipped = slices.Compact(result.Skipped)
+	}
+	return result, nil
+}
+
+// presetError maps preset store errors to JSON-RPC errors.
+func presetError(err error) error {
+	var verr *state.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		return NewErrorWithData(ErrCodeInvalidParams, "Invalid settings", verr.Errors)
+	case errors.Is(err, state.ErrPresetNotFound):
+		return NewErrorWithData(ErrCodeInvalidParams, "Preset not found", err.Error())
+	case errors.Is(err, state.ErrInvalidPreset):
+		return NewErrorWithData(ErrCodeInvalidParams, "Invalid preset", err.Error())
+	default:
+		return NewErrorWithData(ErrCodeInternalError, "Failed to save presets", err.Error())
+	}
+}
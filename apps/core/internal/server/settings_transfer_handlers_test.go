@@ -0,0 +1,128 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/paths"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/state"
+)
+
+// TestSettingsExportImport verifies settings.export and settings.import with dry run
+func TestSettingsExportImport(t *testing.T) {
+	t.Setenv(paths.HomeEnv, t.TempDir())
+	source, target := t.TempDir(), t.TempDir()
+
+	srv := New(nil, nil, log.New(io.Discard, "", 0), source)
+	if err := RegisterSettingsHandlers(srv, source); err != nil {
+		t.Fatalf("RegisterSettingsHandlers failed: %v", err)
+	}
+	if _, _, err := srv.Workspace().Open(target); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer srv.Workspace().Shutdown()
+
+	if _, err := srv.handlers["settings.set"](json.RawMessage(`{"projectPath": "` + source + `", "maxRetries": 6, "stallAction": "kill"}`)); err != nil {
+		t.Fatalf("settings.set failed: %v", err)
+	}
+	if _, err := srv.handlers["settings.set"](json.RawMessage(`{"scope": "user", "lastProjectPath": "/home/me/repo"}`)); err != nil {
+		t.Fatalf("settings.set (user) failed: %v", err)
+	}
+
+	result, err := srv.handlers["settings.export"](json.RawMessage(`{"projectPath": "` + source + `", "scope": "project", "format": "yaml"}`))
+	if err != nil {
+		t.Fatalf("settings.export failed: %v", err)
+	}
+	export := result.(*SettingsExportResult)
+	if export.Format != state.DocumentYAML || !strings.Contains(export.Data, "stallAction: kill") {
+		t.Errorf("export = %+v", export)
+	}
+	result, err = srv.handlers["settings.export"](json.RawMessage(`{"scope": "user"}`))
+	if err != nil {
+		t.Fatalf("settings.export (user) failed: %v", err)
+	}
+	if data := result.(*SettingsExportResult).Data; strings.Contains(data, "lastProjectPath") {
+		t.Errorf("export contains machine-specific settings:\n%s", data)
+	}
+
+	params, _ := json.Marshal(map[string]interface{}{"projectPath": target, "data": export.Data, "dryRun": true})
+	result, err = srv.handlers["settings.import"](params)
+	if err != nil {
+		t.Fatalf("settings.import (dry run) failed: %v", err)
+	}
+	if r := result.(*state.ApplyResult); r.Applied || len(r.Changes) != 2 {
+		t.Errorf("dry run = %+v", r)
+	}
+
+	params, _ = json.Marshal(map[string]interface{}{"projectPath": target, "data": export.Data})
+	if _, err := srv.handlers["settings.import"](params); err != nil {
+		t.Fatalf("settings.import failed: %v", err)
+	}
+	sess, _ := srv.Workspace().Resolve(target)
+	if got := sess.Settings.Get(); got.MaxRetries != 6 || got.StallAction != "kill" {
+		t.Errorf("imported settings = %+v", got)
+	}
+
+	// Process-wide settings would not take effect in the project layer
+	params, _ = json.Marshal(map[string]interface{}{"projectPath": target, "data": `{"maxRetries": 4, "recentProjectsMax": 9}`})
+	result, err = srv.handlers["settings.import"](params)
+	if err != nil {
+		t.Fatalf("settings.import failed: %v", err)
+	}
+	if r := result.(*state.ApplyResult); len(r.Skipped) != 1 || r.Skipped[0] != "recentProjectsMax" || sess.Settings.Get().MaxRetries != 4 {
+		t.Errorf("import = %+v, want recentProjectsMax skipped", r)
+	}
+
+	_, err = srv.handlers["settings.import"](json.RawMessage(`{"data": "{\"maxRetries\": 99}"}`))
+	if rpcErr, ok := err.(*Error); !ok || rpcErr.Code != ErrCodeInvalidParams {
+		t.Errorf("import of invalid values err = %v, want ErrCodeInvalidParams", err)
+	}
+}
+
+// TestSettingsPresets verifies saving, listing, applying and deleting presets
+func TestSettingsPresets(t *testing.T) {
+	t.Setenv(paths.HomeEnv, t.TempDir())
+	tmpDir := t.TempDir()
+
+	srv := New(nil, nil, log.New(io.Discard, "", 0), tmpDir)
+	if err := RegisterSettingsHandlers(srv, tmpDir); err != nil {
+		t.Fatalf("RegisterSettingsHandlers failed: %v", err)
+	}
+
+	result, err := srv.handlers["settings.applyPreset"](json.RawMessage(`{"name": "overnight-unattended"}`))
+	if err != nil {
+		t.Fatalf("settings.applyPreset failed: %v", err)
+	}
+	sess, _ := srv.Workspace().Resolve("")
+	if r := result.(*state.ApplyResult); !r.Applied || sess.Settings.Get().StallAction != "retry" {
+		t.Errorf("applyPreset = %+v, settings %+v", r, sess.Settings.Get())
+	}
+
+	// Save the project's values as a preset
+	result, err = srv.handlers["settings.savePreset"](json.RawMessage(`{"name": "team", "description": "Team policy"}`))
+	if err != nil {
+		t.Fatalf("settings.savePreset failed: %v", err)
+	}
+	if p := result.(*state.Preset); p.Settings["stallAction"] != "retry" {
+		t.Errorf("saved preset = %+v", p)
+	}
+
+	result, err = srv.handlers["settings.listPresets"](nil)
+	if err != nil {
+		t.Fatalf("settings.listPresets failed: %v", err)
+	}
+	if presets := result.([]state.Preset); len(presets) != 3 {
+		t.Errorf("listPresets = %d presets, want 3", len(presets))
+	}
+
+	if _, err := srv.handlers["settings.deletePreset"](json.RawMessage(`{"name": "team"}`)); err != nil {
+		t.Fatalf("settings.deletePreset failed: %v", err)
+	}
+	_, err = srv.handlers["settings.applyPreset"](json.RawMessage(`{"name": "team"}`))
+	if rpcErr, ok := err.(*Error); !ok || rpcErr.Code != ErrCodeInvalidParams {
+		t.Errorf("applyPreset of deleted preset err = %v, want ErrCodeInvalidParams", err)
+	}
+}
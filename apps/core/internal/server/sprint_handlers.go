@@ -0,0 +1,72 @@
+// Package server provides sprint status handlers.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/sprint"
+)
+
+// SetStoryStatusParams are the parameters of sprint.setStoryStatus.
+type SetStoryStatusParams struct {
+	StoryKey string        `json:"storyKey"`
+	Status   sprint.Status `json:"status"`
+}
+
+// RegisterSprintHandlers registers sprint-related JSON-RPC handlers. They act
+// on the project selected by projectPath, or the active project.
+func RegisterSprintHandlers(s *Server, projectPath string) {
+	s.workspace.attach(projectPath, nil)
+
+	s.RegisterHandler("sprint.getStatus", s.projectHandler(func(p *ProjectSession) Handler { return handleSprintGetStatus(p.Path) }))
+	s.RegisterHandler("sprint.setStoryStatus", s.projectHandler(func(p *ProjectSession) Handler { return handleSprintSetStoryStatus(p.Path) }))
+}
+
+// handleSprintGetStatus returns the parsed sprint-status.yaml.
+// Method: sprint.getStatus
+// Params: none
+// Result: SprintStatus
+func handleSprintGetStatus(projectPath string) Handler {
+	return func(params json.RawMessage) (interface{}, error) {
+		status, err := sprint.Load(projectPath)
+		if err != nil {
+			return nil, sprintError("Failed to read sprint status", err)
+		}
+		return status, nil
+	}
+}
+
+// handleSprintSetStoryStatus moves a story to a new status.
+// Method: sprint.setStoryStatus
+// Params: { "storyKey": "1-2-user-login", "status": "in-progress" }
+// Result: SprintStatus (after the update)
+func handleSprintSetStoryStatus(projectPath string) Handler {
+	return func(params json.RawMessage) (interface{}, error) {
+		var p SetStoryStatusParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", err.Error())
+		}
+		if p.StoryKey == "" || p.Status == "" {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", "storyKey and status are required")
+		}
+
+		status, err := sprint.SetStoryStatus(projectPath, p.StoryKey, p.Status)
+		if err != nil {
+			return nil, sprintError("Failed to update story status", err)
+		}
+		return status, nil
+	}
+}
+
+// sprintError maps sprint errors to JSON-RPC errors.
+func sprintError(msg string, err error) error {
+	switch {
+	case errors.Is(err, sprint.ErrNotFound):
+		return NewErrorWithData(ErrCodeSprintNotFound, msg, err.Error())
+	case errors.Is(err, sprint.ErrUnknownStory), errors.Is(err, sprint.ErrInvalidTransition):
+		return NewErrorWithData(ErrCodeInvalidParams, msg, err.Error())
+	default:
+		return NewErrorWithData(ErrCodeInternalError, msg, err.Error())
+	}
+}
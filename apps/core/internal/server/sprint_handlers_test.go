@@ -0,0 +1,59 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/sprint"
+)
+
+func TestSprintHandlers(t *testing.T) {
+	dir := t.TempDir()
+	srv := New(nil, io.Discard, log.New(io.Discard, "", 0), dir)
+	RegisterSprintHandlers(srv, dir)
+
+	_, err := srv.handlers["sprint.getStatus"](nil)
+	if rpcErr, ok := err.(*Error); !ok || rpcErr.Code != ErrCodeSprintNotFound {
+		t.Fatalf("missing file: err = %v, want ErrCodeSprintNotFound", err)
+	}
+
+	path := filepath.Join(dir, filepath.FromSlash(sprint.StatusFile))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "development_status:\n  epic-1: in-progress\n  1-1-setup: in-progress # started\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := srv.handlers["sprint.getStatus"](nil)
+	if err != nil {
+		t.Fatalf("sprint.getStatus failed: %v", err)
+	}
+	if status := result.(*sprint.SprintStatus); status.Counts.Stories[sprint.StatusInProgress] != 1 {
+		t.Errorf("counts = %v", status.Counts.Stories)
+	}
+
+	params, _ := json.Marshal(SetStoryStatusParams{StoryKey: "1-1-setup", Status: sprint.StatusDone})
+	_, err = srv.handlers["sprint.setStoryStatus"](params)
+	if rpcErr, ok := err.(*Error); !ok || rpcErr.Code != ErrCodeInvalidParams {
+		t.Errorf("in-progress -> done: err = %v, want ErrCodeInvalidParams", err)
+	}
+
+	params, _ = json.Marshal(SetStoryStatusParams{StoryKey: "1-1-setup", Status: sprint.StatusReview})
+	result, err = srv.handlers["sprint.setStoryStatus"](params)
+	if err != nil {
+		t.Fatalf("sprint.setStoryStatus failed: %v", err)
+	}
+	if story, _ := result.(*sprint.SprintStatus).Story("1-1-setup"); story.Status != sprint.StatusReview {
+		t.Errorf("status = %s, want review", story.Status)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "development_status:\n  epic-1: in-progress\n  1-1-setup: review # started\n" {
+		t.Errorf("file = %q", data)
+	}
+}
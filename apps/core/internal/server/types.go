@@ -20,6 +20,8 @@ const (
 	ErrCodeOpenCodeNotFound = -32001
 	ErrCodeGitNotFound      = -32002
 	ErrCodeJourneyNotFound  = -32003
+	ErrCodeSprintNotFound   = -32004
+	ErrCodeProjectNotOpen   = -32005
 )
 
 // Request represents a JSON-RPC 2.0 request.
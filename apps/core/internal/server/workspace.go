@@ -0,0 +1,360 @@
+// Package server provides the multi-project workspace.
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/journey"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/state"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/watcher"
+)
+
+// ProjectSession is the per-project state of an opened project: its
+// settings, journey engine and artifact watcher.
+type ProjectSession struct {
+	Path     string
+	Settings *state.StateManager
+	Engine   *journey.Engine
+	OpenedAt time.Time
+
+	watcher *watcher.Watcher
+}
+
+// ProjectInfo describes an opened project.
+type ProjectInfo struct {
+	Path            string    `json:"path"`
+	Active          bool      `json:"active"`
+	OpenedAt        time.Time `json:"openedAt"`
+	RunningJourneys []string  `json:"runningJourneys"`
+	Watching        bool      `json:"watching"`
+}
+
+// Workspace keeps the projects opened in this process, keyed by their
+// resolved path. Project-scoped handlers act on the project named by their
+// optional projectPath parameter, or on the active project.
+type Workspace struct {
+	server *Server
+
+	mu          sync.Mutex
+	sessions    map[string]*ProjectSession
+	active      string
+	userWatcher *watcher.Watcher // User-global settings file
+}
+
+// newWorkspace creates an empty workspace for the server.
+func newWorkspace(s *Server) *Workspace {
+	return &Workspace{server: s, sessions: make(map[string]*ProjectSession)}
+}
+
+// projectKey resolves a project path to the key sessions are stored under.
+// It matches the path ValidateProjectPath returns for the same directory.
+func projectKey(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		return real
+	}
+	return filepath.Clean(path)
+}
+
+// attach gets or creates the session for path and lets fn set its parts.
+// The Register* functions use it to add the startup project piece by piece.
+// The first project attached becomes active.
+func (w *Workspace) attach(path string, fn func(*ProjectSession)) *ProjectSession {
+	key := projectKey(path)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	sess, ok := w.sessions[key]
+	if !ok {
+		sess = &ProjectSession{Path: path, OpenedAt: time.Now()}
+		w.sessions[key] = sess
+	}
+	if w.active == "" {
+		w.active = key
+	}
+	if fn != nil {
+		fn(sess)
+	}
+	return sess
+}
+
+// Open opens a project, or returns it if it is already open, and makes it
+// active. A new project gets its own settings, journey engine and watcher.
+// The bool result reports whether the project was already open.
+func (w *Workspace) Open(path string) (*ProjectSession, bool, error) {
+	validated, err := ValidateProjectPath(path)
+	if err != nil {
+		return nil, false, NewErrorWithData(ErrCodeInvalidParams, "Invalid path", err.Error())
+	}
+	key := projectKey(validated)
+
+	w.mu.Lock()
+	if sess, ok := w.sessions[key]; ok {
+		w.active = key
+		w.mu.Unlock()
+		return sess, true, nil
+	}
+	w.mu.Unlock()
+
+	sess, err := w.newSession(validated)
+	if err != nil {
+		return nil, false, NewErrorWithData(ErrCodeInternalError, "Failed to open project", err.Error())
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if existing, ok := w.sessions[key]; ok {
+		// Opened concurrently; keep the first session
+		if sess.watcher != nil {
+			sess.watcher.Stop()
+		}
+		w.active = key
+		return existing, true, nil
+	}
+	w.sessions[key] = sess
+	w.active = key
+	return sess, false, nil
+}
+
+// newSession creates the settings, engine and watcher of a project.
+func (w *Workspace) newSession(path string) (*ProjectSession, error) {
+	sm, err := state.NewStateManager(path)
+	if err != nil {
+		return nil, fmt.Errorf("creating state manager: %w", err)
+	}
+	logQuarantined(w.server, sm)
+	logFileErrors(w.server, sm)
+	notifySettingsChanges(w.server, path, sm)
+	engine, err := newJourneyEngine(w.server, path, sm)
+	if err != nil {
+		return nil, fmt.Errorf("creating journey engine: %w", err)
+	}
+	pw, err := newProjectWatcher(w.server, path)
+	if err != nil {
+		return nil, fmt.Errorf("starting project watcher: %w", err)
+	}
+	return &ProjectSession{Path: path, Settings: sm, Engine: engine, OpenedAt: time.Now(), watcher: pw}, nil
+}
+
+// Close stops a project's watcher and forgets it. Projects with running
+// journeys are only closed with force, which aborts the journeys first.
+// Closing the active project leaves no project active.
+func (w *Workspace) Close(path string, force bool) error {
+	key := projectKey(path)
+
+	w.mu.Lock()
+	sess, ok := w.sessions[key]
+	w.mu.Unlock()
+	if !ok {
+		return NewErrorWithData(ErrCodeProjectNotOpen, "Project not open", path)
+	}
+
+	if sess.Engine != nil {
+		running := sess.Engine.Running()
+		if len(running) > 0 && !force {
+			return NewErrorWithData(ErrCodeInvalidParams, "Project has running journeys", running)
+		}
+		for _, id := range running {
+			sess.Engine.Abort(id)
+		}
+		sess.Engine.Wait()
+	}
+
+	w.mu.Lock()
+	delete(w.sessions, key)
+	if w.active == key {
+		w.active = ""
+	}
+	pw := sess.watcher
+	sess.watcher = nil
+	w.mu.Unlock()
+
+	if pw != nil {
+		pw.Stop()
+	}
+	return nil
+}
+
+// lookup returns the session of an opened project, or nil.
+func (w *Workspace) lookup(path string) *ProjectSession {
+	key := projectKey(path)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.sessions[key]
+}
+
+// watchUserSettings reloads the settings of every opened project when the
+// user-global settings file at path changes. It does nothing if path is
+// empty or the file is already watched.
+func (w *Workspace) watchUserSettings(path string) error {
+	if path == "" {
+		return nil
+	}
+	w.mu.Lock()
+	watching := w.userWatcher != nil
+	w.mu.Unlock()
+	if watching {
+		return nil
+	}
+
+	dir := filepath.Dir(path)
+	_, statErr := os.Stat(dir)
+	uw := watcher.New([]string{dir}, watcher.Options{
+		Ignore: func(p string, isDir bool) bool {
+			return !isDir && p != path
+		},
+		// Notifications cannot watch a directory that does not exist yet
+		ForcePolling: statErr != nil,
+	}, func(watcher.Event) {
+		w.mu.Lock()
+		sessions := make([]*ProjectSession, 0, len(w.sessions))
+		for _, sess := range w.sessions {
+			sessions = append(sessions, sess)
+		}
+		w.mu.Unlock()
+		reloadUserSettings(w.server)
+		for _, sess := range sessions {
+			reloadSettings(w.server, sess)
+		}
+	})
+	if err := uw.Start(); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.userWatcher != nil {
+		uw.Stop()
+		return nil
+	}
+	w.userWatcher = uw
+	return nil
+}
+
+// Resolve returns the project named by path, or the active project if path
+// is empty.
+func (w *Workspace) Resolve(path string) (*ProjectSession, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	key := w.active
+	if path != "" {
+		validated, err := ValidateProjectPath(path)
+		if err != nil {
+			return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid path", err.Error())
+		}
+		key = projectKey(validated)
+	}
+	if key == "" {
+		return nil, NewError(ErrCodeProjectNotOpen, "No active project")
+	}
+	sess, ok := w.sessions[key]
+	if !ok {
+		return nil, NewErrorWithData(ErrCodeProjectNotOpen, "Project not open", path)
+	}
+	return sess, nil
+}
+
+// Projects lists the opened projects by path.
+func (w *Workspace) Projects() []ProjectInfo {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	infos := make([]ProjectInfo, 0, len(w.sessions))
+	for key, sess := range w.sessions {
+		infos = append(infos, w.infoLocked(key, sess))
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Path < infos[j].Path })
+	return infos
+}
+
+// Engines returns the journey engines of the opened projects.
+func (w *Workspace) Engines() []*journey.Engine {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	engines := make([]*journey.Engine, 0, len(w.sessions))
+	for _, sess := range w.sessions {
+		if sess.Engine != nil {
+			engines = append(engines, sess.Engine)
+		}
+	}
+	return engines
+}
+
+// Describe returns the current description of an opened project.
+func (w *Workspace) Describe(sess *ProjectSession) ProjectInfo {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.infoLocked(projectKey(sess.Path), sess)
+}
+
+// infoLocked describes a session. Caller must hold w.mu.
+func (w *Workspace) infoLocked(key string, sess *ProjectSession) ProjectInfo {
+	info := ProjectInfo{
+		Path:            sess.Path,
+		Active:          key == w.active,
+		OpenedAt:        sess.OpenedAt,
+		RunningJourneys: []string{},
+		Watching:        sess.watcher != nil,
+	}
+	if sess.Engine != nil {
+		info.RunningJourneys = sess.Engine.Running()
+	}
+	return info
+}
+
+// Shutdown stops the watchers of all projects and of the user settings.
+// Journeys are left to the process shutdown.
+func (w *Workspace) Shutdown() {
+	w.mu.Lock()
+	var watchers []*watcher.Watcher
+	if w.userWatcher != nil {
+		watchers = append(watchers, w.userWatcher)
+		w.userWatcher = nil
+	}
+	for _, sess := range w.sessions {
+		if sess.watcher != nil {
+			watchers = append(watchers, sess.watcher)
+			sess.watcher = nil
+		}
+	}
+	w.mu.Unlock()
+
+	for _, pw := range watchers {
+		pw.Stop()
+	}
+}
+
+// ProjectParams selects the project a project-scoped method acts on.
+// Handlers accept it alongside their own parameters.
+type ProjectParams struct {
+	ProjectPath string `json:"projectPath,omitempty"` // Defaults to the active project
+}
+
+// projectHandler wraps a handler built for one project so that it runs
+// against the project named by the projectPath parameter, or the active one.
+func (s *Server) projectHandler(build func(sess *ProjectSession) Handler) Handler {
+	return func(params json.RawMessage) (interface{}, error) {
+		var p ProjectParams
+		if trimmed := bytes.TrimSpace(params); len(trimmed) > 0 && trimmed[0] == '{' {
+			if err := json.Unmarshal(trimmed, &p); err != nil {
+				return nil, NewErrorWithData(ErrCodeInvalidParams, "Invalid params", err.Error())
+			}
+		}
+		sess, err := s.workspace.Resolve(p.ProjectPath)
+		if err != nil {
+			return nil, err
+		}
+		return build(sess)(params)
+	}
+}
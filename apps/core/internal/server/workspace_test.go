@@ -0,0 +1,110 @@
+package server
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/project"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/state"
+)
+
+func TestWorkspace_OpenScopeAndClose(t *testing.T) {
+	srv, first := newJourneyTestServer(t)
+	RegisterProjectHandlers(srv)
+	RegisterArtifactHandlers(srv, first)
+	defer srv.Workspace().Shutdown()
+
+	second := newJourneyTestProject(t)
+	planning := filepath.Join(second, "_bmad-output", "planning-artifacts")
+	if err := os.MkdirAll(planning, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(planning, "prd.md"), []byte("# PRD\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	params, _ := json.Marshal(ProjectOpenParams{Path: second})
+	result, err := srv.handlers["project.open"](params)
+	if err != nil {
+		t.Fatalf("project.open failed: %v", err)
+	}
+	opened := result.(ProjectOpenResult)
+	if !opened.Active || opened.AlreadyOpen || !opened.Watching {
+		t.Errorf("project.open = %+v", opened)
+	}
+
+	// Without projectPath the active (second) project is used
+	if _, err := srv.handlers["settings.set"](json.RawMessage(`{"maxRetries": 7}`)); err != nil {
+		t.Fatalf("settings.set failed: %v", err)
+	}
+	result, err = srv.handlers["artifact.list"](nil)
+	if err != nil {
+		t.Fatalf("artifact.list failed: %v", err)
+	}
+	if catalog := result.(*project.Catalog); len(catalog.Artifacts) != 1 {
+		t.Errorf("second project artifacts = %+v", catalog.Artifacts)
+	}
+
+	// With projectPath the named project is used
+	scoped, _ := json.Marshal(ProjectParams{ProjectPath: first})
+	result, err = srv.handlers["settings.get"](scoped)
+	if err != nil {
+		t.Fatalf("settings.get failed: %v", err)
+	}
+	if got := result.(*state.Settings).MaxRetries; got != 3 {
+		t.Errorf("first project maxRetries = %d, want default 3", got)
+	}
+	result, err = srv.handlers["artifact.list"](scoped)
+	if err != nil {
+		t.Fatalf("artifact.list failed: %v", err)
+	}
+	if catalog := result.(*project.Catalog); len(catalog.Artifacts) != 0 {
+		t.Errorf("first project artifacts = %+v", catalog.Artifacts)
+	}
+
+	result, _ = srv.handlers["project.listOpen"](nil)
+	if infos := result.([]ProjectInfo); len(infos) != 2 {
+		t.Errorf("project.listOpen = %+v", infos)
+	}
+
+	// Closing the active project leaves none active
+	params, _ = json.Marshal(ProjectCloseParams{Path: second})
+	if _, err := srv.handlers["project.close"](params); err != nil {
+		t.Fatalf("project.close failed: %v", err)
+	}
+	_, err = srv.handlers["settings.get"](nil)
+	if rpcErr, ok := err.(*Error); !ok || rpcErr.Code != ErrCodeProjectNotOpen {
+		t.Errorf("settings.get after close err = %v, want ErrCodeProjectNotOpen", err)
+	}
+	_, err = srv.handlers["journey.getState"](json.RawMessage(`{"journeyId": "j-1", "projectPath": "` + second + `"}`))
+	if rpcErr, ok := err.(*Error); !ok || rpcErr.Code != ErrCodeProjectNotOpen {
+		t.Errorf("journey.getState on closed project err = %v, want ErrCodeProjectNotOpen", err)
+	}
+	if _, err := srv.handlers["project.close"](params); err == nil {
+		t.Error("closing a closed project succeeded")
+	}
+
+	// Reopening the startup project makes it active again
+	params, _ = json.Marshal(ProjectOpenParams{Path: first})
+	result, err = srv.handlers["project.open"](params)
+	if err != nil {
+		t.Fatalf("project.open failed: %v", err)
+	}
+	if reopened := result.(ProjectOpenResult); !reopened.AlreadyOpen || !reopened.Active {
+		t.Errorf("project.open = %+v", reopened)
+	}
+}
+
+func TestWorkspace_OpenInvalidPath(t *testing.T) {
+	srv, _ := newJourneyTestServer(t)
+	RegisterProjectHandlers(srv)
+
+	for _, params := range []string{`{}`, `{"path": "relative/dir"}`, `{"path": "/does/not/exist"}`} {
+		_, err := srv.handlers["project.open"](json.RawMessage(params))
+		if rpcErr, ok := err.(*Error); !ok || rpcErr.Code != ErrCodeInvalidParams {
+			t.Errorf("project.open(%s) err = %v, want ErrCodeInvalidParams", params, err)
+		}
+	}
+}
@@ -0,0 +1,423 @@
+// Package sprint reads and updates BMAD's sprint tracking file,
+// _bmad-output/implementation-artifacts/sprint-status.yaml.
+package sprint
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/filelock"
+	"gopkg.in/yaml.v3"
+)
+
+// StatusFile is the sprint status location relative to the project root.
+const StatusFile = "_bmad-output/implementation-artifacts/sprint-status.yaml"
+
+var (
+	// ErrNotFound is returned when the project has no sprint status file.
+	ErrNotFound = errors.New("sprint status file not found")
+	// ErrUnknownStory is returned when a story key is not in the file.
+	ErrUnknownStory = errors.New("unknown story")
+	// ErrInvalidTransition is returned when a story cannot move to the requested status.
+	ErrInvalidTransition = errors.New("invalid status transition")
+)
+
+// Status is the state of an epic, story or retrospective.
+type Status string
+
+// Story statuses, in workflow order.
+const (
+	StatusBacklog     Status = "backlog"
+	StatusReadyForDev Status = "ready-for-dev"
+	StatusInProgress  Status = "in-progress"
+	StatusReview      Status = "review"
+	StatusDone        Status = "done"
+)
+
+// Retrospective statuses. Epics use backlog, in-progress and done.
+const (
+	StatusOptional Status = "optional"
+)
+
+// Legacy statuses written by older BMAD versions, mapped to current ones.
+var legacyStatuses = map[Status]Status{
+	"drafted":   StatusReadyForDev,
+	"contexted": StatusInProgress,
+	"completed": StatusDone,
+}
+
+// storyTransitions are the documented story moves: forward one step, or
+// back from review when changes are requested.
+var storyTransitions = map[Status][]Status{
+	StatusBacklog:     {StatusReadyForDev},
+	StatusReadyForDev: {StatusInProgress, StatusBacklog},
+	StatusInProgress:  {StatusReview, StatusReadyForDev},
+	StatusReview:      {StatusDone, StatusInProgress},
+	StatusDone:        {},
+}
+
+var (
+	epicKeyPattern  = regexp.MustCompile(`^epic-(\d+)$`)
+	retroKeyPattern = regexp.MustCompile(`^epic-(\d+)-retrospective$`)
+	storyKeyPattern = regexp.MustCompile(`^(\d+)-(\d+)-(.+)$`)
+)
+
+// Story is one story entry.
+type Story struct {
+	Key    string `json:"key"` // e.g. 1-2-account-management
+	Epic   int    `json:"epic"`
+	Number int    `json:"number"`
+	Title  string `json:"title"` // From the key slug
+	Status Status `json:"status"`
+}
+
+// Retrospective is an epic's retrospective entry.
+type Retrospective struct {
+	Key    string `json:"key"`
+	Status Status `json:"status"`
+}
+
+// Epic groups an epic's stories and retrospective.
+type Epic struct {
+	Key           string         `json:"key"`
+	Number        int            `json:"number"`
+	Status        Status         `json:"status"` // Empty if the file lists stories without their epic
+	Stories       []Story        `json:"stories"`
+	Retrospective *Retrospective `json:"retrospective,omitempty"`
+	StoryCounts   map[Status]int `json:"storyCounts"`
+}
+
+// Counts are the number of entries per status.
+type Counts struct {
+	Epics          map[Status]int `json:"epics"`
+	Stories        map[Status]int `json:"stories"`
+	Retrospectives map[Status]int `json:"retrospectives"`
+}
+
+// SprintStatus is the parsed sprint status file.
+type SprintStatus struct {
+	Path           string   `json:"path"`
+	Generated      string   `json:"generated,omitempty"`
+	Project        string   `json:"project,omitempty"`
+	ProjectKey     string   `json:"projectKey,omitempty"`
+	TrackingSystem string   `json:"trackingSystem,omitempty"`
+	StoryLocation  string   `json:"storyLocation,omitempty"`
+	Epics          []*Epic  `json:"epics"`
+	Counts         Counts   `json:"counts"`
+	Warnings       []string `json:"warnings,omitempty"` // Unrecognized keys or statuses
+}
+
+// Story returns the story with the given key.
+func (s *SprintStatus) Story(key string) (*Story, bool) {
+	for _, e := range s.Epics {
+		for i := range e.Stories {
+			if e.Stories[i].Key == key {
+				return &e.Stories[i], true
+			}
+		}
+	}
+	return nil, false
+}
+
+// Load reads the sprint status file of a project.
+func Load(projectPath string) (*SprintStatus, error) {
+	path := filepath.Join(projectPath, filepath.FromSlash(StatusFile))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, StatusFile)
+		}
+		return nil, fmt.Errorf("reading sprint status: %w", err)
+	}
+	status, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	status.Path = StatusFile
+	return status, nil
+}
+
+// rawStatus is the top-level layout of sprint-status.yaml.
+type rawStatus struct {
+	Generated         string    `yaml:"generated"`
+	Project           string    `yaml:"project"`
+	ProjectKey        string    `yaml:"project_key"`
+	TrackingSystem    string    `yaml:"tracking_system"`
+	StoryLocation     string    `yaml:"story_location"`
+	DevelopmentStatus yaml.Node `yaml:"development_status"`
+}
+
+// Parse parses the content of a sprint status file. Entries keep their
+// file order within each epic.
+func Parse(data []byte) (*SprintStatus, error) {
+	var raw rawStatus
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parsing sprint status: %w", err)
+	}
+
+	s := &SprintStatus{
+		Generated:      raw.Generated,
+		Project:        raw.Project,
+		ProjectKey:     raw.ProjectKey,
+		TrackingSystem: raw.TrackingSystem,
+		StoryLocation:  raw.StoryLocation,
+		Epics:          []*Epic{},
+		Counts: Counts{
+			Epics:          make(map[Status]int),
+			Stories:        make(map[Status]int),
+			Retrospectives: make(map[Status]int),
+		},
+	}
+
+	dev := &raw.DevelopmentStatus
+	if dev.Kind == 0 {
+		return s, nil
+	}
+	if dev.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("parsing sprint status: development_status must be a mapping")
+	}
+
+	epics := make(map[int]*Epic)
+	epicFor := func(n int) *Epic {
+		if e, ok := epics[n]; ok {
+			return e
+		}
+		e := &Epic{Key: fmt.Sprintf("epic-%d", n), Number: n, Stories: []Story{}, StoryCounts: make(map[Status]int)}
+		epics[n] = e
+		return e
+	}
+
+	for i := 0; i+1 < len(dev.Content); i += 2 {
+		key, value := dev.Content[i].Value, dev.Content[i+1].Value
+		status := normalize(Status(value))
+
+		switch {
+		case epicKeyPattern.MatchString(key):
+			n, _ := strconv.Atoi(epicKeyPattern.FindStringSubmatch(key)[1])
+			e := epicFor(n)
+			e.Status = status
+			s.Counts.Epics[status]++
+			s.checkStatus(key, status, StatusBacklog, StatusInProgress, StatusDone)
+		case retroKeyPattern.MatchString(key):
+			n, _ := strconv.Atoi(retroKeyPattern.FindStringSubmatch(key)[1])
+			epicFor(n).Retrospective = &Retrospective{Key: key, Status: status}
+			s.Counts.Retrospectives[status]++
+			s.checkStatus(key, status, StatusOptional, StatusDone)
+		case storyKeyPattern.MatchString(key):
+			m := storyKeyPattern.FindStringSubmatch(key)
+			epicNum, _ := strconv.Atoi(m[1])
+			storyNum, _ := strconv.Atoi(m[2])
+			e := epicFor(epicNum)
+			e.Stories = append(e.Stories, Story{
+				Key:    key,
+				Epic:   epicNum,
+				Number: storyNum,
+				Title:  titleFromSlug(m[3]),
+				Status: status,
+			})
+			e.StoryCounts[status]++
+			s.Counts.Stories[status]++
+			if _, ok := storyTransitions[status]; !ok {
+				s.Warnings = append(s.Warnings, fmt.Sprintf("%s: unknown story status %q", key, value))
+			}
+		default:
+			s.Warnings = append(s.Warnings, fmt.Sprintf("%s: unrecognized entry", key))
+		}
+	}
+
+	for _, e := range epics {
+		s.Epics = append(s.Epics, e)
+	}
+	sort.Slice(s.Epics, func(i, j int) bool { return s.Epics[i].Number < s.Epics[j].Number })
+	return s, nil
+}
+
+// checkStatus records a warning if status is not one of allowed.
+func (s *SprintStatus) checkStatus(key string, status Status, allowed ...Status) {
+	for _, a := range allowed {
+		if status == a {
+			return
+		}
+	}
+	s.Warnings = append(s.Warnings, fmt.Sprintf("%s: unknown status %q", key, status))
+}
+
+// normalize maps legacy statuses to current ones.
+func normalize(status Status) Status {
+	if current, ok := legacyStatuses[status]; ok {
+		return current
+	}
+	return status
+}
+
+// titleFromSlug turns "account-management" into "Account Management".
+func titleFromSlug(slug string) string {
+	words := strings.Split(slug, "-")
+	for i, w := range words {
+		if w != "" {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// ValidateTransition checks that a story may move from one status to another.
+func ValidateTransition(from, to Status) error {
+	from, to = normalize(from), normalize(to)
+	if _, ok := storyTransitions[to]; !ok {
+		return fmt.Errorf("%w: unknown status %q", ErrInvalidTransition, to)
+	}
+	allowed, ok := storyTransitions[from]
+	if !ok {
+		return fmt.Errorf("%w: current status %q is unknown", ErrInvalidTransition, from)
+	}
+	for _, a := range allowed {
+		if a == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s -> %s (allowed: %v)", ErrInvalidTransition, from, to, allowed)
+}
+
+// lockFile is the file whose lock serializes status updates, kept under
+// .autobmad/ so the lock file does not show up among the artifacts.
+const lockFile = "_bmad-output/.autobmad/sprint-status.yaml"
+
+// SetStoryStatus moves a story to a new status and rewrites the file. Only
+// the status value is replaced, so comments, formatting and the file mode
+// are kept. Concurrent updates, also from other processes, are serialized.
+func SetStoryStatus(projectPath, key string, to Status) (*SprintStatus, error) {
+	lock, err := filelock.Acquire(filepath.Join(projectPath, filepath.FromSlash(lockFile)))
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	path := filepath.Join(projectPath, filepath.FromSlash(StatusFile))
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, StatusFile)
+		}
+		return nil, fmt.Errorf("reading sprint status: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, StatusFile)
+		}
+		return nil, fmt.Errorf("reading sprint status: %w", err)
+	}
+
+	updated, err := setStatus(data, key, to)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := replaceFile(path, updated, info.Mode().Perm()); err != nil {
+		return nil, err
+	}
+	return Load(projectPath)
+}
+
+// replaceFile atomically replaces path with data through a temp file in the
+// same directory, giving it mode perm.
+func replaceFile(path string, data []byte, perm os.FileMode) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating temp file: %w", err)
+	}
+	defer os.Remove(temp.Name()) // No-op once renamed
+
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return fmt.Errorf("writing temp file: %w", err)
+	}
+	if err := temp.Chmod(perm); err != nil {
+		temp.Close()
+		return fmt.Errorf("setting file mode: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("writing temp file: %w", err)
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return fmt.Errorf("renaming temp file: %w", err)
+	}
+	return nil
+}
+
+// setStatus returns data with the story's status value replaced.
+func setStatus(data []byte, key string, to Status) ([]byte, error) {
+	if !storyKeyPattern.MatchString(key) {
+		return nil, fmt.Errorf("%w: %s is not a story key", ErrUnknownStory, key)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing sprint status: %w", err)
+	}
+	value := findStatusNode(&doc, key)
+	if value == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownStory, key)
+	}
+	if err := ValidateTransition(Status(value.Value), to); err != nil {
+		return nil, err
+	}
+
+	// Replace the value token in place using the node's position
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	if value.Line < 1 || value.Line > len(lines) {
+		return nil, fmt.Errorf("parsing sprint status: bad position for %s", key)
+	}
+	line := lines[value.Line-1]
+	start := value.Column - 1
+	end := start + len(rawToken(value))
+	if start < 0 || end > len(line) || string(line[start:end]) != rawToken(value) {
+		return nil, fmt.Errorf("cannot update %s: status value is not a simple scalar", key)
+	}
+	replaced := append(append(append([]byte{}, line[:start]...), to...), line[end:]...)
+	lines[value.Line-1] = replaced
+	return bytes.Join(lines, nil), nil
+}
+
+// findStatusNode returns the value node of key under development_status.
+func findStatusNode(doc *yaml.Node, key string) *yaml.Node {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "development_status" || root.Content[i+1].Kind != yaml.MappingNode {
+			continue
+		}
+		dev := root.Content[i+1]
+		for k := 0; k+1 < len(dev.Content); k += 2 {
+			if dev.Content[k].Value == key && dev.Content[k+1].Kind == yaml.ScalarNode {
+				return dev.Content[k+1]
+			}
+		}
+	}
+	return nil
+}
+
+// rawToken returns how a scalar appears in the source.
+func rawToken(n *yaml.Node) string {
+	switch n.Style {
+	case yaml.DoubleQuotedStyle:
+		return `"` + n.Value + `"`
+	case yaml.SingleQuotedStyle:
+		return "'" + n.Value + "'"
+	default:
+		return n.Value
+	}
+}
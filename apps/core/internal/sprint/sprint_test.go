@@ -0,0 +1,220 @@
+package sprint
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const sampleStatus = `# generated: 2026-01-10
+# STATUS DEFINITIONS:
+#   backlog -> ready-for-dev -> in-progress -> review -> done
+
+generated: 2026-01-10
+project: demo
+project_key: DEMO
+tracking_system: file-system
+story_location: "{project-root}/_bmad-output/implementation-artifacts"
+
+development_status:
+  epic-1: in-progress
+  1-1-project-setup: done
+  1-2-user-login: review   # waiting on QA
+  1-3-password-reset: "ready-for-dev"
+  epic-1-retrospective: optional
+
+  epic-2: backlog
+  2-1-dashboard: backlog
+  2-2-reports: drafted
+  epic-2-retrospective: optional
+`
+
+func writeStatus(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, filepath.FromSlash(StatusFile))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestParse(t *testing.T) {
+	s, err := Parse([]byte(sampleStatus))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if s.Project != "demo" || s.ProjectKey != "DEMO" || s.TrackingSystem != "file-system" {
+		t.Errorf("header = %+v", s)
+	}
+	if len(s.Epics) != 2 {
+		t.Fatalf("epics = %d, want 2", len(s.Epics))
+	}
+
+	e1 := s.Epics[0]
+	if e1.Number != 1 || e1.Status != StatusInProgress || len(e1.Stories) != 3 {
+		t.Errorf("epic 1 = %+v", e1)
+	}
+	if e1.Retrospective == nil || e1.Retrospective.Status != StatusOptional {
+		t.Errorf("epic 1 retrospective = %+v", e1.Retrospective)
+	}
+	if got := e1.Stories[1]; got.Key != "1-2-user-login" || got.Number != 2 || got.Title != "User Login" || got.Status != StatusReview {
+		t.Errorf("story 1-2 = %+v", got)
+	}
+	if e1.StoryCounts[StatusDone] != 1 || e1.StoryCounts[StatusReview] != 1 || e1.StoryCounts[StatusReadyForDev] != 1 {
+		t.Errorf("epic 1 counts = %v", e1.StoryCounts)
+	}
+
+	// Legacy "drafted" reads as ready-for-dev
+	if story, ok := s.Story("2-2-reports"); !ok || story.Status != StatusReadyForDev {
+		t.Errorf("story 2-2 = %+v", story)
+	}
+	if s.Counts.Stories[StatusBacklog] != 1 || s.Counts.Stories[StatusReadyForDev] != 2 {
+		t.Errorf("story counts = %v", s.Counts.Stories)
+	}
+	if s.Counts.Epics[StatusInProgress] != 1 || s.Counts.Retrospectives[StatusOptional] != 2 {
+		t.Errorf("counts = %+v", s.Counts)
+	}
+	if len(s.Warnings) != 0 {
+		t.Errorf("warnings = %v", s.Warnings)
+	}
+}
+
+func TestParse_Warnings(t *testing.T) {
+	s, err := Parse([]byte("development_status:\n  epic-1: started\n  1-1-a: blocked\n  notes: x\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(s.Warnings) != 3 {
+		t.Errorf("warnings = %v, want 3", s.Warnings)
+	}
+}
+
+func TestLoad_NotFound(t *testing.T) {
+	if _, err := Load(t.TempDir()); !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestValidateTransition(t *testing.T) {
+	tests := []struct {
+		from, to Status
+		ok       bool
+	}{
+		{StatusBacklog, StatusReadyForDev, true},
+		{StatusReadyForDev, StatusInProgress, true},
+		{StatusInProgress, StatusReview, true},
+		{StatusReview, StatusDone, true},
+		{StatusReview, StatusInProgress, true},
+		{"drafted", StatusInProgress, true},
+		{StatusBacklog, StatusDone, false},
+		{StatusDone, StatusInProgress, false},
+		{StatusReview, "shipped", false},
+	}
+	for _, tt := range tests {
+		err := ValidateTransition(tt.from, tt.to)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s -> %s: err = %v, want ok=%v", tt.from, tt.to, err, tt.ok)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("%s -> %s: err = %v, want ErrInvalidTransition", tt.from, tt.to, err)
+		}
+	}
+}
+
+func TestSetStoryStatus_PreservesComments(t *testing.T) {
+	dir := writeStatus(t, sampleStatus)
+
+	s, err := SetStoryStatus(dir, "1-2-user-login", StatusDone)
+	if err != nil {
+		t.Fatalf("SetStoryStatus failed: %v", err)
+	}
+	if story, _ := s.Story("1-2-user-login"); story.Status != StatusDone {
+		t.Errorf("status = %s, want done", story.Status)
+	}
+
+	// Quoted values are replaced whole
+	if _, err := SetStoryStatus(dir, "1-3-password-reset", StatusInProgress); err != nil {
+		t.Fatalf("SetStoryStatus failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(StatusFile)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(sampleStatus, "1-2-user-login: review   # waiting on QA", "1-2-user-login: done   # waiting on QA", 1)
+	want = strings.Replace(want, `1-3-password-reset: "ready-for-dev"`, "1-3-password-reset: in-progress", 1)
+	if string(data) != want {
+		t.Errorf("file =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestSetStoryStatus_ConcurrentKeepsModeAndUpdates(t *testing.T) {
+	dir := writeStatus(t, sampleStatus)
+	path := filepath.Join(dir, filepath.FromSlash(StatusFile))
+	if err := os.Chmod(path, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	updates := map[string]Status{
+		"1-2-user-login":     StatusDone,
+		"1-3-password-reset": StatusInProgress,
+		"2-1-dashboard":      StatusReadyForDev,
+	}
+	var wg sync.WaitGroup
+	for key, to := range updates {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := SetStoryStatus(dir, key, to); err != nil {
+				t.Errorf("SetStoryStatus(%s) failed: %v", key, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	s, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	for key, want := range updates {
+		if story, _ := s.Story(key); story == nil || story.Status != want {
+			t.Errorf("%s = %+v, want %s", key, story, want)
+		}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(filepath.Dir(path), "*.tmp")); len(leftovers) > 0 {
+		t.Errorf("temp files left behind: %v", leftovers)
+	}
+}
+
+func TestSetStoryStatus_Errors(t *testing.T) {
+	dir := writeStatus(t, sampleStatus)
+
+	if _, err := SetStoryStatus(dir, "1-1-project-setup", StatusInProgress); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("done -> in-progress: err = %v", err)
+	}
+	if _, err := SetStoryStatus(dir, "9-9-missing", StatusReview); !errors.Is(err, ErrUnknownStory) {
+		t.Errorf("missing story: err = %v", err)
+	}
+	if _, err := SetStoryStatus(dir, "epic-1", StatusDone); !errors.Is(err, ErrUnknownStory) {
+		t.Errorf("epic key: err = %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, filepath.FromSlash(StatusFile)))
+	if string(data) != sampleStatus {
+		t.Error("file changed after rejected updates")
+	}
+}
@@ -2,11 +2,14 @@
 package state
 
 // Settings represents user-configurable settings for Auto-BMAD.
-// All settings are persisted to _bmad-output/.autobmad/config.json.
+// Settings are persisted per project in _bmad-output/.autobmad/config.json,
+// layered over the user-global config.json in the config directory (see StateManager).
 type Settings struct {
 	// Retry settings
-	MaxRetries int `json:"maxRetries"` // Default: 3
-	RetryDelay int `json:"retryDelay"` // Default: 5000 (ms)
+	MaxRetries   int  `json:"maxRetries"`   // Default: 3
+	RetryDelay   int  `json:"retryDelay"`   // Default: 5000 (ms)
+	RetryBackoff bool `json:"retryBackoff"` // Default: false (double delay per retry)
+	RetryJitter  bool `json:"retryJitter"`  // Default: false (randomize delay 50-100%)
 
 	// Notification settings
 	DesktopNotifications bool `json:"desktopNotifications"` // Default: true
@@ -16,10 +19,33 @@ type Settings struct {
 	StepTimeoutDefault int `json:"stepTimeoutDefault"` // Default: 300000 (5 min)
 	HeartbeatInterval  int `json:"heartbeatInterval"`  // Default: 60000 (60s)
 
+	// Stall detection settings
+	StallIntervals int    `json:"stallIntervals"` // Default: 3 (heartbeats without progress)
+	StallAction    string `json:"stallAction"`    // Default: "warn" (warn, kill, retry)
+
+	// Input to running OpenCode processes
+	InputMode string `json:"inputMode"` // Default: "off" (off, pipe, pty)
+
+	// Yellow flag settings (moments where the AI needs a human decision)
+	YellowFlagDetection bool     `json:"yellowFlagDetection"` // Default: true
+	YellowFlagPatterns  []string `json:"yellowFlagPatterns"`  // Extra regex triggers, matched per output line
+
+	// Git checkpoints of the BMAD output
+	GitCheckpoints bool `json:"gitCheckpoints"` // Default: true (commit _bmad-output/ after each completed step)
+
 	// UI preferences
 	Theme           string `json:"theme"`           // Default: "system"
 	ShowDebugOutput bool   `json:"showDebugOutput"` // Default: false
 
+	// Per-workflow overrides of the timeout, retry and profile settings
+	WorkflowOverrides map[string]WorkflowOverride `json:"workflowOverrides"` // manifest workflow name -> override
+
+	// Network monitoring
+	NetworkProbes        map[string]NetworkProbe `json:"networkProbes"`        // probe name -> probe; empty uses the built-in probes
+	NetworkCheckInterval int                     `json:"networkCheckInterval"` // Default: 30000 (ms)
+	NetworkDebounce      int                     `json:"networkDebounce"`      // Default: 5000 (ms)
+	NetworkResumeDelay   int                     `json:"networkResumeDelay"`   // Default: 30000 (ms online before held journeys resume)
+
 	// Project memory
 	LastProjectPath   string            `json:"lastProjectPath,omitempty"`
 	ProjectProfiles   map[string]string `json:"projectProfiles"`   // path -> profile name
@@ -31,13 +57,33 @@ func DefaultSettings() *Settings {
 	return &Settings{
 		MaxRetries:           3,
 		RetryDelay:           5000,
+		RetryBackoff:         false,
+		RetryJitter:          false,
 		DesktopNotifications: true,
 		SoundEnabled:         false,
 		StepTimeoutDefault:   300000,
 		HeartbeatInterval:    60000,
+		StallIntervals:       3,
+		StallAction:          "warn",
+		InputMode:            "off",
+		YellowFlagDetection:  true,
+		YellowFlagPatterns:   []string{},
+		GitCheckpoints:       true,
 		Theme:                "system",
 		ShowDebugOutput:      false,
+		WorkflowOverrides:    make(map[string]WorkflowOverride),
+		NetworkProbes:        make(map[string]NetworkProbe),
+		NetworkCheckInterval: 30000,
+		NetworkDebounce:      5000,
+		NetworkResumeDelay:   30000,
 		ProjectProfiles:      make(map[string]string),
 		RecentProjectsMax:    10,
 	}
 }
+
+// NetworkProbe configures one reachability probe of the network monitor.
+type NetworkProbe struct {
+	Kind         string `json:"kind"`                   // dns, tcp, http or provider
+	Target       string `json:"target"`                 // Host, host:port or URL
+	ExpectStatus int    `json:"expectStatus,omitempty"` // http only; default 200
+}
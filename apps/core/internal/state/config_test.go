@@ -55,6 +55,12 @@ func TestDefaultSettings(t *testing.T) {
 	if settings.RecentProjectsMax != 10 {
 		t.Errorf("RecentProjectsMax = %d, want 10", settings.RecentProjectsMax)
 	}
+
+	// Verify network monitoring
+	if settings.NetworkCheckInterval != 30000 || settings.NetworkDebounce != 5000 || settings.NetworkResumeDelay != 30000 {
+		t.Errorf("network timing = (%d, %d, %d), want (30000, 5000, 30000)",
+			settings.NetworkCheckInterval, settings.NetworkDebounce, settings.NetworkResumeDelay)
+	}
 }
 
 // TestSettingsJSONMarshaling verifies settings can be marshaled to JSON
@@ -0,0 +1,284 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// historyVersion is bumped when HistoryRecord changes incompatibly; an index
+// with another version is rebuilt from the journey state files.
+const historyVersion = 1
+
+// Page size limits for history queries.
+const (
+	DefaultHistoryLimit = 20
+	MaxHistoryLimit     = 100
+)
+
+// HistoryRecord is the compact summary of a finished journey (FR7/FR42).
+type HistoryRecord struct {
+	ID             string     `json:"id"`
+	Destination    string     `json:"destination"`
+	Route          []string   `json:"route"`
+	Status         string     `json:"status"` // completed, failed or aborted
+	Profile        string     `json:"profile,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	StartedAt      *time.Time `json:"startedAt,omitempty"`
+	CompletedAt    time.Time  `json:"completedAt"`
+	DurationMs     int64      `json:"durationMs"`
+	StepsCompleted int        `json:"stepsCompleted"`
+	Retries        int        `json:"retries"`             // Attempts beyond the first, over all steps
+	Artifacts      []string   `json:"artifacts,omitempty"` // Relative to _bmad-output/
+	Error          string     `json:"error,omitempty"`
+}
+
+// SummarizeFunc builds the history record of a journey from its state file.
+// It returns nil for journeys that have not finished.
+type SummarizeFunc func(id string) (*HistoryRecord, error)
+
+// HistoryQuery filters history records. Zero values match everything.
+type HistoryQuery struct {
+	Text        string     `json:"text,omitempty"`        // Case-insensitive match on ID, route, profile, artifacts and error
+	Statuses    []string   `json:"statuses,omitempty"`    // Any of these statuses
+	Destination string     `json:"destination,omitempty"` // Exact destination workflow
+	From        *time.Time `json:"from,omitempty"`        // Completed at or after
+	To          *time.Time `json:"to,omitempty"`          // Completed at or before
+	Offset      int        `json:"offset,omitempty"`
+	Limit       int        `json:"limit,omitempty"`
+}
+
+// HistoryPage is one page of history records, newest first.
+type HistoryPage struct {
+	Records []HistoryRecord `json:"records"`
+	Total   int             `json:"total"` // Matching records over all pages
+	Offset  int             `json:"offset"`
+	Limit   int             `json:"limit"`
+}
+
+// historyIndex is the on-disk format of history.json.
+type historyIndex struct {
+	Version int             `json:"version"`
+	Records []HistoryRecord `json:"records"`
+}
+
+// History is an index of finished journeys stored in
+// _bmad-output/.autobmad/history.json. The journey state files stay the
+// source of truth: a missing, corrupt or outdated index is rebuilt from them.
+type History struct {
+	store     *Manager
+	summarize SummarizeFunc
+	path      string
+
+	mu      sync.Mutex
+	records map[string]HistoryRecord
+}
+
+// NewHistory creates a history index over the journeys of store.
+// The index is loaded lazily on first use.
+func NewHistory(store *Manager, summarize SummarizeFunc) *History {
+	return &History{
+		store:     store,
+		summarize: summarize,
+		path:      filepath.Join(store.Root(), "history.json"),
+	}
+}
+
+// Path returns the location of the index file.
+func (h *History) Path() string {
+	return h.path
+}
+
+// Record adds or replaces the record of a finished journey.
+func (h *History) Record(rec HistoryRecord) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.loadLocked(); err != nil {
+		return err
+	}
+	h.records[rec.ID] = rec
+	return h.saveLocked()
+}
+
+// Get returns the record of a journey.
+func (h *History) Get(id string) (*HistoryRecord, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.loadLocked(); err != nil {
+		return nil, err
+	}
+	rec, ok := h.records[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrJourneyNotFound, id)
+	}
+	return &rec, nil
+}
+
+// List returns a page of all records, newest first.
+func (h *History) List(offset, limit int) (*HistoryPage, error) {
+	return h.Search(HistoryQuery{Offset: offset, Limit: limit})
+}
+
+// Search returns a page of the records matching q, newest first.
+func (h *History) Search(q HistoryQuery) (*HistoryPage, error) {
+	if q.Offset < 0 {
+		return nil, fmt.Errorf("offset must not be negative")
+	}
+	if q.Limit < 0 || q.Limit > MaxHistoryLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d", MaxHistoryLimit)
+	}
+	if q.Limit == 0 {
+		q.Limit = DefaultHistoryLimit
+	}
+
+	h.mu.Lock()
+	if err := h.loadLocked(); err != nil {
+		h.mu.Unlock()
+		return nil, err
+	}
+	var matches []HistoryRecord
+	for _, rec := range h.records {
+		if q.matches(rec) {
+			matches = append(matches, rec)
+		}
+	}
+	h.mu.Unlock()
+
+	sort.Slice(matches, func(a, b int) bool {
+		if !matches[a].CompletedAt.Equal(matches[b].CompletedAt) {
+			return matches[a].CompletedAt.After(matches[b].CompletedAt)
+		}
+		return matches[a].ID > matches[b].ID
+	})
+
+	page := &HistoryPage{Records: []HistoryRecord{}, Total: len(matches), Offset: q.Offset, Limit: q.Limit}
+	if q.Offset < len(matches) {
+		end := min(q.Offset+q.Limit, len(matches))
+		page.Records = matches[q.Offset:end]
+	}
+	return page, nil
+}
+
+// matches reports whether rec passes every filter of q.
+func (q HistoryQuery) matches(rec HistoryRecord) bool {
+	if len(q.Statuses) > 0 && !containsFold(q.Statuses, rec.Status) {
+		return false
+	}
+	if q.Destination != "" && rec.Destination != q.Destination {
+		return false
+	}
+	if q.From != nil && rec.CompletedAt.Before(*q.From) {
+		return false
+	}
+	if q.To != nil && rec.CompletedAt.After(*q.To) {
+		return false
+	}
+	if text := strings.ToLower(strings.TrimSpace(q.Text)); text != "" {
+		fields := append([]string{rec.ID, rec.Profile, rec.Error}, rec.Route...)
+		fields = append(fields, rec.Artifacts...)
+		for _, f := range fields {
+			if strings.Contains(strings.ToLower(f), text) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Rebuild recreates the index from the journey state files and returns the
+// number of records. Unreadable journeys are skipped.
+func (h *History) Rebuild() (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.rebuildLocked(); err != nil {
+		return 0, err
+	}
+	return len(h.records), nil
+}
+
+// loadLocked reads the index once, rebuilding it when it cannot be used.
+// Caller must hold h.mu.
+func (h *History) loadLocked() error {
+	if h.records != nil {
+		return nil
+	}
+
+	data, err := os.ReadFile(h.path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("reading history index: %w", err)
+	}
+	var index historyIndex
+	if err != nil || json.Unmarshal(data, &index) != nil || index.Version != historyVersion {
+		return h.rebuildLocked()
+	}
+
+	h.records = make(map[string]HistoryRecord, len(index.Records))
+	for _, rec := range index.Records {
+		h.records[rec.ID] = rec
+	}
+	return nil
+}
+
+// rebuildLocked summarizes every journey and writes a fresh index.
+// Caller must hold h.mu.
+func (h *History) rebuildLocked() error {
+	ids, err := h.store.ListJourneyIDs()
+	if err != nil {
+		return err
+	}
+
+	records := make(map[string]HistoryRecord)
+	for _, id := range ids {
+		rec, err := h.summarize(id)
+		if err != nil || rec == nil {
+			continue
+		}
+		records[id] = *rec
+	}
+	h.records = records
+	return h.saveLocked()
+}
+
+// saveLocked writes the index atomically. Caller must hold h.mu.
+func (h *History) saveLocked() error {
+	index := historyIndex{Version: historyVersion, Records: make([]HistoryRecord, 0, len(h.records))}
+	for _, rec := range h.records {
+		index.Records = append(index.Records, rec)
+	}
+	sort.Slice(index.Records, func(a, b int) bool { return index.Records[a].ID < index.Records[b].ID })
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling history index: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return fmt.Errorf("creating history directory: %w", err)
+	}
+	tempPath := h.path + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0644); err != nil {
+		return fmt.Errorf("writing temp file: %w", err)
+	}
+	if err := os.Rename(tempPath, h.path); err != nil {
+		return fmt.Errorf("renaming temp file: %w", err)
+	}
+	return nil
+}
+
+// containsFold reports whether list contains s, ignoring case.
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+// historyFixture saves finished journeys as HistoryRecords and returns an
+// index that summarizes them straight from their state files.
+func historyFixture(t *testing.T, records ...HistoryRecord) (*Manager, *History) {
+	t.Helper()
+	m := NewManager(t.TempDir())
+	for _, rec := range records {
+		if err := m.SaveJourney(rec.ID, rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := NewHistory(m, func(id string) (*HistoryRecord, error) {
+		var rec HistoryRecord
+		if err := m.LoadJourney(id, &rec); err != nil {
+			return nil, err
+		}
+		if rec.Status == "running" {
+			return nil, nil
+		}
+		return &rec, nil
+	})
+	return m, h
+}
+
+func sampleRecords() []HistoryRecord {
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	return []HistoryRecord{
+		{ID: "j-1", Destination: "prd", Route: []string{"prd"}, Status: "completed", CompletedAt: base},
+		{ID: "j-2", Destination: "create-architecture", Route: []string{"prd", "create-architecture"}, Status: "failed", Error: "network unreachable", CompletedAt: base.Add(24 * time.Hour)},
+		{ID: "j-3", Destination: "prd", Route: []string{"prd"}, Status: "aborted", CompletedAt: base.Add(48 * time.Hour)},
+		{ID: "j-4", Destination: "prd", Route: []string{"prd"}, Status: "running"},
+	}
+}
+
+// TestHistory_RebuildsMissingIndex verifies the index is built from state files on first use
+func TestHistory_RebuildsMissingIndex(t *testing.T) {
+	_, h := historyFixture(t, sampleRecords()...)
+
+	page, err := h.List(0, 0)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if page.Total != 3 || page.Limit != DefaultHistoryLimit {
+		t.Fatalf("Total = %d, Limit = %d, want 3 finished journeys and default limit", page.Total, page.Limit)
+	}
+	if page.Records[0].ID != "j-3" || page.Records[2].ID != "j-1" {
+		t.Errorf("records not newest first: %s..%s", page.Records[0].ID, page.Records[2].ID)
+	}
+	if _, err := os.Stat(h.Path()); err != nil {
+		t.Errorf("index file not written: %v", err)
+	}
+}
+
+// TestHistory_RebuildsCorruptIndex verifies a corrupt index is replaced
+func TestHistory_RebuildsCorruptIndex(t *testing.T) {
+	m, h := historyFixture(t, sampleRecords()...)
+	if err := os.MkdirAll(m.Root(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(h.Path(), []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rec, err := h.Get("j-2")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if rec.Status != "failed" {
+		t.Errorf("Status = %q, want failed", rec.Status)
+	}
+
+	if _, err := h.Get("j-4"); !errors.Is(err, ErrJourneyNotFound) {
+		t.Errorf("unfinished journey err = %v, want ErrJourneyNotFound", err)
+	}
+}
+
+// TestHistory_RecordAndPaginate verifies new records are persisted and paged
+func TestHistory_RecordAndPaginate(t *testing.T) {
+	m, h := historyFixture(t, sampleRecords()...)
+	if err := h.Record(HistoryRecord{ID: "j-5", Status: "completed", CompletedAt: time.Now()}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	// A fresh index reads the file instead of rebuilding, so j-5 survives
+	reloaded := NewHistory(m, func(id string) (*HistoryRecord, error) { return nil, nil })
+	page, err := reloaded.List(1, 2)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if page.Total != 4 || len(page.Records) != 2 || page.Records[0].ID != "j-3" {
+		t.Errorf("page = %+v", page)
+	}
+
+	page, _ = reloaded.List(10, 2)
+	if len(page.Records) != 0 || page.Total != 4 {
+		t.Errorf("page past the end = %+v", page)
+	}
+
+	if _, err := reloaded.List(0, MaxHistoryLimit+1); err == nil {
+		t.Error("expected error for limit above maximum")
+	}
+}
+
+// TestHistory_Search verifies text, status, destination and date filters
+func TestHistory_Search(t *testing.T) {
+	_, h := historyFixture(t, sampleRecords()...)
+	from := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		query HistoryQuery
+		want  []string
+	}{
+		{"text matches error", HistoryQuery{Text: "NETWORK"}, []string{"j-2"}},
+		{"text matches route", HistoryQuery{Text: "architecture"}, []string{"j-2"}},
+		{"statuses", HistoryQuery{Statuses: []string{"completed", "aborted"}}, []string{"j-3", "j-1"}},
+		{"destination", HistoryQuery{Destination: "prd"}, []string{"j-3", "j-1"}},
+		{"date range", HistoryQuery{From: &from}, []string{"j-3", "j-2"}},
+		{"combined", HistoryQuery{Destination: "prd", From: &from}, []string{"j-3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := h.Search(tt.query)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			var got []string
+			for _, r := range page.Records {
+				got = append(got, r.ID)
+			}
+			if len(got) != len(tt.want) || page.Total != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
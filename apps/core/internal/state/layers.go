@@ -0,0 +1,230 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/paths"
+)
+
+// Scope names a settings layer. Layers are applied in the order
+// default, user, project, env; later layers win.
+type Scope string
+
+const (
+	ScopeDefault Scope = "default" // Built-in defaults
+	ScopeUser    Scope = "user"    // <config dir>/config.json, shared by all projects
+	ScopeProject Scope = "project" // <project>/_bmad-output/.autobmad/config.json
+	ScopeEnv     Scope = "env"     // AUTOBMAD_* environment variables
+)
+
+// layerOrder lists the stored layers from lowest to highest precedence.
+var layerOrder = []Scope{ScopeUser, ScopeProject, ScopeEnv}
+
+// EnvPrefix is the prefix of environment overrides, e.g. AUTOBMAD_MAX_RETRIES.
+const EnvPrefix = "AUTOBMAD_"
+
+// ParseScope validates a scope name. Empty means the project scope.
+func ParseScope(name string) (Scope, error) {
+	switch Scope(name) {
+	case "":
+		return ScopeProject, nil
+	case ScopeDefault, ScopeUser, ScopeProject, ScopeEnv:
+		return Scope(name), nil
+	default:
+		return "", fmt.Errorf("unknown scope %q (default, user, project or env)", name)
+	}
+}
+
+// Writable reports whether settings can be saved to the scope.
+func (s Scope) Writable() bool {
+	return s == ScopeUser || s == ScopeProject
+}
+
+// UserConfigPath returns the user-global settings file, config.json in the
+// config directory (see package paths), or "" if there is none.
+func UserConfigPath() string {
+	return paths.ConfigFile("config.json")
+}
+
+// EffectiveSettings are the resolved settings with the layer each value came from.
+type EffectiveSettings struct {
+	Settings    *Settings        `json:"settings"`
+	Sources     map[string]Scope `json:"sources"`               // Setting key -> layer
+	EnvErrors   []string         `json:"envErrors,omitempty"`   // Ignored invalid AUTOBMAD_* values
+	FileErrors  []string         `json:"fileErrors,omitempty"`  // Ignored invalid values in the layer files
+	Quarantined []Quarantine     `json:"quarantined,omitempty"` // Corrupt files moved aside at load
+}
+
+// settingKeys are the JSON keys of all settings, in field order.
+var settingKeys = func() []string {
+	var keys []string
+	t := reflect.TypeOf(Settings{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			keys = append(keys, name)
+		}
+	}
+	return keys
+}()
+
+// envName converts a setting key to its environment variable, e.g.
+// "maxRetries" to "AUTOBMAD_MAX_RETRIES".
+func envName(key string) string {
+	var b strings.Builder
+	b.WriteString(EnvPrefix)
+	for i, r := range key {
+		if unicode.IsUpper(r) && i > 0 {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+// envLayer reads AUTOBMAD_* overrides. Values of string settings are taken
+// as written; others are parsed as JSON (numbers, booleans, lists). Values
+// that fail validation are skipped and reported.
+func envLayer(lookup func(string) (string, bool)) (map[string]json.RawMessage, []string) {
+	layer := make(map[string]json.RawMessage)
+	var errs []string
+	for _, key := range settingKeys {
+		name := envName(key)
+		value, ok := lookup(name)
+		if !ok {
+			continue
+		}
+
+		var parsed interface{} = value
+		if field, _ := schemaField(key); field == nil || field.Type != TypeString {
+			if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+				parsed = value
+			}
+		}
+		if err := validateUpdates(map[string]interface{}{key: parsed}); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		layer[key], _ = json.Marshal(parsed)
+	}
+	return layer, errs
+}
+
+// validLayer drops the values of a layer file that fail validation, so an
+// out-of-range value such as a networkCheckInterval of 0 never reaches the
+// settings, and reports them. Keys that are not settings are kept.
+func validLayer(path string, layer map[string]json.RawMessage) (map[string]json.RawMessage, []string) {
+	valid := make(map[string]json.RawMessage, len(layer))
+	var errs []string
+	for key, raw := range layer {
+		field, ok := schemaField(key)
+		if !ok {
+			valid[key] = raw
+			continue
+		}
+		var value interface{}
+		err := json.Unmarshal(raw, &value)
+		if err == nil {
+			_, err = field.coerce(value)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s: %v", path, key, err))
+			continue
+		}
+		valid[key] = raw
+	}
+	sort.Strings(errs)
+	return valid, errs
+}
+
+// readLayer reads a layer file and its schema version. A missing file is an
+// empty layer at the current version. Unparsable files return a
+// *CorruptFileError.
+func readLayer(path string) (map[string]json.RawMessage, int, error) {
+	layer := make(map[string]json.RawMessage)
+	if path == "" {
+		return layer, CurrentSchemaVersion, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return layer, CurrentSchemaVersion, nil
+		}
+		return nil, 0, err
+	}
+	if err := json.Unmarshal(data, &layer); err != nil {
+		return nil, 0, &CorruptFileError{Path: path, Err: err}
+	}
+	if layer == nil {
+		layer = make(map[string]json.RawMessage) // The file held "null"
+	}
+
+	version := 1 // Written before schema versioning
+	if raw, ok := layer[schemaVersionKey]; ok {
+		if err := json.Unmarshal(raw, &version); err != nil || version < 1 {
+			return nil, 0, &CorruptFileError{Path: path, Err: fmt.Errorf("invalid %s %s", schemaVersionKey, raw)}
+		}
+		delete(layer, schemaVersionKey)
+	}
+	return layer, version, nil
+}
+
+// writeLayer saves a layer file at the current schema version atomically,
+// creating its directory.
+func writeLayer(path string, layer map[string]json.RawMessage) error {
+	file := make(map[string]json.RawMessage, len(layer)+1)
+	for k, v := range layer {
+		file[k] = v
+	}
+	file[schemaVersionKey] = json.RawMessage(fmt.Sprint(CurrentSchemaVersion))
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling settings: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+
+	// Atomic write: write to temp file, then rename
+	tempPath := path + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0644); err != nil {
+		return fmt.Errorf("writing temp file: %w", err)
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		return fmt.Errorf("renaming temp file: %w", err)
+	}
+	return nil
+}
+
+// applyLayer sets the layer's values on settings. Values of the wrong type
+// are skipped. It returns the keys that were applied.
+func applyLayer(settings *Settings, layer map[string]json.RawMessage) []string {
+	var applied []string
+	for _, key := range settingKeys {
+		raw, ok := layer[key]
+		if !ok {
+			continue
+		}
+		one, _ := json.Marshal(map[string]json.RawMessage{key: raw})
+		if err := json.Unmarshal(one, settings); err == nil {
+			applied = append(applied, key)
+		}
+	}
+	return applied
+}
+
+// settingFields marshals settings into one raw value per key.
+func settingFields(settings *Settings) map[string]json.RawMessage {
+	data, _ := json.Marshal(settings)
+	var fields map[string]json.RawMessage
+	json.Unmarshal(data, &fields)
+	return fields
+}
@@ -0,0 +1,211 @@
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newLayeredTestManager creates a manager with a user layer in a temp directory.
+func newLayeredTestManager(t *testing.T) (*StateManager, string, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	projectPath := filepath.Join(tmpDir, "project")
+	userPath := filepath.Join(tmpDir, "home", ".config", "auto-bmad", "config.json")
+	sm, err := NewLayeredStateManager(projectPath, userPath)
+	if err != nil {
+		t.Fatalf("NewLayeredStateManager() failed: %v", err)
+	}
+	return sm, projectPath, userPath
+}
+
+func TestEnvName(t *testing.T) {
+	tests := map[string]string{
+		"maxRetries":         "AUTOBMAD_MAX_RETRIES",
+		"theme":              "AUTOBMAD_THEME",
+		"stepTimeoutDefault": "AUTOBMAD_STEP_TIMEOUT_DEFAULT",
+	}
+	for key, want := range tests {
+		if got := envName(key); got != want {
+			t.Errorf("envName(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestLayeredSettings_Precedence(t *testing.T) {
+	sm, projectPath, userPath := newLayeredTestManager(t)
+
+	if err := sm.SetScope(ScopeUser, map[string]interface{}{"maxRetries": 5, "theme": "dark"}); err != nil {
+		t.Fatalf("SetScope(user) failed: %v", err)
+	}
+	if err := sm.SetScope(ScopeProject, map[string]interface{}{"maxRetries": 2}); err != nil {
+		t.Fatalf("SetScope(project) failed: %v", err)
+	}
+
+	eff := sm.Effective()
+	if eff.Settings.MaxRetries != 2 || eff.Settings.Theme != "dark" || eff.Settings.RetryDelay != 5000 {
+		t.Errorf("settings = %+v", eff.Settings)
+	}
+	want := map[string]Scope{"maxRetries": ScopeProject, "theme": ScopeUser, "retryDelay": ScopeDefault}
+	for key, scope := range want {
+		if eff.Sources[key] != scope {
+			t.Errorf("source of %s = %s, want %s", key, eff.Sources[key], scope)
+		}
+	}
+
+	// Layer files only hold their own values
+	var user map[string]interface{}
+	data, err := os.ReadFile(userPath)
+	if err != nil {
+		t.Fatalf("user config not written: %v", err)
+	}
+	json.Unmarshal(data, &user)
+	delete(user, "schemaVersion")
+	if len(user) != 2 {
+		t.Errorf("user layer = %v", user)
+	}
+
+	// A new project starts from the user layer
+	other, err := NewLayeredStateManager(filepath.Join(filepath.Dir(projectPath), "other"), userPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := other.Get(); got.MaxRetries != 5 || got.Theme != "dark" {
+		t.Errorf("other project settings = %+v", got)
+	}
+
+	// Resetting the project layer falls back to the user layer
+	if err := sm.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if got := sm.Get().MaxRetries; got != 5 {
+		t.Errorf("after reset maxRetries = %d, want 5", got)
+	}
+	values, err := sm.Layer(ScopeProject)
+	if err != nil || len(values) != 0 {
+		t.Errorf("project layer = %v, %v", values, err)
+	}
+}
+
+func TestLayeredSettings_Env(t *testing.T) {
+	t.Setenv("AUTOBMAD_MAX_RETRIES", "7")
+	t.Setenv("AUTOBMAD_THEME", "light")
+	t.Setenv("AUTOBMAD_SOUND_ENABLED", "true")
+	t.Setenv("AUTOBMAD_RETRY_DELAY", "999999")      // Out of range
+	t.Setenv("AUTOBMAD_RETRY_BACKOFF", "sometimes") // Wrong type
+
+	sm, _, _ := newLayeredTestManager(t)
+	if err := sm.Set(map[string]interface{}{"maxRetries": 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	eff := sm.Effective()
+	if eff.Settings.MaxRetries != 7 || eff.Settings.Theme != "light" || !eff.Settings.SoundEnabled {
+		t.Errorf("settings = %+v", eff.Settings)
+	}
+	if eff.Sources["maxRetries"] != ScopeEnv || eff.Sources["retryDelay"] != ScopeDefault {
+		t.Errorf("sources = %v", eff.Sources)
+	}
+	if len(eff.EnvErrors) != 2 {
+		t.Errorf("env errors = %v, want 2", eff.EnvErrors)
+	}
+
+	// The project value is kept underneath the override
+	values, _ := sm.Layer(ScopeProject)
+	if values["maxRetries"] != float64(1) {
+		t.Errorf("project layer = %v", values)
+	}
+}
+
+func TestLayeredSettings_ReadOnlyScopes(t *testing.T) {
+	sm, err := NewLayeredStateManager(filepath.Join(t.TempDir(), "project"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, scope := range []Scope{ScopeDefault, ScopeEnv, ScopeUser} {
+		if err := sm.SetScope(scope, map[string]interface{}{"maxRetries": 1}); err == nil {
+			t.Errorf("SetScope(%s) succeeded", scope)
+		}
+	}
+	if _, err := ParseScope("global"); err == nil {
+		t.Error("ParseScope accepted an unknown scope")
+	}
+}
+
+func TestLayeredSettings_InvalidFileValues(t *testing.T) {
+	sm, projectPath, userPath := newLayeredTestManager(t)
+	os.MkdirAll(filepath.Dir(userPath), 0755)
+	if err := os.WriteFile(userPath, []byte(`{"networkCheckInterval": 0, "theme": "dark"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(sm.configPath, []byte(`{"maxRetries": "many", "futureSetting": true}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both at startup and on reload
+	loaded, err := NewLayeredStateManager(projectPath, userPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sm.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	defaults := DefaultSettings()
+	for _, m := range []*StateManager{loaded, sm} {
+		eff := m.Effective()
+		if eff.Settings.NetworkCheckInterval != defaults.NetworkCheckInterval || eff.Settings.Theme != "dark" {
+			t.Errorf("settings = %+v", eff.Settings)
+		}
+		if eff.Sources["networkCheckInterval"] != ScopeDefault || eff.Sources["maxRetries"] != ScopeDefault {
+			t.Errorf("sources = %v", eff.Sources)
+		}
+		if len(eff.FileErrors) != 2 || !strings.Contains(eff.FileErrors[0], "networkCheckInterval") {
+			t.Errorf("file errors = %v", eff.FileErrors)
+		}
+	}
+
+	// A write drops the invalid value and keeps unknown keys
+	if err := sm.Set(map[string]interface{}{"theme": "light"}); err != nil {
+		t.Fatal(err)
+	}
+	values, _ := sm.Layer(ScopeProject)
+	if _, ok := values["maxRetries"]; ok || values["futureSetting"] != true {
+		t.Errorf("project layer = %v", values)
+	}
+	if errs := sm.FileErrors(); len(errs) != 1 {
+		t.Errorf("FileErrors() = %v, want the user layer's only", errs)
+	}
+}
+
+func TestUserStateManager(t *testing.T) {
+	project, projectPath, userPath := newLayeredTestManager(t)
+	if err := project.SetScope(ScopeProject, map[string]interface{}{"recentProjectsMax": 5}); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := NewUserStateManager(userPath)
+	if err != nil {
+		t.Fatalf("NewUserStateManager() failed: %v", err)
+	}
+	// Project values do not reach the process-wide settings
+	if got := user.Get().RecentProjectsMax; got != DefaultSettings().RecentProjectsMax {
+		t.Errorf("recentProjectsMax = %d, want the default", got)
+	}
+	if err := user.Set(map[string]interface{}{"theme": "dark"}); err == nil {
+		t.Error("Set() without a project layer succeeded")
+	}
+
+	if err := user.SetScope(ScopeUser, map[string]interface{}{"recentProjectsMax": 20}); err != nil {
+		t.Fatalf("SetScope(user) failed: %v", err)
+	}
+	reread, err := NewLayeredStateManager(projectPath, userPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, _ := reread.Layer(ScopeUser)
+	if values["recentProjectsMax"] != float64(20) || reread.Get().RecentProjectsMax != 5 {
+		t.Errorf("user layer = %v, effective %d", values, reread.Get().RecentProjectsMax)
+	}
+}
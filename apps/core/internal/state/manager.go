@@ -2,25 +2,56 @@ package state
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 	"sync"
+	"time"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/filelock"
 )
 
 // StateManager handles settings persistence and retrieval.
-// It provides thread-safe access to user settings stored in config.json.
+// It provides thread-safe access to user settings, resolved from layers:
+// built-in defaults, the user-global config.json, the project config.json
+// and AUTOBMAD_* environment variables, in increasing precedence. Layer files
+// hold only the settings set in that layer.
+//
+// Writes hold an advisory lock on the layer file and first pick up changes
+// made by other processes, so concurrent instances do not lose each other's
+// updates. Reload applies external edits. Values in the layer files that
+// fail schema validation are ignored and reported (see FileErrors); the next
+// write of that layer drops them from the file.
 type StateManager struct {
-	settings   *Settings
-	configPath string
-	mu         sync.RWMutex
+	// OnChange, if set, is called after the effective settings change,
+	// whether by Set, Reset or Reload.
+	OnChange func(Change)
+
+	settings    *Settings // Effective settings
+	sources     map[string]Scope
+	envErrors   []string
+	fileErrors  map[Scope][]string // Invalid values dropped from each layer file
+	quarantined []Quarantine
+	layers      map[Scope]map[string]json.RawMessage
+	stamps      map[Scope]fileStamp // Layer files as last read or written
+	configPath  string              // Project layer; empty if there is none
+	userPath    string              // User layer; empty if disabled
+	mu          sync.RWMutex
 }
 
 // NewStateManager creates a new StateManager instance.
 // It creates the config directory if it doesn't exist and loads existing settings.
-// If no settings file exists, default settings are used.
+// If no settings file exists, default settings are used. Older files are
+// migrated and corrupt ones quarantined. The user-global layer is read from
+// UserConfigPath.
 func NewStateManager(projectPath string) (*StateManager, error) {
+	return NewLayeredStateManager(projectPath, UserConfigPath())
+}
+
+// NewLayeredStateManager creates a StateManager with the user-global layer
+// at userPath. An empty userPath disables the user layer.
+func NewLayeredStateManager(projectPath, userPath string) (*StateManager, error) {
 	configDir := filepath.Join(projectPath, "_bmad-output", ".autobmad")
 	if err := os.MkdirAll(configDir, 0755); err != nil {
 		return nil, fmt.Errorf("creating config directory: %w", err)
@@ -28,54 +59,163 @@ func NewStateManager(projectPath string) (*StateManager, error) {
 
 	sm := &StateManager{
 		configPath: filepath.Join(configDir, "config.json"),
-		settings:   DefaultSettings(),
+		userPath:   userPath,
+		stamps:     make(map[Scope]fileStamp),
+		fileErrors: make(map[Scope][]string),
 	}
 
-	// Load existing settings (ignore if file doesn't exist)
-	if err := sm.load(); err != nil && !os.IsNotExist(err) {
+	if err := sm.load(); err != nil {
 		return nil, fmt.Errorf("loading settings: %w", err)
 	}
 
 	return sm, nil
 }
 
-// load reads settings from the config file.
-// If the file doesn't exist, default settings are kept.
+// NewUserStateManager creates a StateManager without a project layer: the
+// settings of the user-global file at userPath and the environment, shared
+// by every project of the process. Its user layer is written with
+// SetScope(ScopeUser, ...).
+func NewUserStateManager(userPath string) (*StateManager, error) {
+	sm := &StateManager{
+		userPath:   userPath,
+		stamps:     make(map[Scope]fileStamp),
+		fileErrors: make(map[Scope][]string),
+	}
+	if err := sm.load(); err != nil {
+		return nil, fmt.Errorf("loading user settings: %w", err)
+	}
+	return sm, nil
+}
+
+// load reads the layer files and the environment and resolves the settings.
+// Missing files are empty layers.
 func (sm *StateManager) load() error {
-	data, err := os.ReadFile(sm.configPath)
+	user, err := sm.loadLayer(ScopeUser, sm.userPath)
 	if err != nil {
 		return err
 	}
-
-	// Start with defaults to ensure all fields have values
-	settings := DefaultSettings()
-	if err := json.Unmarshal(data, settings); err != nil {
+	project, err := sm.loadLayer(ScopeProject, sm.configPath)
+	if err != nil {
 		return err
 	}
+	env, envErrors := envLayer(os.LookupEnv)
 
-	sm.settings = settings
+	sm.layers = map[Scope]map[string]json.RawMessage{ScopeEnv: env}
+	sm.setLayerLocked(ScopeUser, sm.userPath, user)
+	sm.setLayerLocked(ScopeProject, sm.configPath, project)
+	sm.envErrors = envErrors
+	sm.resolveLocked()
 	return nil
 }
 
-// save writes settings to the config file using atomic write.
-// It writes to a temporary file first, then renames it to ensure atomicity.
-func (sm *StateManager) save() error {
-	data, err := json.MarshalIndent(sm.settings, "", "  ")
-	if err != nil {
-		return fmt.Errorf("marshaling settings: %w", err)
+// loadLayer reads one layer file at startup and migrates it to the current
+// schema version, saving the result. A corrupt file is moved aside (see
+// Quarantine) and the layer starts empty, so a bad file never prevents
+// startup.
+func (sm *StateManager) loadLayer(scope Scope, path string) (map[string]json.RawMessage, error) {
+	layer, err := readAndMigrate(path)
+	if path != "" {
+		sm.stamps[scope] = stampFile(path)
+	}
+	var corrupt *CorruptFileError
+	if !errors.As(err, &corrupt) {
+		return layer, err
 	}
 
-	// Atomic write: write to temp file, then rename
-	tempPath := sm.configPath + ".tmp"
-	if err := os.WriteFile(tempPath, data, 0644); err != nil {
-		return fmt.Errorf("writing temp file: %w", err)
+	now := time.Now()
+	movedTo, qerr := quarantineFile(path, now)
+	if qerr != nil {
+		return nil, fmt.Errorf("%w (quarantine failed: %v)", err, qerr)
 	}
+	sm.stamps[scope] = fileStamp{}
+	sm.quarantined = append(sm.quarantined, Quarantine{
+		Scope:   scope,
+		Path:    path,
+		MovedTo: movedTo,
+		Error:   corrupt.Err.Error(),
+		At:      now,
+	})
+	return make(map[string]json.RawMessage), nil
+}
 
-	if err := os.Rename(tempPath, sm.configPath); err != nil {
-		return fmt.Errorf("renaming temp file: %w", err)
+// readAndMigrate reads a layer file and migrates it to the current schema
+// version, saving the result. Unparsable files return a *CorruptFileError.
+func readAndMigrate(path string) (map[string]json.RawMessage, error) {
+	layer, version, err := readLayer(path)
+	if err != nil {
+		return nil, err
+	}
+	migrated, err := migrateLayer(layer, version)
+	if err != nil {
+		return nil, &CorruptFileError{Path: path, Err: err}
+	}
+	if migrated {
+		if err := writeLayer(path, layer); err != nil {
+			return nil, fmt.Errorf("saving migrated settings: %w", err)
+		}
 	}
+	return layer, nil
+}
 
-	return nil
+// setLayerLocked replaces a file layer with its valid values and records the
+// invalid ones. Caller must hold sm.mu (or own sm exclusively); settings are
+// not re-resolved.
+func (sm *StateManager) setLayerLocked(scope Scope, path string, layer map[string]json.RawMessage) {
+	sm.layers[scope], sm.fileErrors[scope] = validLayer(path, layer)
+}
+
+// FileErrors lists the invalid values dropped from the user and project
+// layer files when they were last read.
+func (sm *StateManager) FileErrors() []string {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return append(append([]string(nil), sm.fileErrors[ScopeUser]...), sm.fileErrors[ScopeProject]...)
+}
+
+// Quarantined lists the corrupt settings files moved aside at load.
+func (sm *StateManager) Quarantined() []Quarantine {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return append([]Quarantine(nil), sm.quarantined...)
+}
+
+// resolveLocked recomputes the effective settings from the layers.
+// Caller must hold sm.mu (or own sm exclusively).
+func (sm *StateManager) resolveLocked() {
+	sm.settings, sm.sources = resolveLayers(sm.layers)
+}
+
+// resolveLayers applies the layers over the defaults in precedence order.
+func resolveLayers(layers map[Scope]map[string]json.RawMessage) (*Settings, map[string]Scope) {
+	settings := DefaultSettings()
+	sources := make(map[string]Scope, len(settingKeys))
+	for _, key := range settingKeys {
+		sources[key] = ScopeDefault
+	}
+	for _, scope := range layerOrder {
+		for _, key := range applyLayer(settings, layers[scope]) {
+			sources[key] = scope
+		}
+	}
+	return settings, sources
+}
+
+// layerPath returns the file of a writable layer.
+func (sm *StateManager) layerPath(scope Scope) (string, error) {
+	switch scope {
+	case ScopeProject:
+		if sm.configPath == "" {
+			return "", fmt.Errorf("project settings are unavailable: no project")
+		}
+		return sm.configPath, nil
+	case ScopeUser:
+		if sm.userPath == "" {
+			return "", fmt.Errorf("user settings are unavailable: no home directory")
+		}
+		return sm.userPath, nil
+	default:
+		return "", fmt.Errorf("scope %q is read-only", scope)
+	}
 }
 
 // Get returns a copy of the current settings.
@@ -91,148 +231,186 @@ func (sm *StateManager) Get() *Settings {
 	for k, v := range sm.settings.ProjectProfiles {
 		settingsCopy.ProjectProfiles[k] = v
 	}
+	settingsCopy.YellowFlagPatterns = append([]string{}, sm.settings.YellowFlagPatterns...)
+	settingsCopy.WorkflowOverrides = make(map[string]WorkflowOverride, len(sm.settings.WorkflowOverrides))
+	for k, v := range sm.settings.WorkflowOverrides {
+		settingsCopy.WorkflowOverrides[k] = v.clone()
+	}
+	settingsCopy.NetworkProbes = make(map[string]NetworkProbe, len(sm.settings.NetworkProbes))
+	for k, v := range sm.settings.NetworkProbes {
+		settingsCopy.NetworkProbes[k] = v
+	}
 	return &settingsCopy
 }
 
-// Set updates settings with the provided values and saves to disk.
-// Only recognized fields are updated; unknown fields are ignored.
-// Input validation is performed to prevent invalid or malicious values.
+// Effective returns the settings together with the layer each value came from.
+func (sm *StateManager) Effective() *EffectiveSettings {
+	settings := sm.Get()
+
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	sources := make(map[string]Scope, len(sm.sources))
+	for k, v := range sm.sources {
+		sources[k] = v
+	}
+	return &EffectiveSettings{
+		Settings:    settings,
+		Sources:     sources,
+		EnvErrors:   append([]string(nil), sm.envErrors...),
+		FileErrors:  append(append([]string(nil), sm.fileErrors[ScopeUser]...), sm.fileErrors[ScopeProject]...),
+		Quarantined: append([]Quarantine(nil), sm.quarantined...),
+	}
+}
+
+// Layer returns the values set in one layer. The default layer holds every setting.
+func (sm *StateManager) Layer(scope Scope) (map[string]interface{}, error) {
+	var raw map[string]json.RawMessage
+	if scope == ScopeDefault {
+		raw = settingFields(DefaultSettings())
+	} else {
+		sm.mu.RLock()
+		layer, ok := sm.layers[scope]
+		if !ok {
+			sm.mu.RUnlock()
+			return nil, fmt.Errorf("unknown scope %q", scope)
+		}
+		raw = make(map[string]json.RawMessage, len(layer))
+		for k, v := range layer {
+			raw[k] = v
+		}
+		sm.mu.RUnlock()
+	}
+
+	values := make(map[string]interface{}, len(raw))
+	for k, v := range raw {
+		var value interface{}
+		if err := json.Unmarshal(v, &value); err == nil {
+			values[k] = value
+		}
+	}
+	return values, nil
+}
+
+// Set updates project settings with the provided values and saves to disk.
+// Values are validated against the settings schema (see Schema); unknown
+// keys and wrong types are rejected with a *ValidationError.
 // Validation is atomic: all fields are validated before any changes are applied.
 func (sm *StateManager) Set(updates map[string]interface{}) error {
-	sm.mu.Lock()
-	defer sm.mu.Unlock()
+	return sm.SetScope(ScopeProject, updates)
+}
+
+// SetScope updates the settings of one writable layer (user or project) and
+// saves its file. The effective settings still follow layer precedence.
+func (sm *StateManager) SetScope(scope Scope, updates map[string]interface{}) error {
+	path, err := sm.layerPath(scope)
+	if err != nil {
+		return err
+	}
 
 	// PHASE 1: Validate ALL fields first (atomic validation)
+	if err := validateUpdates(updates); err != nil {
+		return err
+	}
+
+	lock, err := filelock.Acquire(path)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	sm.mu.Lock()
+	change, err := sm.setLocked(scope, path, updates)
+	sm.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	sm.notify(change)
+	return nil
+}
+
+// setLocked applies validated updates to a layer, starting from its current
+// file content. Caller must hold sm.mu and the layer's file lock.
+func (sm *StateManager) setLocked(scope Scope, path string, updates map[string]interface{}) (Change, error) {
+	before := settingFields(sm.settings)
+	if refreshed, err := sm.refreshLocked(scope); err != nil {
+		return Change{}, err
+	} else if refreshed {
+		sm.resolveLocked()
+	}
+
+	// PHASE 2: Apply updates on the layer's own values (only after all validations pass)
+	layer, err := sm.updatedLayerLocked(scope, updates)
+	if err != nil {
+		return Change{}, err
+	}
+
+	if err := writeLayer(path, layer); err != nil {
+		return Change{}, err
+	}
+	sm.setLayerLocked(scope, path, layer)
+	sm.stamps[scope] = stampFile(path)
+	sm.resolveLocked()
+	return Change{Keys: sm.changedKeysLocked(before)}, nil
+}
+
+// updatedLayerLocked returns a copy of a layer with validated updates
+// applied. Caller must hold sm.mu.
+func (sm *StateManager) updatedLayerLocked(scope Scope, updates map[string]interface{}) (map[string]json.RawMessage, error) {
+	scratch := DefaultSettings()
+	applyLayer(scratch, sm.layers[scope])
 	for key, value := range updates {
-		switch key {
-		case "maxRetries":
-			val := toInt(value)
-			if val < 0 || val > 10 {
-				return fmt.Errorf("maxRetries must be 0-10, got %d", val)
-			}
-
-		case "retryDelay":
-			val := toInt(value)
-			if val < 0 || val > 60000 {
-				return fmt.Errorf("retryDelay must be 0-60000ms, got %d", val)
-			}
-
-		case "stepTimeoutDefault":
-			val := toInt(value)
-			if val < 1000 || val > 3600000 {
-				return fmt.Errorf("stepTimeoutDefault must be 1000-3600000ms (1s-1h), got %d", val)
-			}
-
-		case "heartbeatInterval":
-			val := toInt(value)
-			if val < 1000 || val > 300000 {
-				return fmt.Errorf("heartbeatInterval must be 1000-300000ms (1s-5min), got %d", val)
-			}
-
-		case "theme":
-			if v, ok := value.(string); ok {
-				if v != "light" && v != "dark" && v != "system" {
-					return fmt.Errorf("theme must be 'light', 'dark', or 'system', got %q", v)
-				}
-			}
-
-		case "lastProjectPath":
-			if v, ok := value.(string); ok {
-				// Prevent path traversal attacks
-				if strings.Contains(v, "..") {
-					return fmt.Errorf("lastProjectPath contains path traversal sequence '..'")
-				}
-			}
-
-		case "recentProjectsMax":
-			val := toInt(value)
-			if val < 1 || val > 50 {
-				return fmt.Errorf("recentProjectsMax must be 1-50, got %d", val)
-			}
-
-		case "projectProfiles":
-			if v, ok := value.(map[string]interface{}); ok {
-				for k := range v {
-					// Validate project path doesn't contain path traversal
-					if strings.Contains(k, "..") {
-						return fmt.Errorf("projectProfiles key contains path traversal sequence '..'")
-					}
-				}
-			}
+		if err := applyUpdate(scratch, key, value); err != nil {
+			return nil, err
 		}
 	}
 
-	// PHASE 2: Apply updates (only after all validations pass)
-	for key, value := range updates {
-		switch key {
-		case "maxRetries":
-			sm.settings.MaxRetries = toInt(value)
-		case "retryDelay":
-			sm.settings.RetryDelay = toInt(value)
-		case "desktopNotifications":
-			if v, ok := value.(bool); ok {
-				sm.settings.DesktopNotifications = v
-			}
-		case "soundEnabled":
-			if v, ok := value.(bool); ok {
-				sm.settings.SoundEnabled = v
-			}
-		case "stepTimeoutDefault":
-			sm.settings.StepTimeoutDefault = toInt(value)
-		case "heartbeatInterval":
-			sm.settings.HeartbeatInterval = toInt(value)
-		case "theme":
-			if v, ok := value.(string); ok {
-				sm.settings.Theme = v
-			}
-		case "showDebugOutput":
-			if v, ok := value.(bool); ok {
-				sm.settings.ShowDebugOutput = v
-			}
-		case "lastProjectPath":
-			if v, ok := value.(string); ok {
-				sm.settings.LastProjectPath = v
-			}
-		case "recentProjectsMax":
-			sm.settings.RecentProjectsMax = toInt(value)
-		case "projectProfiles":
-			if v, ok := value.(map[string]interface{}); ok {
-				// Merge entries instead of replacing the whole map
-				// This allows different projects to have different profiles
-				if sm.settings.ProjectProfiles == nil {
-					sm.settings.ProjectProfiles = make(map[string]string)
-				}
-				for k, pv := range v {
-					if s, ok := pv.(string); ok {
-						sm.settings.ProjectProfiles[k] = s
-					}
-				}
-			}
+	fields := settingFields(scratch)
+	layer := make(map[string]json.RawMessage, len(sm.layers[scope])+len(updates))
+	for k, v := range sm.layers[scope] {
+		layer[k] = v
+	}
+	for key := range updates {
+		if raw, ok := fields[key]; ok {
+			layer[key] = raw
+		} else {
+			delete(layer, key) // Cleared omitempty value
 		}
 	}
+	return layer, nil
+}
 
-	return sm.save()
+// Reset restores all project settings to their defaults, or to the
+// user-global values where set, and saves to disk.
+func (sm *StateManager) Reset() error {
+	return sm.ResetScope(ScopeProject)
 }
 
-// toInt converts interface{} to int, handling both int and float64 types
-func toInt(v interface{}) int {
-	switch val := v.(type) {
-	case int:
-		return val
-	case float64:
-		return int(val)
-	case int64:
-		return int(val)
-	default:
-		return 0
+// ResetScope clears every value set in a writable layer and saves its file.
+func (sm *StateManager) ResetScope(scope Scope) error {
+	path, err := sm.layerPath(scope)
+	if err != nil {
+		return err
 	}
-}
 
-// Reset restores all settings to their default values and saves to disk.
-func (sm *StateManager) Reset() error {
+	lock, err := filelock.Acquire(path)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
 	sm.mu.Lock()
-	defer sm.mu.Unlock()
+	before := settingFields(sm.settings)
+	layer := make(map[string]json.RawMessage)
+	if err := writeLayer(path, layer); err != nil {
+		sm.mu.Unlock()
+		return err
+	}
+	sm.setLayerLocked(scope, path, layer)
+	sm.stamps[scope] = stampFile(path)
+	sm.resolveLocked()
+	change := Change{Keys: sm.changedKeysLocked(before)}
+	sm.mu.Unlock()
 
-	sm.settings = DefaultSettings()
-	return sm.save()
+	sm.notify(change)
+	return nil
 }
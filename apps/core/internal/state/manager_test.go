@@ -293,10 +293,24 @@ func TestStateManagerLoadWithCorruptedFile(t *testing.T) {
 		t.Fatalf("Failed to write corrupted file: %v", err)
 	}
 
-	// NewStateManager should return error for corrupted file
-	_, err := NewStateManager(projectPath)
-	if err == nil {
-		t.Error("NewStateManager() should fail with corrupted JSON file")
+	// A corrupted file is quarantined and defaults are used
+	sm, err := NewStateManager(projectPath)
+	if err != nil {
+		t.Fatalf("NewStateManager() failed: %v", err)
+	}
+	if got := sm.Get(); got.MaxRetries != DefaultSettings().MaxRetries {
+		t.Errorf("MaxRetries = %d, want default", got.MaxRetries)
+	}
+
+	quarantined := sm.Quarantined()
+	if len(quarantined) != 1 || quarantined[0].Scope != ScopeProject {
+		t.Fatalf("Quarantined() = %+v", quarantined)
+	}
+	if matches, _ := filepath.Glob(configPath + ".corrupt-*"); len(matches) != 1 {
+		t.Errorf("corrupt files = %v", matches)
+	}
+	if _, err := os.Stat(configPath); !os.IsNotExist(err) {
+		t.Error("corrupted config should have been moved aside")
 	}
 }
 
@@ -547,6 +561,88 @@ func TestStateManagerValidation_HeartbeatInterval(t *testing.T) {
 	}
 }
 
+// TestStateManagerValidation_StallDetection verifies stall interval and action validation
+func TestStateManagerValidation_StallDetection(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectPath := filepath.Join(tmpDir, "test-project")
+	sm, err := NewStateManager(projectPath)
+	if err != nil {
+		t.Fatalf("NewStateManager() failed: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		updates   map[string]interface{}
+		wantError bool
+	}{
+		{"valid_intervals_min", map[string]interface{}{"stallIntervals": 1}, false},
+		{"valid_intervals_max", map[string]interface{}{"stallIntervals": 20}, false},
+		{"invalid_intervals_zero", map[string]interface{}{"stallIntervals": 0}, true},
+		{"invalid_intervals_too_high", map[string]interface{}{"stallIntervals": 21}, true},
+		{"valid_action_warn", map[string]interface{}{"stallAction": "warn"}, false},
+		{"valid_action_kill", map[string]interface{}{"stallAction": "kill"}, false},
+		{"valid_action_retry", map[string]interface{}{"stallAction": "retry"}, false},
+		{"invalid_action", map[string]interface{}{"stallAction": "ignore"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := sm.Set(tt.updates)
+			if tt.wantError && err == nil {
+				t.Errorf("Set(%v) should have failed", tt.updates)
+			}
+			if !tt.wantError && err != nil {
+				t.Errorf("Set(%v) failed: %v", tt.updates, err)
+			}
+		})
+	}
+
+	settings := sm.Get()
+	if settings.StallIntervals != 20 || settings.StallAction != "retry" {
+		t.Errorf("stall settings = (%d, %q), want (20, \"retry\")", settings.StallIntervals, settings.StallAction)
+	}
+}
+
+// TestStateManagerValidation_YellowFlagPatterns verifies regex triggers are compiled before saving
+func TestStateManagerValidation_YellowFlagPatterns(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectPath := filepath.Join(tmpDir, "test-project")
+	sm, err := NewStateManager(projectPath)
+	if err != nil {
+		t.Fatalf("NewStateManager() failed: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		updates   map[string]interface{}
+		wantError bool
+	}{
+		{"valid_patterns", map[string]interface{}{"yellowFlagPatterns": []interface{}{`(?i)approve\?`, "WAITING"}}, false},
+		{"invalid_regex", map[string]interface{}{"yellowFlagPatterns": []interface{}{"("}}, true},
+		{"empty_entry", map[string]interface{}{"yellowFlagPatterns": []interface{}{""}}, true},
+		{"not_a_list", map[string]interface{}{"yellowFlagPatterns": "WAITING"}, true},
+		{"non_string_entry", map[string]interface{}{"yellowFlagPatterns": []interface{}{1}}, true},
+		{"disable_detection", map[string]interface{}{"yellowFlagDetection": false}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := sm.Set(tt.updates)
+			if tt.wantError && err == nil {
+				t.Errorf("Set(%v) should have failed", tt.updates)
+			}
+			if !tt.wantError && err != nil {
+				t.Errorf("Set(%v) failed: %v", tt.updates, err)
+			}
+		})
+	}
+
+	settings := sm.Get()
+	if len(settings.YellowFlagPatterns) != 2 || settings.YellowFlagDetection {
+		t.Errorf("yellow flag settings = (%v, %v)", settings.YellowFlagPatterns, settings.YellowFlagDetection)
+	}
+}
+
 // TestStateManagerValidation_RecentProjectsMax verifies recent projects limit
 func TestStateManagerValidation_RecentProjectsMax(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -583,6 +679,39 @@ func TestStateManagerValidation_RecentProjectsMax(t *testing.T) {
 	}
 }
 
+// TestStateManagerValidation_NetworkTiming verifies the network monitor and resume ranges
+func TestStateManagerValidation_NetworkTiming(t *testing.T) {
+	sm, err := NewStateManager(filepath.Join(t.TempDir(), "test-project"))
+	if err != nil {
+		t.Fatalf("NewStateManager() failed: %v", err)
+	}
+
+	tests := []struct {
+		key       string
+		value     int
+		wantError bool
+	}{
+		{"networkCheckInterval", 5000, false},
+		{"networkCheckInterval", 1000, true},
+		{"networkDebounce", 0, false},
+		{"networkDebounce", 60001, true},
+		{"networkResumeDelay", 0, false},
+		{"networkResumeDelay", -1, true},
+	}
+	for _, tt := range tests {
+		err := sm.Set(map[string]interface{}{tt.key: tt.value})
+		if (err != nil) != tt.wantError {
+			t.Errorf("Set(%s=%d) error = %v, wantError %v", tt.key, tt.value, err, tt.wantError)
+		}
+	}
+	if err := sm.Set(map[string]interface{}{"workflowOverrides": map[string]interface{}{"prd": map[string]interface{}{"offline": true}}}); err != nil {
+		t.Errorf("Set(offline override) failed: %v", err)
+	}
+	if !sm.Get().ForWorkflow("prd").Offline {
+		t.Error("ForWorkflow(prd).Offline = false, want true")
+	}
+}
+
 // TestStateManagerValidation_ProjectProfiles verifies project profile path validation
 func TestStateManagerValidation_ProjectProfiles(t *testing.T) {
 	tmpDir := t.TempDir()
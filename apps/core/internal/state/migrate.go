@@ -0,0 +1,106 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+)
+
+// CurrentSchemaVersion is the version of the settings file format written
+// by this build. Files without a schemaVersion field are version 1.
+const CurrentSchemaVersion = 2
+
+// schemaVersionKey is the settings file field holding the format version.
+const schemaVersionKey = "schemaVersion"
+
+// Migration upgrades a settings layer from one schema version to the next.
+type Migration struct {
+	From        int // Version the migration reads; it writes From+1
+	Description string
+	Apply       func(layer map[string]json.RawMessage) error
+}
+
+// migrations are applied in order to bring older files up to date.
+// Each entry must have From equal to its position plus one.
+var migrations = []Migration{
+	{
+		// Before layering, config.json held every setting. Values equal to
+		// the defaults are dropped so they no longer hide the user layer.
+		From:        1,
+		Description: "keep only values that differ from the built-in defaults",
+		Apply:       dropDefaultValues,
+	},
+}
+
+// migrateLayer upgrades a layer read at version to CurrentSchemaVersion. It
+// reports whether any migration ran. Layers from newer versions are used
+// as they are.
+func migrateLayer(layer map[string]json.RawMessage, version int) (bool, error) {
+	if version >= CurrentSchemaVersion {
+		return false, nil
+	}
+	for _, m := range migrations {
+		if m.From < version {
+			continue
+		}
+		if err := m.Apply(layer); err != nil {
+			return false, fmt.Errorf("migrating settings from version %d: %w", m.From, err)
+		}
+	}
+	return true, nil
+}
+
+// dropDefaultValues removes settings whose value equals the built-in default.
+func dropDefaultValues(layer map[string]json.RawMessage) error {
+	defaults := settingFields(DefaultSettings())
+	for key, raw := range layer {
+		def, ok := defaults[key]
+		if !ok {
+			continue
+		}
+		var value, defValue interface{}
+		if json.Unmarshal(raw, &value) != nil || json.Unmarshal(def, &defValue) != nil {
+			continue
+		}
+		if reflect.DeepEqual(value, defValue) {
+			delete(layer, key)
+		}
+	}
+	return nil
+}
+
+// CorruptFileError reports a settings file that cannot be parsed.
+type CorruptFileError struct {
+	Path string
+	Err  error
+}
+
+func (e *CorruptFileError) Error() string {
+	return fmt.Sprintf("corrupt settings file %s: %v", e.Path, e.Err)
+}
+
+func (e *CorruptFileError) Unwrap() error { return e.Err }
+
+// Quarantine records a corrupt settings file that was moved aside so the
+// server could start from the remaining layers.
+type Quarantine struct {
+	Scope   Scope     `json:"scope"`
+	Path    string    `json:"path"`
+	MovedTo string    `json:"movedTo"`
+	Error   string    `json:"error"`
+	At      time.Time `json:"at"`
+}
+
+// quarantineFile renames a corrupt file to <path>.corrupt-<timestamp>.
+func quarantineFile(path string, now time.Time) (string, error) {
+	dest := fmt.Sprintf("%s.corrupt-%s", path, now.UTC().Format("20060102T150405Z"))
+	if _, err := os.Stat(dest); err == nil {
+		dest = fmt.Sprintf("%s.corrupt-%s", path, now.UTC().Format("20060102T150405.000000000Z"))
+	}
+	if err := os.Rename(path, dest); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
@@ -0,0 +1,154 @@
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMigrations_Fixtures loads each config in testdata/migrations as a
+// project layer and compares the file left on disk with <name>.want.json.
+// Files already at the current version must be left untouched.
+func TestMigrations_Fixtures(t *testing.T) {
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "migrations", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ran int
+	for _, fixture := range fixtures {
+		if strings.HasSuffix(fixture, ".want.json") {
+			continue
+		}
+		name := strings.TrimSuffix(filepath.Base(fixture), ".json")
+		ran++
+		t.Run(name, func(t *testing.T) {
+			input, err := os.ReadFile(fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := os.ReadFile(strings.TrimSuffix(fixture, ".json") + ".want.json")
+			if err != nil {
+				t.Fatalf("missing want file: %v", err)
+			}
+
+			projectPath := t.TempDir()
+			configPath := filepath.Join(projectPath, "_bmad-output", ".autobmad", "config.json")
+			os.MkdirAll(filepath.Dir(configPath), 0755)
+			if err := os.WriteFile(configPath, input, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			sm, err := NewLayeredStateManager(projectPath, "")
+			if err != nil {
+				t.Fatalf("NewLayeredStateManager() failed: %v", err)
+			}
+			if q := sm.Quarantined(); len(q) != 0 {
+				t.Fatalf("fixture was quarantined: %+v", q)
+			}
+
+			got, err := os.ReadFile(configPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var gotJSON, wantJSON interface{}
+			if err := json.Unmarshal(got, &gotJSON); err != nil {
+				t.Fatalf("migrated file is not JSON: %v", err)
+			}
+			json.Unmarshal(want, &wantJSON)
+			if !reflect.DeepEqual(gotJSON, wantJSON) {
+				t.Errorf("migrated file =\n%s\nwant\n%s", got, want)
+			}
+
+			// The effective settings are the same before and after migration
+			legacy := DefaultSettings()
+			json.Unmarshal(input, legacy)
+			if eff := sm.Get(); !reflect.DeepEqual(eff, legacy) {
+				t.Errorf("settings = %+v, want %+v", eff, legacy)
+			}
+		})
+	}
+	if ran == 0 {
+		t.Fatal("no migration fixtures found")
+	}
+}
+
+func TestMigrateLayer_NewerVersion(t *testing.T) {
+	layer := map[string]json.RawMessage{"maxRetries": json.RawMessage("3")}
+	migrated, err := migrateLayer(layer, CurrentSchemaVersion+1)
+	if err != nil || migrated {
+		t.Fatalf("migrateLayer() = %v, %v", migrated, err)
+	}
+	if _, ok := layer["maxRetries"]; !ok {
+		t.Error("layer from a newer version should be used as is")
+	}
+}
+
+func TestMigrations_Registry(t *testing.T) {
+	for i, m := range migrations {
+		if m.From != i+1 {
+			t.Errorf("migrations[%d].From = %d, want %d", i, m.From, i+1)
+		}
+	}
+	if len(migrations) != CurrentSchemaVersion-1 {
+		t.Errorf("%d migrations for schema version %d", len(migrations), CurrentSchemaVersion)
+	}
+}
+
+func TestQuarantine_UserLayer(t *testing.T) {
+	sm, projectPath, userPath := newLayeredTestManager(t)
+	if err := sm.SetScope(ScopeProject, map[string]interface{}{"theme": "dark"}); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"invalid JSON":    "{not json",
+		"not an object":   `["maxRetries"]`,
+		"invalid version": `{"schemaVersion": "two"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			os.MkdirAll(filepath.Dir(userPath), 0755)
+			if err := os.WriteFile(userPath, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			sm, err := NewLayeredStateManager(projectPath, userPath)
+			if err != nil {
+				t.Fatalf("NewLayeredStateManager() failed: %v", err)
+			}
+			if got := sm.Get().Theme; got != "dark" {
+				t.Errorf("project layer lost: theme = %q", got)
+			}
+
+			eff := sm.Effective()
+			if len(eff.Quarantined) != 1 || eff.Quarantined[0].Scope != ScopeUser {
+				t.Fatalf("Quarantined = %+v", eff.Quarantined)
+			}
+			moved, err := os.ReadFile(eff.Quarantined[0].MovedTo)
+			if err != nil || string(moved) != content {
+				t.Errorf("quarantined file = %q, %v", moved, err)
+			}
+			os.Remove(eff.Quarantined[0].MovedTo)
+		})
+	}
+}
+
+func TestQuarantineFile_Collision(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	os.WriteFile(path, []byte("x"), 0644)
+	first, err := quarantineFile(path, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(path, []byte("y"), 0644)
+	second, err := quarantineFile(path, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Errorf("second quarantine overwrote %s", first)
+	}
+}
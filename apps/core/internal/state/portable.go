@@ -0,0 +1,224 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DocumentFormat is the encoding of an exported settings document.
+type DocumentFormat string
+
+const (
+	DocumentJSON DocumentFormat = "json"
+	DocumentYAML DocumentFormat = "yaml"
+)
+
+// ParseDocumentFormat validates a format name. Empty means JSON.
+func ParseDocumentFormat(name string) (DocumentFormat, error) {
+	switch DocumentFormat(name) {
+	case "", DocumentJSON:
+		return DocumentJSON, nil
+	case DocumentYAML:
+		return DocumentYAML, nil
+	default:
+		return "", fmt.Errorf("unknown format %q (json or yaml)", name)
+	}
+}
+
+// SettingDiff is the change of one effective setting.
+type SettingDiff struct {
+	Key  string      `json:"key"`
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// ApplyResult reports the outcome of applying imported or preset settings.
+type ApplyResult struct {
+	Changes []SettingDiff `json:"changes"`           // Effective settings that change, by key
+	Skipped []string      `json:"skipped,omitempty"` // Machine-specific keys that were ignored
+	Applied bool          `json:"applied"`           // False for a dry run
+}
+
+// isLocal reports whether a setting is machine-specific (see SchemaField.Local).
+func isLocal(key string) bool {
+	field, ok := schemaField(key)
+	return ok && field.Local
+}
+
+// portable returns values without machine-specific settings, and the keys
+// that were removed, sorted.
+func portable(values map[string]interface{}) (map[string]interface{}, []string) {
+	out := make(map[string]interface{}, len(values))
+	var skipped []string
+	for k, v := range values {
+		if isLocal(k) {
+			skipped = append(skipped, k)
+			continue
+		}
+		out[k] = v
+	}
+	sort.Strings(skipped)
+	return out, skipped
+}
+
+// Export returns the shareable settings of a layer, or the effective
+// settings if scope is empty. Machine-specific settings are left out.
+func (sm *StateManager) Export(scope Scope) (map[string]interface{}, error) {
+	var values map[string]interface{}
+	if scope == "" {
+		values = make(map[string]interface{})
+		for k, raw := range settingFields(sm.Get()) {
+			var v interface{}
+			if err := json.Unmarshal(raw, &v); err == nil {
+				values[k] = v
+			}
+		}
+	} else {
+		var err error
+		if values, err = sm.Layer(scope); err != nil {
+			return nil, err
+		}
+	}
+	values, _ = portable(values)
+	return values, nil
+}
+
+// MarshalDocument encodes settings as a document that ParseDocument and
+// settings.import accept. It carries the schema version.
+func MarshalDocument(values map[string]interface{}, format DocumentFormat) ([]byte, error) {
+	doc := make(map[string]interface{}, len(values)+1)
+	for k, v := range values {
+		doc[k] = v
+	}
+	doc[schemaVersionKey] = CurrentSchemaVersion
+
+	switch format {
+	case DocumentYAML:
+		return yaml.Marshal(doc)
+	default:
+		return json.MarshalIndent(doc, "", "  ")
+	}
+}
+
+// ParseDocument decodes a settings document in JSON or YAML. Empty format
+// detects it: documents starting with "{" are JSON. Documents from older
+// schema versions are migrated. Values are not validated.
+func ParseDocument(data []byte, format DocumentFormat) (map[string]interface{}, error) {
+	if format == "" {
+		format = DocumentYAML
+		if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+			format = DocumentJSON
+		}
+	}
+
+	var doc map[string]interface{}
+	switch format {
+	case DocumentYAML:
+		// Round-trip through JSON so values have the types JSON decoding gives
+		var raw interface{}
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("parsing YAML: %w", err)
+		}
+		converted, err := json.Marshal(raw)
+		if err != nil {
+			return nil, fmt.Errorf("parsing YAML: %w", err)
+		}
+		data = converted
+		fallthrough
+	case DocumentJSON:
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("parsing settings: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("parsing settings: not an object")
+	}
+
+	layer := make(map[string]json.RawMessage, len(doc))
+	for k, v := range doc {
+		layer[k], _ = json.Marshal(v)
+	}
+	version := 1
+	if raw, ok := layer[schemaVersionKey]; ok {
+		if err := json.Unmarshal(raw, &version); err != nil || version < 1 {
+			return nil, fmt.Errorf("invalid %s %s", schemaVersionKey, raw)
+		}
+		delete(layer, schemaVersionKey)
+	}
+	if _, err := migrateLayer(layer, version); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{}, len(layer))
+	for k, raw := range layer {
+		var v interface{}
+		json.Unmarshal(raw, &v)
+		values[k] = v
+	}
+	return values, nil
+}
+
+// Apply validates values and sets them in a writable layer, reporting how
+// the effective settings change. Machine-specific settings are skipped. A
+// dry run only reports the changes.
+func (sm *StateManager) Apply(scope Scope, values map[string]interface{}, dryRun bool) (*ApplyResult, error) {
+	if _, err := sm.layerPath(scope); err != nil {
+		return nil, err
+	}
+	updates, skipped := portable(values)
+	if err := validateUpdates(updates); err != nil {
+		return nil, err
+	}
+
+	changes, err := sm.plan(scope, updates)
+	if err != nil {
+		return nil, err
+	}
+	result := &ApplyResult{Changes: changes, Skipped: skipped}
+	if dryRun || len(updates) == 0 {
+		return result, nil
+	}
+	if err := sm.SetScope(scope, updates); err != nil {
+		return nil, err
+	}
+	result.Applied = true
+	return result, nil
+}
+
+// plan computes the effective settings changes that validated updates to a
+// layer would make, without saving them.
+func (sm *StateManager) plan(scope Scope, updates map[string]interface{}) ([]SettingDiff, error) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	layer, err := sm.updatedLayerLocked(scope, updates)
+	if err != nil {
+		return nil, err
+	}
+	layers := make(map[Scope]map[string]json.RawMessage, len(sm.layers))
+	for s, l := range sm.layers {
+		layers[s] = l
+	}
+	layers[scope] = layer
+	after, _ := resolveLayers(layers)
+
+	before, planned := settingFields(sm.settings), settingFields(after)
+	changes := []SettingDiff{}
+	for _, key := range settingKeys {
+		if bytes.Equal(before[key], planned[key]) {
+			continue
+		}
+		diff := SettingDiff{Key: key}
+		json.Unmarshal(before[key], &diff.From)
+		json.Unmarshal(planned[key], &diff.To)
+		changes = append(changes, diff)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes, nil
+}
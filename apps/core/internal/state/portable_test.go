@@ -0,0 +1,121 @@
+package state
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExport_StripsLocalSettings(t *testing.T) {
+	sm, _, _ := newLayeredTestManager(t)
+	if err := sm.Set(map[string]interface{}{
+		"maxRetries":      6,
+		"lastProjectPath": "/home/user/project",
+		"projectProfiles": map[string]interface{}{"/home/user/project": "fast"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := sm.Export(ScopeProject)
+	if err != nil {
+		t.Fatalf("Export() failed: %v", err)
+	}
+	if !reflect.DeepEqual(values, map[string]interface{}{"maxRetries": float64(6)}) {
+		t.Errorf("Export(project) = %v", values)
+	}
+
+	effective, err := sm.Export("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := effective["lastProjectPath"]; ok {
+		t.Error("effective export contains lastProjectPath")
+	}
+	if _, ok := effective["projectProfiles"]; ok {
+		t.Error("effective export contains projectProfiles")
+	}
+	if effective["theme"] != "system" || effective["maxRetries"] != float64(6) {
+		t.Errorf("Export(effective) = %v", effective)
+	}
+}
+
+func TestDocument_RoundTrip(t *testing.T) {
+	values := map[string]interface{}{
+		"maxRetries":         float64(5),
+		"stallAction":        "retry",
+		"yellowFlagPatterns": []interface{}{"(?i)approve"},
+	}
+	for _, format := range []DocumentFormat{DocumentJSON, DocumentYAML} {
+		data, err := MarshalDocument(values, format)
+		if err != nil {
+			t.Fatalf("MarshalDocument(%s) failed: %v", format, err)
+		}
+		if !strings.Contains(string(data), "schemaVersion") {
+			t.Errorf("%s document has no schemaVersion:\n%s", format, data)
+		}
+		// Format is detected when not given
+		parsed, err := ParseDocument(data, "")
+		if err != nil {
+			t.Fatalf("ParseDocument(%s) failed: %v", format, err)
+		}
+		if !reflect.DeepEqual(parsed, values) {
+			t.Errorf("%s round trip = %v, want %v", format, parsed, values)
+		}
+	}
+}
+
+func TestParseDocument_MigratesAndRejects(t *testing.T) {
+	// Version 1 documents were full dumps; defaults are dropped
+	parsed, err := ParseDocument([]byte(`{"maxRetries": 3, "theme": "dark"}`), DocumentJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, map[string]interface{}{"theme": "dark"}) {
+		t.Errorf("migrated document = %v", parsed)
+	}
+
+	for _, bad := range []string{"{nope", "[1, 2]", `{"schemaVersion": "x"}`, "- just\n- a list\n"} {
+		if _, err := ParseDocument([]byte(bad), ""); err == nil {
+			t.Errorf("ParseDocument(%q) should fail", bad)
+		}
+	}
+}
+
+func TestApply_DryRunAndApply(t *testing.T) {
+	sm, _, _ := newLayeredTestManager(t)
+	values := map[string]interface{}{
+		"maxRetries":      float64(7),
+		"theme":           "system", // Unchanged
+		"lastProjectPath": "/elsewhere",
+	}
+
+	result, err := sm.Apply(ScopeProject, values, true)
+	if err != nil {
+		t.Fatalf("Apply(dry run) failed: %v", err)
+	}
+	want := []SettingDiff{{Key: "maxRetries", From: float64(3), To: float64(7)}}
+	if !reflect.DeepEqual(result.Changes, want) || result.Applied {
+		t.Errorf("dry run = %+v", result)
+	}
+	if !reflect.DeepEqual(result.Skipped, []string{"lastProjectPath"}) {
+		t.Errorf("Skipped = %v", result.Skipped)
+	}
+	if sm.Get().MaxRetries != 3 {
+		t.Error("dry run changed the settings")
+	}
+
+	result, err = sm.Apply(ScopeProject, values, false)
+	if err != nil {
+		t.Fatalf("Apply() failed: %v", err)
+	}
+	if !result.Applied || sm.Get().MaxRetries != 7 || sm.Get().LastProjectPath != "" {
+		t.Errorf("after apply: %+v, settings %+v", result, sm.Get())
+	}
+
+	if _, err := sm.Apply(ScopeProject, map[string]interface{}{"maxRetries": "many"}, true); err == nil {
+		t.Error("Apply() should validate values")
+	}
+	if _, err := sm.Apply(ScopeEnv, values, true); err == nil {
+		t.Error("Apply() should reject read-only scopes")
+	}
+}
@@ -0,0 +1,237 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/filelock"
+	"github.com/fairyhunter13/auto-bmad/apps/core/internal/paths"
+)
+
+// Preset errors.
+var (
+	ErrPresetNotFound = errors.New("preset not found")
+	ErrInvalidPreset  = errors.New("invalid preset")
+)
+
+// Preset is a named set of settings that can be applied to a project in
+// one call, e.g. a team's retry and timeout policy.
+type Preset struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Settings    map[string]interface{} `json:"settings"`
+	BuiltIn     bool                   `json:"builtIn"` // Shipped with Auto-BMAD; a user preset of the same name replaces it
+}
+
+// builtinPresets are available without any presets file.
+var builtinPresets = []Preset{
+	{
+		Name:        "overnight-unattended",
+		Description: "Run without supervision: retry with backoff, recover stalled steps, stay quiet.",
+		Settings: map[string]interface{}{
+			"maxRetries":           5,
+			"retryBackoff":         true,
+			"retryJitter":          true,
+			"stallAction":          "retry",
+			"inputMode":            "off",
+			"desktopNotifications": false,
+			"soundEnabled":         false,
+		},
+	},
+	{
+		Name:        "interactive",
+		Description: "Work alongside the AI: few retries, warn on stalls, answer prompts in a terminal.",
+		Settings: map[string]interface{}{
+			"maxRetries":           1,
+			"retryBackoff":         false,
+			"stallAction":          "warn",
+			"inputMode":            "pty",
+			"yellowFlagDetection":  true,
+			"desktopNotifications": true,
+			"soundEnabled":         true,
+		},
+	},
+}
+
+// presetNamePattern restricts preset names to lowercase words joined by hyphens.
+var presetNamePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// UserPresetsPath returns the user-global presets file, presets.json in the
+// config directory, or "" if there is none.
+func UserPresetsPath() string {
+	return paths.ConfigFile("presets.json")
+}
+
+// presetsFile is the format of the presets file.
+type presetsFile struct {
+	SchemaVersion int                    `json:"schemaVersion"`
+	Presets       map[string]presetEntry `json:"presets"`
+}
+
+// presetEntry is one user preset in the presets file.
+type presetEntry struct {
+	Description string                 `json:"description,omitempty"`
+	Settings    map[string]interface{} `json:"settings"`
+}
+
+// PresetStore keeps user presets in a file shared by all projects, next to
+// the user-global settings.
+type PresetStore struct {
+	path string
+}
+
+// NewPresetStore creates a store backed by path. An empty path offers the
+// built-in presets only.
+func NewPresetStore(path string) *PresetStore {
+	return &PresetStore{path: path}
+}
+
+// List returns the built-in and user presets, sorted by name.
+func (ps *PresetStore) List() ([]Preset, error) {
+	file, err := ps.read()
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]Preset)
+	for _, p := range builtinPresets {
+		p.BuiltIn = true
+		settings := make(map[string]interface{}, len(p.Settings))
+		for k, v := range p.Settings {
+			settings[k] = v // Callers may modify the result
+		}
+		p.Settings = settings
+		byName[p.Name] = p
+	}
+	for name, entry := range file.Presets {
+		byName[name] = Preset{Name: name, Description: entry.Description, Settings: entry.Settings}
+	}
+
+	presets := make([]Preset, 0, len(byName))
+	for _, p := range byName {
+		presets = append(presets, p)
+	}
+	sort.Slice(presets, func(i, j int) bool { return presets[i].Name < presets[j].Name })
+	return presets, nil
+}
+
+// Get returns a preset by name.
+func (ps *PresetStore) Get(name string) (*Preset, error) {
+	presets, err := ps.List()
+	if err != nil {
+		return nil, err
+	}
+	for i := range presets {
+		if presets[i].Name == name {
+			return &presets[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrPresetNotFound, name)
+}
+
+// Save stores a user preset, replacing one of the same name. Its settings
+// are validated; machine-specific settings are not allowed.
+func (ps *PresetStore) Save(name, description string, settings map[string]interface{}) error {
+	if !presetNamePattern.MatchString(name) || len(name) > 64 {
+		return fmt.Errorf("%w: name %q must be lowercase letters, digits and hyphens", ErrInvalidPreset, name)
+	}
+	if len(settings) == 0 {
+		return fmt.Errorf("%w: %s has no settings", ErrInvalidPreset, name)
+	}
+	var errs []FieldError
+	for key := range settings {
+		if isLocal(key) {
+			errs = append(errs, FieldError{Key: key, Message: "machine-specific settings cannot be part of a preset"})
+		}
+	}
+	if len(errs) > 0 {
+		sort.Slice(errs, func(i, j int) bool { return errs[i].Key < errs[j].Key })
+		return &ValidationError{Errors: errs}
+	}
+	if err := validateUpdates(settings); err != nil {
+		return err
+	}
+
+	return ps.update(func(file *presetsFile) error {
+		file.Presets[name] = presetEntry{Description: description, Settings: settings}
+		return nil
+	})
+}
+
+// Delete removes a user preset. Built-in presets cannot be deleted; deleting
+// a user preset that replaced one restores the built-in.
+func (ps *PresetStore) Delete(name string) error {
+	return ps.update(func(file *presetsFile) error {
+		if _, ok := file.Presets[name]; !ok {
+			for _, p := range builtinPresets {
+				if p.Name == name {
+					return fmt.Errorf("%w: %s is built in", ErrInvalidPreset, name)
+				}
+			}
+			return fmt.Errorf("%w: %s", ErrPresetNotFound, name)
+		}
+		delete(file.Presets, name)
+		return nil
+	})
+}
+
+// read loads the presets file. A missing file has no presets.
+func (ps *PresetStore) read() (*presetsFile, error) {
+	file := &presetsFile{Presets: make(map[string]presetEntry)}
+	if ps.path == "" {
+		return file, nil
+	}
+	data, err := os.ReadFile(ps.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return file, nil
+		}
+		return nil, fmt.Errorf("reading presets: %w", err)
+	}
+	if err := json.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("parsing presets %s: %w", ps.path, err)
+	}
+	if file.Presets == nil {
+		file.Presets = make(map[string]presetEntry)
+	}
+	return file, nil
+}
+
+// update runs a locked read-modify-write cycle on the presets file.
+func (ps *PresetStore) update(fn func(*presetsFile) error) error {
+	if ps.path == "" {
+		return fmt.Errorf("user presets are unavailable: no home directory")
+	}
+	lock, err := filelock.Acquire(ps.path)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	file, err := ps.read()
+	if err != nil {
+		return err
+	}
+	if err := fn(file); err != nil {
+		return err
+	}
+	file.SchemaVersion = CurrentSchemaVersion
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling presets: %w", err)
+	}
+	// Atomic write: write to temp file, then rename
+	tempPath := ps.path + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0644); err != nil {
+		return fmt.Errorf("writing temp file: %w", err)
+	}
+	if err := os.Rename(tempPath, ps.path); err != nil {
+		return fmt.Errorf("renaming temp file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package state
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuiltinPresets_Valid(t *testing.T) {
+	for _, p := range builtinPresets {
+		if !presetNamePattern.MatchString(p.Name) {
+			t.Errorf("invalid built-in preset name %q", p.Name)
+		}
+		if err := validateUpdates(p.Settings); err != nil {
+			t.Errorf("built-in preset %s: %v", p.Name, err)
+		}
+		for key := range p.Settings {
+			if isLocal(key) {
+				t.Errorf("built-in preset %s sets machine-specific %s", p.Name, key)
+			}
+		}
+	}
+}
+
+func TestPresetStore(t *testing.T) {
+	ps := NewPresetStore(filepath.Join(t.TempDir(), "auto-bmad", "presets.json"))
+
+	presets, err := ps.List()
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(presets) != len(builtinPresets) {
+		t.Errorf("List() = %d presets, want the built-ins", len(presets))
+	}
+
+	if err := ps.Save("team-policy", "Shared retries", map[string]interface{}{"maxRetries": 4, "retryDelay": 10000}); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	// Replacing a built-in
+	if err := ps.Save("interactive", "", map[string]interface{}{"inputMode": "pipe"}); err != nil {
+		t.Fatalf("Save(interactive) failed: %v", err)
+	}
+
+	reopened := NewPresetStore(ps.path)
+	team, err := reopened.Get("team-policy")
+	if err != nil || team.BuiltIn || team.Settings["retryDelay"] != float64(10000) {
+		t.Errorf("Get(team-policy) = %+v, %v", team, err)
+	}
+	interactive, _ := reopened.Get("interactive")
+	if interactive.BuiltIn || len(interactive.Settings) != 1 {
+		t.Errorf("user preset did not replace built-in: %+v", interactive)
+	}
+
+	// Deleting the replacement restores the built-in
+	if err := reopened.Delete("interactive"); err != nil {
+		t.Fatal(err)
+	}
+	if interactive, _ = reopened.Get("interactive"); !interactive.BuiltIn {
+		t.Errorf("built-in not restored: %+v", interactive)
+	}
+	if err := reopened.Delete("interactive"); !errors.Is(err, ErrInvalidPreset) {
+		t.Errorf("Delete(built-in) = %v", err)
+	}
+	if _, err := reopened.Get("missing"); !errors.Is(err, ErrPresetNotFound) {
+		t.Errorf("Get(missing) = %v", err)
+	}
+}
+
+func TestPresetStore_Validation(t *testing.T) {
+	ps := NewPresetStore(filepath.Join(t.TempDir(), "presets.json"))
+
+	if err := ps.Save("Bad Name", "", map[string]interface{}{"maxRetries": 1}); !errors.Is(err, ErrInvalidPreset) {
+		t.Errorf("Save(bad name) = %v", err)
+	}
+	if err := ps.Save("empty", "", nil); !errors.Is(err, ErrInvalidPreset) {
+		t.Errorf("Save(no settings) = %v", err)
+	}
+	var verr *ValidationError
+	if err := ps.Save("local", "", map[string]interface{}{"lastProjectPath": "/x"}); !errors.As(err, &verr) {
+		t.Errorf("Save(local setting) = %v", err)
+	}
+	if err := ps.Save("typo", "", map[string]interface{}{"maxRetry": 1}); !errors.As(err, &verr) {
+		t.Errorf("Save(unknown setting) = %v", err)
+	}
+
+	if err := NewPresetStore("").Save("x", "", map[string]interface{}{"maxRetries": 1}); err == nil {
+		t.Error("Save() without a presets file should fail")
+	}
+}
@@ -0,0 +1,119 @@
+package state
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"time"
+)
+
+// Change describes settings whose effective values changed.
+type Change struct {
+	Keys     []string `json:"keys"`     // Changed setting keys, sorted
+	External bool     `json:"external"` // Made outside this manager, e.g. by another process or an editor
+}
+
+// fileStamp identifies the content of a layer file as last read or written.
+type fileStamp struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+	hash    [sha256.Size]byte
+}
+
+// stampFile records the state of path. A missing file has the zero stamp.
+func stampFile(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{exists: true, modTime: info.ModTime(), size: info.Size(), hash: sha256.Sum256(data)}
+}
+
+// changedSince reports whether path differs from the stamp. Files with the
+// same modification time and size are assumed unchanged; otherwise the
+// content hash decides, so touching a file is not a change.
+func (st fileStamp) changedSince(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return st.exists
+	}
+	if st.exists && info.ModTime().Equal(st.modTime) && info.Size() == st.size {
+		return false
+	}
+	current := stampFile(path)
+	return current.exists != st.exists || current.hash != st.hash
+}
+
+// Reload re-reads the layer files that were modified since this manager last
+// read or wrote them, and reports the settings that changed as a result.
+// OnChange is called with the same change if it is not empty. A layer file
+// that cannot be read, e.g. because it is being edited into invalid JSON,
+// keeps its last good values and its error is returned; unlike at startup,
+// the file is left in place.
+func (sm *StateManager) Reload() (Change, error) {
+	sm.mu.Lock()
+	before := settingFields(sm.settings)
+	var errs []error
+	for _, scope := range []Scope{ScopeUser, ScopeProject} {
+		if _, err := sm.refreshLocked(scope); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	sm.resolveLocked()
+	change := Change{Keys: sm.changedKeysLocked(before), External: true}
+	sm.mu.Unlock()
+
+	sm.notify(change)
+	return change, errors.Join(errs...)
+}
+
+// refreshLocked re-reads one layer file if it changed on disk and reports
+// whether it did. On error the layer keeps its last good values and is read
+// again next time. Caller must hold sm.mu; settings are not re-resolved.
+func (sm *StateManager) refreshLocked(scope Scope) (bool, error) {
+	path, err := sm.layerPath(scope)
+	if err != nil {
+		return false, nil // Layer without a file
+	}
+	if !sm.stamps[scope].changedSince(path) {
+		return false, nil
+	}
+	layer, err := readAndMigrate(path)
+	if err != nil {
+		return false, err
+	}
+	sm.setLayerLocked(scope, path, layer)
+	sm.stamps[scope] = stampFile(path)
+	return true, nil
+}
+
+// changedKeysLocked lists the settings whose effective value differs from
+// before. Caller must hold sm.mu.
+func (sm *StateManager) changedKeysLocked(before map[string]json.RawMessage) []string {
+	after := settingFields(sm.settings)
+	var keys []string
+	for _, key := range settingKeys {
+		if !bytes.Equal(before[key], after[key]) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// notify calls OnChange for a non-empty change. It must be called without
+// holding sm.mu so the callback can read the settings.
+func (sm *StateManager) notify(change Change) {
+	if len(change.Keys) == 0 || sm.OnChange == nil {
+		return
+	}
+	sm.OnChange(change)
+}
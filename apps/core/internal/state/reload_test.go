@@ -0,0 +1,142 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReload_ExternalChange(t *testing.T) {
+	sm, projectPath, userPath := newLayeredTestManager(t)
+	var changes []Change
+	sm.OnChange = func(c Change) { changes = append(changes, c) }
+
+	// Another instance writes the project layer
+	other, err := NewLayeredStateManager(projectPath, userPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Set(map[string]interface{}{"maxRetries": 7, "theme": "dark"}); err != nil {
+		t.Fatal(err)
+	}
+
+	change, err := sm.Reload()
+	if err != nil {
+		t.Fatalf("Reload() failed: %v", err)
+	}
+	if !reflect.DeepEqual(change.Keys, []string{"maxRetries", "theme"}) || !change.External {
+		t.Errorf("Reload() = %+v", change)
+	}
+	if got := sm.Get(); got.MaxRetries != 7 || got.Theme != "dark" {
+		t.Errorf("settings after reload = %+v", got)
+	}
+	if len(changes) != 1 {
+		t.Errorf("OnChange called %d times", len(changes))
+	}
+
+	// Nothing changed since: no keys, no notification
+	change, err = sm.Reload()
+	if err != nil || len(change.Keys) != 0 {
+		t.Errorf("second Reload() = %+v, %v", change, err)
+	}
+
+	// Touching the file is not a change
+	future := time.Now().Add(time.Hour)
+	os.Chtimes(sm.configPath, future, future)
+	if change, _ := sm.Reload(); len(change.Keys) != 0 {
+		t.Errorf("Reload() after touch = %+v", change)
+	}
+	if len(changes) != 1 {
+		t.Errorf("OnChange called %d times", len(changes))
+	}
+}
+
+func TestReload_CorruptFileKeepsLastGoodLayer(t *testing.T) {
+	sm, _, _ := newLayeredTestManager(t)
+	if err := sm.Set(map[string]interface{}{"maxRetries": 6}); err != nil {
+		t.Fatal(err)
+	}
+
+	// An editor saves the file half-written
+	if err := os.WriteFile(sm.configPath, []byte(`{"maxRetries": 8,`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	change, err := sm.Reload()
+	var corrupt *CorruptFileError
+	if !errors.As(err, &corrupt) || len(change.Keys) != 0 {
+		t.Fatalf("Reload() = %+v, %v; want a corrupt file error", change, err)
+	}
+	if got := sm.Get(); got.MaxRetries != 6 {
+		t.Errorf("maxRetries = %d, want the last good 6", got.MaxRetries)
+	}
+	if q := sm.Quarantined(); len(q) != 0 {
+		t.Errorf("Quarantined() = %+v, want the file left in place", q)
+	}
+	if matches, _ := filepath.Glob(sm.configPath + ".corrupt-*"); len(matches) != 0 {
+		t.Errorf("corrupt files = %v", matches)
+	}
+	// Writes must not overwrite the file being edited
+	if err := sm.Set(map[string]interface{}{"theme": "dark"}); !errors.As(err, &corrupt) {
+		t.Errorf("Set() = %v, want a corrupt file error", err)
+	}
+
+	// Once fixed, the file is picked up
+	if err := os.WriteFile(sm.configPath, []byte(`{"maxRetries": 8}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if change, err := sm.Reload(); err != nil || !reflect.DeepEqual(change.Keys, []string{"maxRetries"}) {
+		t.Errorf("Reload() after fix = %+v, %v", change, err)
+	}
+}
+
+func TestSetScope_KeepsConcurrentWrites(t *testing.T) {
+	first, projectPath, userPath := newLayeredTestManager(t)
+	second, err := NewLayeredStateManager(projectPath, userPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := first.Set(map[string]interface{}{"maxRetries": 6}); err != nil {
+		t.Fatal(err)
+	}
+	// second has not reloaded; its write must not drop maxRetries
+	if err := second.Set(map[string]interface{}{"theme": "light"}); err != nil {
+		t.Fatal(err)
+	}
+
+	reread, err := NewLayeredStateManager(projectPath, userPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reread.Get(); got.MaxRetries != 6 || got.Theme != "light" {
+		t.Errorf("settings on disk = %+v", got)
+	}
+	if got := second.Get(); got.MaxRetries != 6 {
+		t.Errorf("writer did not pick up the concurrent change: %+v", got)
+	}
+}
+
+func TestOnChange_SetAndReset(t *testing.T) {
+	sm, _, _ := newLayeredTestManager(t)
+	var changes []Change
+	sm.OnChange = func(c Change) { changes = append(changes, c) }
+
+	if err := sm.Set(map[string]interface{}{"maxRetries": 4}); err != nil {
+		t.Fatal(err)
+	}
+	// Same value again: nothing changed
+	if err := sm.Set(map[string]interface{}{"maxRetries": 4}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.Reset(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Change{{Keys: []string{"maxRetries"}}, {Keys: []string{"maxRetries"}}}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes = %+v, want %+v", changes, want)
+	}
+}
@@ -0,0 +1,479 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// FieldType is the JSON type of a setting.
+type FieldType string
+
+const (
+	TypeInteger    FieldType = "integer"
+	TypeBoolean    FieldType = "boolean"
+	TypeString     FieldType = "string"
+	TypeStringList FieldType = "stringList"
+	TypeStringMap  FieldType = "stringMap"
+	TypeObjectMap  FieldType = "objectMap" // Object of objects whose properties are described by Fields
+)
+
+// String formats constrain string values, list items or map keys.
+const (
+	FormatRegex = "regex" // Must compile as a Go regular expression
+	FormatPath  = "path"  // Must not contain ".."
+	FormatName  = "name"  // Must not contain path separators or ".."
+	FormatIdent = "ident" // Letters, digits and underscores only
+)
+
+// identPattern matches FormatIdent strings, e.g. OpenCode profile names.
+var identPattern = regexp.MustCompile(`^\w+$`)
+
+// SchemaField describes one setting. Validation and application of
+// settings updates are derived from it, and settings.schema returns it so
+// the UI can render the settings page.
+type SchemaField struct {
+	Key         string        `json:"key"`
+	Type        FieldType     `json:"type"`
+	Group       string        `json:"group"`
+	Description string        `json:"description"`
+	Default     interface{}   `json:"default"`             // From DefaultSettings
+	Min         *int          `json:"min,omitempty"`       // Integers
+	Max         *int          `json:"max,omitempty"`       // Integers
+	Unit        string        `json:"unit,omitempty"`      // e.g. "ms"
+	Enum        []string      `json:"enum,omitempty"`      // Strings
+	MaxItems    int           `json:"maxItems,omitempty"`  // Lists
+	MinLength   int           `json:"minLength,omitempty"` // Strings and list items
+	MaxLength   int           `json:"maxLength,omitempty"` // Strings and list items
+	Format      string        `json:"format,omitempty"`    // FormatRegex or FormatPath
+	Merge       bool          `json:"merge,omitempty"`     // Map updates merge into the existing entries
+	Local       bool          `json:"local,omitempty"`     // Machine-specific; left out of exports, imports and presets
+	Process     bool          `json:"process,omitempty"`   // Applies to all projects; read from the user layer only
+	KeyFormat   string        `json:"keyFormat,omitempty"` // Format of map keys
+	Fields      []SchemaField `json:"fields,omitempty"`    // Properties of object map entries
+
+	index int // Field index in Settings
+}
+
+// Limits of step timeouts in milliseconds, for stepTimeoutDefault and the
+// stepTimeout of workflow overrides.
+const (
+	MinStepTimeout = 1000
+	MaxStepTimeout = 3600000
+)
+
+// intRange returns pointers for SchemaField.Min and Max.
+func intRange(min, max int) (*int, *int) {
+	return &min, &max
+}
+
+// settingsSchema declares every setting, in display order.
+var settingsSchema = func() []SchemaField {
+	retriesMin, retriesMax := intRange(0, 10)
+	delayMin, delayMax := intRange(0, 60000)
+	timeoutMin, timeoutMax := intRange(MinStepTimeout, MaxStepTimeout)
+	heartbeatMin, heartbeatMax := intRange(1000, 300000)
+	stallMin, stallMax := intRange(1, 20)
+	recentMin, recentMax := intRange(1, 50)
+	statusMin, statusMax := intRange(100, 599)
+	checkMin, checkMax := intRange(5000, 600000)
+	debounceMin, debounceMax := intRange(0, 60000)
+	resumeMin, resumeMax := intRange(0, 600000)
+
+	overrideFields := []SchemaField{
+		{Key: "stepTimeout", Type: TypeInteger, Description: "Time limit of the workflow's steps.", Min: timeoutMin, Max: timeoutMax, Unit: "ms"},
+		{Key: "maxRetries", Type: TypeInteger, Description: "Retries per step before escalating to the user.", Min: retriesMin, Max: retriesMax},
+		{Key: "retryDelay", Type: TypeInteger, Description: "Delay before a retry.", Min: delayMin, Max: delayMax, Unit: "ms"},
+		{Key: "profile", Type: TypeString, Description: "OpenCode profile, replacing the journey's profile.", MaxLength: 64, Format: FormatIdent},
+		{Key: "extraArgs", Type: TypeStringList, Description: "Extra OpenCode command-line arguments.", MaxItems: 20, MinLength: 1, MaxLength: 500},
+		{Key: "offline", Type: TypeBoolean, Description: "Runs without network access, e.g. with a local model; not paused while offline."},
+	}
+
+	probeFields := []SchemaField{
+		{Key: "kind", Type: TypeString, Description: "Probe type.", Enum: []string{"dns", "tcp", "http", "provider"}},
+		{Key: "target", Type: TypeString, Description: "Host name for dns, host:port for tcp, URL for http and provider.", MinLength: 1, MaxLength: 500},
+		{Key: "expectStatus", Type: TypeInteger, Description: "HTTP status an http probe must get; default 200.", Min: statusMin, Max: statusMax},
+	}
+
+	fields := []SchemaField{
+		{Key: "maxRetries", Type: TypeInteger, Group: "retry", Description: "Retries per step before escalating to the user.", Min: retriesMin, Max: retriesMax},
+		{Key: "retryDelay", Type: TypeInteger, Group: "retry", Description: "Delay before a retry.", Min: delayMin, Max: delayMax, Unit: "ms"},
+		{Key: "retryBackoff", Type: TypeBoolean, Group: "retry", Description: "Double the delay after each retry."},
+		{Key: "retryJitter", Type: TypeBoolean, Group: "retry", Description: "Randomize the delay between 50% and 100%."},
+		{Key: "desktopNotifications", Type: TypeBoolean, Group: "notifications", Description: "Show desktop notifications."},
+		{Key: "soundEnabled", Type: TypeBoolean, Group: "notifications", Description: "Play a sound with notifications."},
+		{Key: "stepTimeoutDefault", Type: TypeInteger, Group: "timeouts", Description: "Time limit of a step.", Min: timeoutMin, Max: timeoutMax, Unit: "ms"},
+		{Key: "heartbeatInterval", Type: TypeInteger, Group: "timeouts", Description: "Interval of progress heartbeats from running steps.", Min: heartbeatMin, Max: heartbeatMax, Unit: "ms"},
+		{Key: "stallIntervals", Type: TypeInteger, Group: "stall", Description: "Heartbeats without output before a step counts as stalled.", Min: stallMin, Max: stallMax},
+		{Key: "stallAction", Type: TypeString, Group: "stall", Description: "What to do with a stalled step.", Enum: []string{"warn", "kill", "retry"}},
+		{Key: "inputMode", Type: TypeString, Group: "input", Description: "How input reaches running OpenCode processes.", Enum: []string{"off", "pipe", "pty"}},
+		{Key: "yellowFlagDetection", Type: TypeBoolean, Group: "yellowFlags", Description: "Pause when the AI asks for a human decision."},
+		{Key: "yellowFlagPatterns", Type: TypeStringList, Group: "yellowFlags", Description: "Extra regular expressions that raise a yellow flag, matched per output line.", MaxItems: 50, MinLength: 1, MaxLength: 500, Format: FormatRegex},
+		{Key: "gitCheckpoints", Type: TypeBoolean, Group: "checkpoints", Description: "Commit _bmad-output/ to the project's Git repository after each completed step."},
+		{Key: "theme", Type: TypeString, Group: "ui", Description: "Color theme.", Enum: []string{"light", "dark", "system"}},
+		{Key: "showDebugOutput", Type: TypeBoolean, Group: "ui", Description: "Show debug output in the UI."},
+		{Key: "workflowOverrides", Type: TypeObjectMap, Group: "workflows", Description: "Timeout, retries, profile and extra arguments per manifest workflow.", MaxItems: 200, KeyFormat: FormatName, Fields: overrideFields},
+		{Key: "networkProbes", Type: TypeObjectMap, Group: "network", Description: "Reachability probes by name. Empty uses the built-in probes; AI provider endpoints of the active profile are always probed.", MaxItems: 20, KeyFormat: FormatName, Fields: probeFields},
+		{Key: "networkCheckInterval", Type: TypeInteger, Group: "network", Description: "Interval of network checks.", Min: checkMin, Max: checkMax, Unit: "ms", Process: true},
+		{Key: "networkDebounce", Type: TypeInteger, Group: "network", Description: "How long a network change must last before it is reported.", Min: debounceMin, Max: debounceMax, Unit: "ms", Process: true},
+		{Key: "networkResumeDelay", Type: TypeInteger, Group: "network", Description: "How long the network must stay up before journeys paused while offline resume.", Min: resumeMin, Max: resumeMax, Unit: "ms"},
+		{Key: "lastProjectPath", Type: TypeString, Group: "projects", Description: "Project opened most recently.", Format: FormatPath, Local: true, Process: true},
+		{Key: "projectProfiles", Type: TypeStringMap, Group: "projects", Description: "OpenCode profile last used per project path.", Format: FormatPath, Merge: true, Local: true, Process: true},
+		{Key: "recentProjectsMax", Type: TypeInteger, Group: "projects", Description: "Number of recent projects to remember.", Min: recentMin, Max: recentMax, Process: true},
+	}
+
+	defaults := settingFields(DefaultSettings())
+	t := reflect.TypeOf(Settings{})
+	for i := range fields {
+		f := &fields[i]
+		f.index = -1
+		for j := 0; j < t.NumField(); j++ {
+			if name, _, _ := strings.Cut(t.Field(j).Tag.Get("json"), ","); name == f.Key {
+				f.index = j
+			}
+		}
+		if f.index < 0 {
+			panic("settings schema: no Settings field for " + f.Key)
+		}
+		var def interface{}
+		if raw, ok := defaults[f.Key]; ok {
+			json.Unmarshal(raw, &def)
+		} else if f.Type == TypeString {
+			def = "" // Omitted when empty
+		}
+		f.Default = def
+	}
+	return fields
+}()
+
+// Schema returns the settings schema in display order.
+func Schema() []SchemaField {
+	fields := make([]SchemaField, len(settingsSchema))
+	copy(fields, settingsSchema)
+	return fields
+}
+
+// ProcessSettings returns the keys of the settings that apply to all
+// projects (see SchemaField.Process), in display order.
+func ProcessSettings() []string {
+	var keys []string
+	for _, f := range settingsSchema {
+		if f.Process {
+			keys = append(keys, f.Key)
+		}
+	}
+	return keys
+}
+
+// schemaField returns the schema of a setting.
+func schemaField(key string) (*SchemaField, bool) {
+	for i := range settingsSchema {
+		if settingsSchema[i].Key == key {
+			return &settingsSchema[i], true
+		}
+	}
+	return nil, false
+}
+
+// FieldError is a validation error of one setting.
+type FieldError struct {
+	Key     string `json:"key"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every invalid setting of an update.
+type ValidationError struct {
+	Errors []FieldError `json:"errors"`
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		parts[i] = fe.Key + ": " + fe.Message
+	}
+	return "invalid settings: " + strings.Join(parts, "; ")
+}
+
+// validateUpdates checks every update against the schema. Unknown keys and
+// wrong types are errors. It returns a *ValidationError listing all fields.
+func validateUpdates(updates map[string]interface{}) error {
+	var errs []FieldError
+	for key, value := range updates {
+		field, ok := schemaField(key)
+		if !ok {
+			errs = append(errs, FieldError{Key: key, Message: "unknown setting"})
+			continue
+		}
+		if _, err := field.coerce(value); err != nil {
+			errs = append(errs, FieldError{Key: key, Message: err.Error()})
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Key < errs[j].Key })
+	return &ValidationError{Errors: errs}
+}
+
+// applyUpdate sets one validated value on settings.
+func applyUpdate(settings *Settings, key string, value interface{}) error {
+	field, ok := schemaField(key)
+	if !ok {
+		return fmt.Errorf("unknown setting %q", key)
+	}
+	v, err := field.coerce(value)
+	if err != nil {
+		return err
+	}
+
+	target := reflect.ValueOf(settings).Elem().Field(field.index)
+	if field.Type == TypeObjectMap {
+		// Validated entries convert through their JSON form
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		converted := reflect.New(target.Type())
+		if err := json.Unmarshal(data, converted.Interface()); err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+		target.Set(converted.Elem())
+		return nil
+	}
+	if field.Type == TypeStringMap && field.Merge {
+		// Merge entries instead of replacing the whole map
+		// This allows different projects to have different profiles
+		if target.IsNil() {
+			target.Set(reflect.MakeMap(target.Type()))
+		}
+		for k, mv := range v.(map[string]string) {
+			target.SetMapIndex(reflect.ValueOf(k), reflect.ValueOf(mv))
+		}
+		return nil
+	}
+	target.Set(reflect.ValueOf(v))
+	return nil
+}
+
+// coerce checks a JSON-decoded value against the field and converts it to
+// the Go type of the Settings field.
+func (f *SchemaField) coerce(value interface{}) (interface{}, error) {
+	switch f.Type {
+	case TypeInteger:
+		n, ok := integerValue(value)
+		if !ok {
+			return nil, fmt.Errorf("must be an integer, got %s", describeValue(value))
+		}
+		if (f.Min != nil && n < *f.Min) || (f.Max != nil && n > *f.Max) {
+			return nil, fmt.Errorf("must be %d-%d%s, got %d", *f.Min, *f.Max, f.Unit, n)
+		}
+		return n, nil
+
+	case TypeBoolean:
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("must be a boolean, got %s", describeValue(value))
+		}
+		return b, nil
+
+	case TypeString:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("must be a string, got %s", describeValue(value))
+		}
+		if err := f.checkString(s); err != nil {
+			return nil, err
+		}
+		return s, nil
+
+	case TypeStringList:
+		items, ok := stringList(value)
+		if !ok {
+			return nil, fmt.Errorf("must be a list of strings, got %s", describeValue(value))
+		}
+		if f.MaxItems > 0 && len(items) > f.MaxItems {
+			return nil, fmt.Errorf("must have at most %d entries, got %d", f.MaxItems, len(items))
+		}
+		for _, item := range items {
+			if err := f.checkString(item); err != nil {
+				return nil, fmt.Errorf("entry %q: %v", item, err)
+			}
+		}
+		return items, nil
+
+	case TypeObjectMap:
+		return f.coerceObjectMap(value)
+
+	case TypeStringMap:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			if sm, isMap := value.(map[string]string); isMap {
+				m = make(map[string]interface{}, len(sm))
+				for k, v := range sm {
+					m[k] = v
+				}
+			} else {
+				return nil, fmt.Errorf("must be an object of strings, got %s", describeValue(value))
+			}
+		}
+		out := make(map[string]string, len(m))
+		for k, v := range m {
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("entry %q must be a string, got %s", k, describeValue(v))
+			}
+			if f.Format == FormatPath && strings.Contains(k, "..") {
+				return nil, fmt.Errorf("key contains path traversal sequence '..'")
+			}
+			out[k] = s
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", f.Type)
+}
+
+// coerceObjectMap checks an object of objects against Fields. It returns
+// the entries with their properties coerced; applyUpdate converts them to
+// the Settings field's type.
+func (f *SchemaField) coerceObjectMap(value interface{}) (interface{}, error) {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("must be an object, got %s", describeValue(value))
+	}
+	if f.MaxItems > 0 && len(m) > f.MaxItems {
+		return nil, fmt.Errorf("must have at most %d entries, got %d", f.MaxItems, len(m))
+	}
+	out := make(map[string]map[string]interface{}, len(m))
+	for k, v := range m {
+		if err := checkFormat(f.KeyFormat, k); err != nil || k == "" {
+			return nil, fmt.Errorf("invalid key %q", k)
+		}
+		entry, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("entry %q must be an object, got %s", k, describeValue(v))
+		}
+		props := make(map[string]interface{}, len(entry))
+		for name, pv := range entry {
+			var prop *SchemaField
+			for i := range f.Fields {
+				if f.Fields[i].Key == name {
+					prop = &f.Fields[i]
+				}
+			}
+			if prop == nil {
+				return nil, fmt.Errorf("entry %q: unknown property %q", k, name)
+			}
+			cv, err := prop.coerce(pv)
+			if err != nil {
+				return nil, fmt.Errorf("entry %q: %s %v", k, name, err)
+			}
+			props[name] = cv
+		}
+		out[k] = props
+	}
+	return out, nil
+}
+
+// checkString applies enum, length and format constraints.
+func (f *SchemaField) checkString(s string) error {
+	if len(f.Enum) > 0 {
+		for _, e := range f.Enum {
+			if s == e {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %s, got %q", strings.Join(f.Enum, ", "), s)
+	}
+	if len(s) < f.MinLength || (f.MaxLength > 0 && len(s) > f.MaxLength) {
+		return fmt.Errorf("must be %d-%d characters", f.MinLength, f.MaxLength)
+	}
+	return checkFormat(f.Format, s)
+}
+
+// checkFormat applies a string format.
+func checkFormat(format, s string) error {
+	switch format {
+	case FormatRegex:
+		if _, err := regexp.Compile(s); err != nil {
+			return fmt.Errorf("not a valid regex: %v", err)
+		}
+	case FormatPath:
+		// Prevent path traversal attacks
+		if strings.Contains(s, "..") {
+			return fmt.Errorf("contains path traversal sequence '..'")
+		}
+	case FormatName:
+		if strings.ContainsAny(s, `/\`) || strings.Contains(s, "..") {
+			return fmt.Errorf("must not contain path separators")
+		}
+	case FormatIdent:
+		if !identPattern.MatchString(s) {
+			return fmt.Errorf("must contain only letters, digits and underscores")
+		}
+	}
+	return nil
+}
+
+// integerValue converts JSON numbers and Go integers to int. Fractions fail.
+func integerValue(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case float64:
+		if n != math.Trunc(n) || math.IsInf(n, 0) {
+			return 0, false
+		}
+		return int(n), true
+	case json.Number:
+		i, err := n.Int64()
+		return int(i), err == nil
+	default:
+		return 0, false
+	}
+}
+
+// stringList converts a JSON array to []string. It fails on non-string elements.
+func stringList(v interface{}) ([]string, bool) {
+	switch val := v.(type) {
+	case []string:
+		return append([]string{}, val...), true
+	case []interface{}:
+		out := make([]string, 0, len(val))
+		for _, item := range val {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			out = append(out, s)
+		}
+		return out, true
+	default:
+		return nil, false
+	}
+}
+
+// describeValue names the JSON type of a value for error messages.
+func describeValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return fmt.Sprintf("string %q", v)
+	case float64, int, int64, json.Number:
+		return fmt.Sprintf("number %v", v)
+	case []interface{}, []string:
+		return "list"
+	case map[string]interface{}, map[string]string:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
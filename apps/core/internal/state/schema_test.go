@@ -0,0 +1,81 @@
+package state
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestSchema_CoversSettings(t *testing.T) {
+	schema := Schema()
+	if len(schema) != len(settingKeys) {
+		t.Errorf("schema has %d fields, Settings has %d", len(schema), len(settingKeys))
+	}
+	for _, key := range settingKeys {
+		if _, ok := schemaField(key); !ok {
+			t.Errorf("no schema for %s", key)
+		}
+	}
+
+	field, _ := schemaField("maxRetries")
+	if field.Default != float64(3) || *field.Min != 0 || *field.Max != 10 {
+		t.Errorf("maxRetries schema = %+v", field)
+	}
+	field, _ = schemaField("theme")
+	if field.Default != "system" || len(field.Enum) != 3 {
+		t.Errorf("theme schema = %+v", field)
+	}
+}
+
+func TestValidateUpdates_FieldErrors(t *testing.T) {
+	err := validateUpdates(map[string]interface{}{
+		"maxRetries":         "5",
+		"retryDelay":         1.5,
+		"theme":              "neon",
+		"soundEnabled":       "yes",
+		"projectProfiles":    map[string]interface{}{"/p": 1},
+		"unknownSetting":     true,
+		"stepTimeoutDefault": 60000,
+	})
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("err = %v, want *ValidationError", err)
+	}
+
+	want := []string{"maxRetries", "projectProfiles", "retryDelay", "soundEnabled", "theme", "unknownSetting"}
+	if len(verr.Errors) != len(want) {
+		t.Fatalf("errors = %+v", verr.Errors)
+	}
+	for i, key := range want {
+		if verr.Errors[i].Key != key {
+			t.Errorf("errors[%d] = %+v, want key %s", i, verr.Errors[i], key)
+		}
+	}
+	if msg := verr.Errors[0].Message; msg != `must be an integer, got string "5"` {
+		t.Errorf("maxRetries message = %q", msg)
+	}
+}
+
+func TestStateManagerSet_RejectsUnknownAndWrongTypes(t *testing.T) {
+	sm, err := NewLayeredStateManager(filepath.Join(t.TempDir(), "project"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sm.Set(map[string]interface{}{"maxRetrys": 5}); err == nil {
+		t.Error("Set() accepted an unknown key")
+	}
+	if err := sm.Set(map[string]interface{}{"maxRetries": 4, "retryBackoff": "true"}); err == nil {
+		t.Error("Set() accepted a string for a boolean")
+	}
+	if got := sm.Get().MaxRetries; got != 3 {
+		t.Errorf("MaxRetries = %d after rejected update, want 3", got)
+	}
+
+	if err := sm.Set(map[string]interface{}{"retryBackoff": true, "yellowFlagPatterns": []interface{}{"WAIT"}}); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if got := sm.Get(); !got.RetryBackoff || len(got.YellowFlagPatterns) != 1 {
+		t.Errorf("settings = %+v", got)
+	}
+}
@@ -3,7 +3,129 @@
 // ensuring persistence across crashes and restarts.
 package state
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// ErrJourneyNotFound is returned when no state file exists for a journey ID.
+var ErrJourneyNotFound = errors.New("journey not found")
+
+// journeyIDPattern restricts journey IDs to safe path components.
+var journeyIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
 // Manager handles journey state persistence.
+// Each journey lives in <project>/_bmad-output/.autobmad/journeys/<id>/state.json.
 type Manager struct {
-	// TODO: Story 2.1 will implement state management
+	root string // <project>/_bmad-output/.autobmad
+	mu   sync.Mutex
+}
+
+// NewManager creates a journey state manager for the given project.
+func NewManager(projectPath string) *Manager {
+	return &Manager{root: filepath.Join(projectPath, "_bmad-output", ".autobmad")}
+}
+
+// Root returns the .autobmad directory managed by this instance.
+func (m *Manager) Root() string {
+	return m.root
+}
+
+// ValidateJourneyID checks that an ID is safe to use as a directory name.
+func ValidateJourneyID(id string) error {
+	if !journeyIDPattern.MatchString(id) {
+		return fmt.Errorf("invalid journey id: %q", id)
+	}
+	return nil
+}
+
+// JourneyDir returns the directory holding all files of a journey.
+func (m *Manager) JourneyDir(id string) string {
+	return filepath.Join(m.root, "journeys", id)
+}
+
+// StepDir returns the directory holding the files of a single journey step.
+func (m *Manager) StepDir(id string, stepIndex int) string {
+	return filepath.Join(m.JourneyDir(id), "steps", fmt.Sprint(stepIndex))
+}
+
+// SaveJourney writes a journey's state atomically.
+func (m *Manager) SaveJourney(id string, journey interface{}) error {
+	if err := ValidateJourneyID(id); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(journey, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling journey state: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	dir := m.JourneyDir(id)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating journey directory: %w", err)
+	}
+
+	// Atomic write: write to temp file, then rename
+	statePath := filepath.Join(dir, "state.json")
+	tempPath := statePath + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0644); err != nil {
+		return fmt.Errorf("writing temp file: %w", err)
+	}
+	if err := os.Rename(tempPath, statePath); err != nil {
+		return fmt.Errorf("renaming temp file: %w", err)
+	}
+
+	return nil
+}
+
+// LoadJourney reads a journey's state into v.
+func (m *Manager) LoadJourney(id string, v interface{}) error {
+	if err := ValidateJourneyID(id); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(filepath.Join(m.JourneyDir(id), "state.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%w: %s", ErrJourneyNotFound, id)
+		}
+		return fmt.Errorf("reading journey state: %w", err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("parsing journey state: %w", err)
+	}
+	return nil
+}
+
+// ListJourneyIDs returns the IDs of all journeys with a state file, sorted.
+func (m *Manager) ListJourneyIDs() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(m.root, "journeys"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("reading journeys directory: %w", err)
+	}
+
+	ids := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() || ValidateJourneyID(entry.Name()) != nil {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(m.JourneyDir(entry.Name()), "state.json")); err == nil {
+			ids = append(ids, entry.Name())
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
 }
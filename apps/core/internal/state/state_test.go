@@ -0,0 +1,85 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type testJourney struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+// TestManagerSaveLoadJourney verifies journey state round-trips through disk
+func TestManagerSaveLoadJourney(t *testing.T) {
+	m := NewManager(t.TempDir())
+
+	if err := m.SaveJourney("j-1", testJourney{ID: "j-1", Status: "running"}); err != nil {
+		t.Fatalf("SaveJourney failed: %v", err)
+	}
+
+	var loaded testJourney
+	if err := m.LoadJourney("j-1", &loaded); err != nil {
+		t.Fatalf("LoadJourney failed: %v", err)
+	}
+	if loaded.Status != "running" {
+		t.Errorf("Status = %q, want running", loaded.Status)
+	}
+
+	// No temp file should remain after atomic write
+	if _, err := os.Stat(filepath.Join(m.JourneyDir("j-1"), "state.json.tmp")); !os.IsNotExist(err) {
+		t.Error("temp file should not exist after save")
+	}
+}
+
+// TestManagerLoadJourney_NotFound verifies missing journeys return ErrJourneyNotFound
+func TestManagerLoadJourney_NotFound(t *testing.T) {
+	m := NewManager(t.TempDir())
+
+	var loaded testJourney
+	err := m.LoadJourney("j-missing", &loaded)
+	if !errors.Is(err, ErrJourneyNotFound) {
+		t.Errorf("err = %v, want ErrJourneyNotFound", err)
+	}
+}
+
+// TestManagerJourneyIDValidation verifies unsafe IDs are rejected
+func TestManagerJourneyIDValidation(t *testing.T) {
+	m := NewManager(t.TempDir())
+
+	for _, id := range []string{"", "../escape", "a/b", ".hidden"} {
+		if err := m.SaveJourney(id, testJourney{}); err == nil {
+			t.Errorf("SaveJourney(%q) should fail", id)
+		}
+	}
+}
+
+// TestManagerListJourneyIDs verifies listing returns sorted IDs with state files
+func TestManagerListJourneyIDs(t *testing.T) {
+	m := NewManager(t.TempDir())
+
+	ids, err := m.ListJourneyIDs()
+	if err != nil || len(ids) != 0 {
+		t.Fatalf("ListJourneyIDs on empty = %v, %v", ids, err)
+	}
+
+	for _, id := range []string{"j-2", "j-1"} {
+		if err := m.SaveJourney(id, testJourney{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Directory without state.json is ignored
+	if err := os.MkdirAll(m.JourneyDir("j-empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err = m.ListJourneyIDs()
+	if err != nil {
+		t.Fatalf("ListJourneyIDs failed: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != "j-1" || ids[1] != "j-2" {
+		t.Errorf("ids = %v, want [j-1 j-2]", ids)
+	}
+}
@@ -0,0 +1,72 @@
+package state
+
+// WorkflowOverride replaces global settings for the steps of one workflow,
+// e.g. a longer timeout and a stronger model for dev-story. Unset fields
+// keep the global value.
+type WorkflowOverride struct {
+	StepTimeout *int     `json:"stepTimeout,omitempty"` // ms; replaces stepTimeoutDefault
+	MaxRetries  *int     `json:"maxRetries,omitempty"`
+	RetryDelay  *int     `json:"retryDelay,omitempty"` // ms
+	Profile     string   `json:"profile,omitempty"`    // OpenCode profile; replaces the journey's profile
+	ExtraArgs   []string `json:"extraArgs,omitempty"`  // Added to the OpenCode command line
+	Offline     bool     `json:"offline,omitempty"`    // Runs without network access, e.g. with a local model
+}
+
+// clone returns a deep copy of the override.
+func (o WorkflowOverride) clone() WorkflowOverride {
+	c := o
+	c.StepTimeout = cloneInt(o.StepTimeout)
+	c.MaxRetries = cloneInt(o.MaxRetries)
+	c.RetryDelay = cloneInt(o.RetryDelay)
+	if o.ExtraArgs != nil {
+		c.ExtraArgs = append([]string{}, o.ExtraArgs...)
+	}
+	return c
+}
+
+func cloneInt(p *int) *int {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+// WorkflowSettings are the settings that apply to the steps of one workflow
+// after its override.
+type WorkflowSettings struct {
+	StepTimeout int      `json:"stepTimeout"` // ms
+	MaxRetries  int      `json:"maxRetries"`
+	RetryDelay  int      `json:"retryDelay"` // ms
+	Profile     string   `json:"profile"`    // Empty keeps the journey's profile
+	ExtraArgs   []string `json:"extraArgs"`
+	Offline     bool     `json:"offline"` // Not paused while the network is offline
+}
+
+// ForWorkflow resolves the settings of a workflow: its override where set,
+// the global settings otherwise.
+func (s *Settings) ForWorkflow(workflow string) WorkflowSettings {
+	ws := WorkflowSettings{
+		StepTimeout: s.StepTimeoutDefault,
+		MaxRetries:  s.MaxRetries,
+		RetryDelay:  s.RetryDelay,
+		ExtraArgs:   []string{},
+	}
+	o, ok := s.WorkflowOverrides[workflow]
+	if !ok {
+		return ws
+	}
+	if o.StepTimeout != nil {
+		ws.StepTimeout = *o.StepTimeout
+	}
+	if o.MaxRetries != nil {
+		ws.MaxRetries = *o.MaxRetries
+	}
+	if o.RetryDelay != nil {
+		ws.RetryDelay = *o.RetryDelay
+	}
+	ws.Profile = o.Profile
+	ws.ExtraArgs = append(ws.ExtraArgs, o.ExtraArgs...)
+	ws.Offline = o.Offline
+	return ws
+}
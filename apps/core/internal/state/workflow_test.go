@@ -0,0 +1,69 @@
+package state
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestForWorkflow(t *testing.T) {
+	s := DefaultSettings()
+	timeout, retries := 3600000, 0
+	s.WorkflowOverrides["dev-story"] = WorkflowOverride{
+		StepTimeout: &timeout,
+		MaxRetries:  &retries,
+		Profile:     "strong",
+		ExtraArgs:   []string{"--model", "big"},
+	}
+
+	got := s.ForWorkflow("dev-story")
+	want := WorkflowSettings{StepTimeout: 3600000, MaxRetries: 0, RetryDelay: 5000, Profile: "strong", ExtraArgs: []string{"--model", "big"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ForWorkflow(dev-story) = %+v, want %+v", got, want)
+	}
+
+	got = s.ForWorkflow("create-product-brief")
+	want = WorkflowSettings{StepTimeout: 300000, MaxRetries: 3, RetryDelay: 5000, ExtraArgs: []string{}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ForWorkflow(create-product-brief) = %+v, want %+v", got, want)
+	}
+}
+
+func TestWorkflowOverrides_SetAndValidate(t *testing.T) {
+	sm, _, _ := newLayeredTestManager(t)
+	err := sm.Set(map[string]interface{}{
+		"workflowOverrides": map[string]interface{}{
+			"dev-story":            map[string]interface{}{"stepTimeout": float64(3600000), "profile": "strong", "extraArgs": []interface{}{"--model", "big"}},
+			"create-product-brief": map[string]interface{}{"stepTimeout": float64(300000), "maxRetries": float64(0)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+
+	got := sm.Get()
+	if ws := got.ForWorkflow("create-product-brief"); ws.MaxRetries != 0 || ws.StepTimeout != 300000 {
+		t.Errorf("create-product-brief = %+v", ws)
+	}
+	// Get returns a deep copy
+	*got.WorkflowOverrides["dev-story"].StepTimeout = 1
+	got.WorkflowOverrides["dev-story"].ExtraArgs[0] = "changed"
+	if ws := sm.Get().ForWorkflow("dev-story"); ws.StepTimeout != 3600000 || ws.ExtraArgs[0] != "--model" {
+		t.Errorf("stored override was modified through Get: %+v", ws)
+	}
+
+	for name, value := range map[string]interface{}{
+		"not an object":    []interface{}{"dev-story"},
+		"entry not object": map[string]interface{}{"dev-story": 5},
+		"unknown property": map[string]interface{}{"dev-story": map[string]interface{}{"model": "x"}},
+		"path key":         map[string]interface{}{"../dev-story": map[string]interface{}{}},
+		"timeout range":    map[string]interface{}{"dev-story": map[string]interface{}{"stepTimeout": float64(10)}},
+		"bad profile":      map[string]interface{}{"dev-story": map[string]interface{}{"profile": "a b"}},
+		"empty argument":   map[string]interface{}{"dev-story": map[string]interface{}{"extraArgs": []interface{}{""}}},
+	} {
+		err := validateUpdates(map[string]interface{}{"workflowOverrides": value})
+		if err == nil || !strings.Contains(err.Error(), "workflowOverrides") {
+			t.Errorf("%s: validateUpdates() = %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// notify is the fsnotify backend. fsnotify watches single directories, so
+// every directory of a tree gets its own watch and new directories are
+// added as the watcher discovers them.
+type notify struct {
+	fsw    *fsnotify.Watcher
+	ignore func(path string, isDir bool) bool
+
+	mu   sync.Mutex
+	dirs map[string]bool
+}
+
+// newNotify creates the fsnotify backend.
+func newNotify(ignore func(path string, isDir bool) bool) (backend, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("creating file watcher: %w", err)
+	}
+	return &notify{fsw: fsw, ignore: ignore, dirs: make(map[string]bool)}, nil
+}
+
+// add watches dir and every directory below it.
+func (n *notify) add(dir string) error {
+	if _, err := os.Stat(dir); err != nil {
+		return err
+	}
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if path != dir && n.ignore != nil && n.ignore(path, true) {
+			return fs.SkipDir
+		}
+
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		if n.dirs[path] {
+			return nil
+		}
+		if err := n.fsw.Add(path); err != nil {
+			return fmt.Errorf("watching %s: %w", path, err)
+		}
+		n.dirs[path] = true
+		return nil
+	})
+}
+
+// run delivers events until the backend is closed. ctx is not needed: Stop
+// closes the backend, which closes the event channels.
+func (n *notify) run(ctx context.Context, touched func(path string)) {
+	for {
+		select {
+		case ev, ok := <-n.fsw.Events:
+			if !ok {
+				return
+			}
+			if ev.Has(fsnotify.Remove) || ev.Has(fsnotify.Rename) {
+				// A removed or moved directory loses its watch
+				n.mu.Lock()
+				delete(n.dirs, ev.Name)
+				n.mu.Unlock()
+			}
+			touched(ev.Name)
+		case err, ok := <-n.fsw.Errors:
+			if !ok {
+				return
+			}
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				// Events were lost: rescan every watched directory
+				for _, dir := range n.watched() {
+					touched(dir)
+				}
+			}
+		}
+	}
+}
+
+// watched lists the watched directories.
+func (n *notify) watched() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	dirs := make([]string, 0, len(n.dirs))
+	for dir := range n.dirs {
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+func (n *notify) close() error {
+	return n.fsw.Close()
+}
@@ -0,0 +1,353 @@
+// Package watcher reports file changes under a set of directory trees.
+// It uses the platform's file notifications (via fsnotify) and falls back
+// to polling when they are unavailable. Both backends only say which paths were touched: the watcher then
+// compares them with the files it knows, so bursts of low-level events
+// (temp file, write, rename) collapse into one created, modified or deleted
+// event per file.
+package watcher
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default timings.
+const (
+	DefaultDebounce     = 250 * time.Millisecond
+	DefaultPollInterval = 2 * time.Second
+)
+
+// Op is the kind of change to a file.
+type Op string
+
+const (
+	Created  Op = "created"
+	Modified Op = "modified"
+	Deleted  Op = "deleted"
+)
+
+// Event is a change to a file under one of the watched roots.
+type Event struct {
+	Op   Op
+	Path string // Absolute path
+	Root string // Watched root containing Path
+}
+
+// Options configure a Watcher.
+type Options struct {
+	// Debounce is how long the watcher waits after the last change before reporting.
+	Debounce time.Duration
+	// PollInterval is the scan interval of the polling backend.
+	PollInterval time.Duration
+	// Ignore reports paths whose changes are not reported. Ignored
+	// directories are not descended into.
+	Ignore func(path string, isDir bool) bool
+	// ForcePolling disables file notifications.
+	ForcePolling bool
+}
+
+// fileState is what the watcher remembers about a file.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// backend delivers touched paths to the watcher.
+type backend interface {
+	// add starts watching a directory tree.
+	add(dir string) error
+	// run delivers touched paths until ctx is done.
+	run(ctx context.Context, touched func(path string))
+	close() error
+}
+
+// Watcher reports file changes under its roots. Create it with New and
+// call Start; Stop ends it.
+type Watcher struct {
+	roots   []string
+	opts    Options
+	onEvent func(Event)
+
+	mu      sync.Mutex
+	known   map[string]fileState
+	pending map[string]bool
+	timer   *time.Timer
+	backend backend
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// New creates a watcher over roots. onEvent is called from the watcher's
+// goroutine, one event at a time, in path order per batch.
+func New(roots []string, opts Options, onEvent func(Event)) *Watcher {
+	if opts.Debounce <= 0 {
+		opts.Debounce = DefaultDebounce
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+	clean := make([]string, len(roots))
+	for i, r := range roots {
+		clean[i] = filepath.Clean(r)
+	}
+	return &Watcher{roots: clean, opts: opts, onEvent: onEvent, known: make(map[string]fileState), pending: make(map[string]bool)}
+}
+
+// Start records the current files and begins watching. Roots that do not
+// exist yet are picked up by the polling backend only; with notifications,
+// their parent must be one of the other roots.
+func (w *Watcher) Start() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.done != nil {
+		return errors.New("watcher already started")
+	}
+
+	for _, root := range w.roots {
+		w.scanLocked(root, nil)
+	}
+
+	var b backend
+	if !w.opts.ForcePolling {
+		b, _ = newNotify(w.ignored)
+	}
+	if b == nil {
+		b = newPoller(w.opts.PollInterval, w.roots)
+	}
+	for _, root := range w.roots {
+		if err := b.add(root); err != nil && !os.IsNotExist(err) {
+			b.close()
+			return err
+		}
+	}
+	w.backend = b
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.done = make(chan struct{})
+	go func() {
+		defer close(w.done)
+		b.run(ctx, w.touch)
+	}()
+	return nil
+}
+
+// Stop ends watching. Pending changes are dropped.
+func (w *Watcher) Stop() {
+	w.mu.Lock()
+	if w.cancel == nil {
+		w.mu.Unlock()
+		return
+	}
+	w.cancel()
+	w.cancel = nil
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	b, done := w.backend, w.done
+	w.mu.Unlock()
+
+	b.close()
+	<-done
+}
+
+// Polling reports whether the watcher fell back to polling.
+func (w *Watcher) Polling() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, ok := w.backend.(*poller)
+	return ok
+}
+
+// touch records a changed path and restarts the debounce timer.
+func (w *Watcher) touch(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cancel == nil {
+		return
+	}
+	w.pending[filepath.Clean(path)] = true
+	if w.timer == nil {
+		w.timer = time.AfterFunc(w.opts.Debounce, w.flush)
+	} else {
+		w.timer.Reset(w.opts.Debounce)
+	}
+}
+
+// flush reconciles all pending paths and reports the changes.
+func (w *Watcher) flush() {
+	w.mu.Lock()
+	if w.cancel == nil {
+		w.mu.Unlock()
+		return
+	}
+	paths := make([]string, 0, len(w.pending))
+	for p := range w.pending {
+		paths = append(paths, p)
+	}
+	w.pending = make(map[string]bool)
+	sort.Strings(paths)
+
+	var events []Event
+	for _, p := range paths {
+		events = w.reconcileLocked(p, events)
+	}
+	onEvent := w.onEvent
+	w.mu.Unlock()
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Path < events[j].Path })
+	for _, e := range events {
+		onEvent(e)
+	}
+}
+
+// reconcileLocked compares path with what the watcher knows and appends the
+// resulting events. Directories are rescanned. Caller must hold w.mu.
+func (w *Watcher) reconcileLocked(path string, events []Event) []Event {
+	root := w.rootOf(path)
+	if root == "" {
+		return events
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		// Gone: the file itself, or everything known under a removed directory
+		prefix := path + string(filepath.Separator)
+		for known := range w.known {
+			if known == path || strings.HasPrefix(known, prefix) {
+				delete(w.known, known)
+				events = append(events, Event{Op: Deleted, Path: known, Root: root})
+			}
+		}
+		return events
+	}
+
+	if info.IsDir() {
+		if w.ignored(path, true) {
+			return events
+		}
+		if w.backend != nil {
+			_ = w.backend.add(path) // New directories need their own watch
+		}
+		return w.scanLocked(path, events)
+	}
+	return w.fileLocked(path, info, root, events)
+}
+
+// scanLocked walks dir, reporting new and changed files, and files known
+// under dir that disappeared. With events nil it only records the files.
+// Caller must hold w.mu.
+func (w *Watcher) scanLocked(dir string, events []Event) []Event {
+	root := w.rootOf(dir)
+	seen := make(map[string]bool)
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != dir && w.ignored(path, true) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		seen[path] = true
+		events = w.fileLocked(path, info, root, events)
+		return nil
+	})
+
+	prefix := dir + string(filepath.Separator)
+	for known := range w.known {
+		if strings.HasPrefix(known, prefix) && !seen[known] {
+			delete(w.known, known)
+			events = append(events, Event{Op: Deleted, Path: known, Root: root})
+		}
+	}
+	return events
+}
+
+// fileLocked records a file's state and appends an event if it is new or
+// changed. Caller must hold w.mu.
+func (w *Watcher) fileLocked(path string, info os.FileInfo, root string, events []Event) []Event {
+	if w.ignored(path, false) {
+		return events
+	}
+	state := fileState{modTime: info.ModTime(), size: info.Size()}
+	old, known := w.known[path]
+	w.known[path] = state
+
+	switch {
+	case w.cancel == nil:
+		// Initial scan: nothing to report
+	case !known:
+		events = append(events, Event{Op: Created, Path: path, Root: root})
+	case old != state:
+		events = append(events, Event{Op: Modified, Path: path, Root: root})
+	}
+	return events
+}
+
+// rootOf returns the watched root containing path, or "".
+func (w *Watcher) rootOf(path string) string {
+	for _, root := range w.roots {
+		if path == root || strings.HasPrefix(path, root+string(filepath.Separator)) {
+			return root
+		}
+	}
+	return ""
+}
+
+// ignored applies the Ignore option.
+func (w *Watcher) ignored(path string, isDir bool) bool {
+	return w.opts.Ignore != nil && w.opts.Ignore(path, isDir)
+}
+
+// poller is the polling backend: every interval it touches every root, and
+// the watcher rescans them.
+type poller struct {
+	interval time.Duration
+	roots    []string
+	stop     chan struct{}
+	once     sync.Once
+}
+
+func newPoller(interval time.Duration, roots []string) *poller {
+	return &poller{interval: interval, roots: roots, stop: make(chan struct{})}
+}
+
+func (p *poller) add(dir string) error { return nil }
+
+func (p *poller) run(ctx context.Context, touched func(path string)) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			for _, root := range p.roots {
+				touched(root)
+			}
+		}
+	}
+}
+
+func (p *poller) close() error {
+	p.once.Do(func() { close(p.stop) })
+	return nil
+}
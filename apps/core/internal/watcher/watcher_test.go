@@ -0,0 +1,159 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder collects events for assertions.
+type recorder struct {
+	mu     sync.Mutex
+	events []Event
+	notify chan struct{}
+}
+
+func newRecorder() *recorder {
+	return &recorder{notify: make(chan struct{}, 100)}
+}
+
+func (r *recorder) record(e Event) {
+	r.mu.Lock()
+	r.events = append(r.events, e)
+	r.mu.Unlock()
+	r.notify <- struct{}{}
+}
+
+// waitFor waits until want events arrived, then returns them after a quiet period.
+func (r *recorder) waitFor(t *testing.T, want int) []Event {
+	t.Helper()
+	deadline := time.After(5 * time.Second)
+	for {
+		r.mu.Lock()
+		n := len(r.events)
+		r.mu.Unlock()
+		if n >= want {
+			break
+		}
+		select {
+		case <-r.notify:
+		case <-deadline:
+			t.Fatalf("timed out waiting for %d events, got %d: %+v", want, n, r.take())
+		}
+	}
+	time.Sleep(150 * time.Millisecond) // Catch unexpected extra events
+	return r.take()
+}
+
+func (r *recorder) take() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := r.events
+	r.events = nil
+	return events
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func describe(events []Event, root string) []string {
+	var out []string
+	for _, e := range events {
+		rel, _ := filepath.Rel(root, e.Path)
+		out = append(out, string(e.Op)+" "+filepath.ToSlash(rel))
+	}
+	return out
+}
+
+func assertEvents(t *testing.T, got []Event, root string, want ...string) {
+	t.Helper()
+	desc := describe(got, root)
+	if strings.Join(desc, ", ") != strings.Join(want, ", ") {
+		t.Errorf("events = %v, want %v", desc, want)
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	for _, polling := range []bool{false, true} {
+		name := "notify"
+		if polling {
+			name = "polling"
+		}
+		t.Run(name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFile(t, filepath.Join(root, "existing.md"), "v1")
+
+			rec := newRecorder()
+			w := New([]string{root}, Options{
+				Debounce:     50 * time.Millisecond,
+				PollInterval: 50 * time.Millisecond,
+				ForcePolling: polling,
+				Ignore: func(path string, isDir bool) bool {
+					return strings.HasSuffix(path, ".tmp")
+				},
+			}, rec.record)
+			if err := w.Start(); err != nil {
+				t.Fatalf("Start failed: %v", err)
+			}
+			defer w.Stop()
+			if !polling && w.Polling() {
+				t.Skip("file notifications unavailable")
+			}
+
+			// A burst of writes to a new file is one created event
+			for i := 0; i < 5; i++ {
+				writeFile(t, filepath.Join(root, "new.md"), strings.Repeat("x", i+1))
+			}
+			assertEvents(t, rec.waitFor(t, 1), root, "created new.md")
+
+			// An atomic write through an ignored temp file is one modified event
+			time.Sleep(10 * time.Millisecond) // Ensure a new mtime
+			writeFile(t, filepath.Join(root, "existing.md.tmp"), "v2 longer")
+			if err := os.Rename(filepath.Join(root, "existing.md.tmp"), filepath.Join(root, "existing.md")); err != nil {
+				t.Fatal(err)
+			}
+			assertEvents(t, rec.waitFor(t, 1), root, "modified existing.md")
+
+			// Files in new directories are found
+			writeFile(t, filepath.Join(root, "sub", "deep", "story.md"), "story")
+			assertEvents(t, rec.waitFor(t, 1), root, "created sub/deep/story.md")
+
+			// Removing a directory deletes everything known under it
+			if err := os.RemoveAll(filepath.Join(root, "sub")); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Remove(filepath.Join(root, "new.md")); err != nil {
+				t.Fatal(err)
+			}
+			assertEvents(t, rec.waitFor(t, 2), root, "deleted new.md", "deleted sub/deep/story.md")
+		})
+	}
+}
+
+func TestWatcher_StopDropsPending(t *testing.T) {
+	root := t.TempDir()
+	rec := newRecorder()
+	w := New([]string{root}, Options{Debounce: 200 * time.Millisecond}, rec.record)
+	if err := w.Start(); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(root, "a.md"), "a")
+	time.Sleep(50 * time.Millisecond)
+	w.Stop()
+	w.Stop() // Idempotent
+
+	time.Sleep(300 * time.Millisecond)
+	if events := rec.take(); len(events) != 0 {
+		t.Errorf("events after Stop = %+v", events)
+	}
+}